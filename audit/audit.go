@@ -0,0 +1,281 @@
+// Package audit records the changes the n8n CLI makes to n8n instances in a journal
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edenreich/n8n-cli/logger"
+)
+
+// Actions recorded in the journal
+const (
+	ActionCreate     = "create"
+	ActionUpdate     = "update"
+	ActionDelete     = "delete"
+	ActionActivate   = "activate"
+	ActionDeactivate = "deactivate"
+	ActionUpdateTags = "update-tags"
+	// Changes to tags and variables have no workflow, the details name the tag or variable
+	ActionCreateTag      = "create-tag"
+	ActionDeleteTag      = "delete-tag"
+	ActionCreateVariable = "create-variable"
+)
+
+// Record is a single change made to a workflow, or to a tag or variable of the instance
+type Record struct {
+	Time         time.Time `json:"time"`
+	Actor        string    `json:"actor"`
+	CIJob        string    `json:"ciJob,omitempty"`
+	Command      string    `json:"command"`
+	Instance     string    `json:"instance"`
+	WorkflowID   string    `json:"workflowId"`
+	WorkflowName string    `json:"workflowName,omitempty"`
+	Action       string    `json:"action"`
+	BeforeHash   string    `json:"beforeHash,omitempty"`
+	AfterHash    string    `json:"afterHash,omitempty"`
+	Details      string    `json:"details,omitempty"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// Sink receives every record appended to the journal, such as a log collector or a webhook
+type Sink interface {
+	Send(record Record) error
+}
+
+// Journal appends records to a JSON Lines file and forwards them to an optional sink
+type Journal struct {
+	Path string
+	Sink Sink
+
+	mu sync.Mutex
+}
+
+// DefaultJournalPath returns the journal location used when none is configured, next to the config file
+func DefaultJournalPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".n8n", "history.jsonl")
+	}
+	return filepath.Join(home, ".n8n", "history.jsonl")
+}
+
+// NewJournal creates a journal writing to the given file
+func NewJournal(path string, sink Sink) *Journal {
+	return &Journal{Path: path, Sink: sink}
+}
+
+// Append writes a record to the journal file and sends it to the sink. A failing sink doesn't lose the
+// record, it is still in the file and the error is returned for the caller to report.
+func (j *Journal) Append(record Record) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("error encoding audit record: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(j.Path), 0755); err != nil {
+		return fmt.Errorf("error creating audit journal directory: %w", err)
+	}
+
+	file, err := os.OpenFile(j.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening audit journal: %w", err)
+	}
+
+	_, err = file.Write(append(line, '\n'))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("error writing audit journal: %w", err)
+	}
+
+	if j.Sink != nil {
+		if err := j.Sink.Send(record); err != nil {
+			return fmt.Errorf("error sending audit record: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Filter selects records read from the journal, empty fields match every record
+type Filter struct {
+	// Workflow matches the workflow ID or name
+	Workflow string
+	Action   string
+	Actor    string
+	Since    time.Time
+}
+
+// Matches reports whether a record is selected by the filter
+func (f Filter) Matches(record Record) bool {
+	if f.Workflow != "" && record.WorkflowID != f.Workflow && record.WorkflowName != f.Workflow {
+		return false
+	}
+	if f.Action != "" && record.Action != f.Action {
+		return false
+	}
+	if f.Actor != "" && !strings.Contains(record.Actor, f.Actor) {
+		return false
+	}
+	if !f.Since.IsZero() && record.Time.Before(f.Since) {
+		return false
+	}
+	return true
+}
+
+// Read returns the records in the journal matching the filter, oldest first.
+// A journal that doesn't exist yet has no records.
+func (j *Journal) Read(filter Filter) ([]Record, error) {
+	file, err := os.Open(j.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error opening audit journal: %w", err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			logger.Warn("Error closing audit journal: %v", err)
+		}
+	}()
+
+	var records []Record
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var record Record
+		if err := json.Unmarshal(line, &record); err != nil {
+			return nil, fmt.Errorf("error reading audit journal %s line %d: %w", j.Path, lineNumber, err)
+		}
+		if filter.Matches(record) {
+			records = append(records, record)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading audit journal: %w", err)
+	}
+
+	return records, nil
+}
+
+// HTTPSink posts every record as JSON to a URL
+type HTTPSink struct {
+	URL    string
+	Token  string
+	Client *http.Client
+}
+
+// NewHTTPSink creates a sink posting records to a URL, authenticated with a bearer token when one is given
+func NewHTTPSink(url string, token string) *HTTPSink {
+	return &HTTPSink{URL: url, Token: token, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Send posts a record to the sink URL
+func (s *HTTPSink) Send(record Record) error {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.Token)
+	}
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logger.Warn("Error closing audit sink response body: %v", err)
+		}
+	}()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("sink %s returned status %d", s.URL, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Actor identifies who made a change: a CI user and job, or the local git or OS user
+type Actor struct {
+	Name  string
+	CIJob string
+}
+
+// DetectActor identifies who runs the CLI. CI variables of GitHub Actions and GitLab CI take precedence,
+// then the git user configured in the working directory, then the OS user.
+func DetectActor() Actor {
+	actor := Actor{CIJob: ciJob()}
+
+	for _, variable := range []string{"GITHUB_ACTOR", "GITLAB_USER_LOGIN", "BUILD_USER_ID"} {
+		if value := os.Getenv(variable); value != "" {
+			actor.Name = value
+			return actor
+		}
+	}
+
+	if name := gitConfig("user.name"); name != "" {
+		actor.Name = name
+		if email := gitConfig("user.email"); email != "" {
+			actor.Name = fmt.Sprintf("%s <%s>", name, email)
+		}
+		return actor
+	}
+
+	if current, err := user.Current(); err == nil {
+		actor.Name = current.Username
+	}
+
+	return actor
+}
+
+// ciJob returns the URL of the CI job running the CLI, if any
+func ciJob() string {
+	if os.Getenv("GITHUB_ACTIONS") == "true" && os.Getenv("GITHUB_RUN_ID") != "" {
+		return fmt.Sprintf("%s/%s/actions/runs/%s", os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"), os.Getenv("GITHUB_RUN_ID"))
+	}
+
+	for _, variable := range []string{"CI_JOB_URL", "BUILD_URL"} {
+		if value := os.Getenv(variable); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// gitConfig reads a git configuration value, empty when git or the value isn't available
+func gitConfig(key string) string {
+	output, err := exec.Command("git", "config", "--get", key).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
@@ -0,0 +1,208 @@
+/*
+Copyright © 2025 Eden Reich
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/edenreich/n8n-cli/audit"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show the changes the CLI made to workflows from the audit journal",
+	Long: `History lists the changes made to workflows by sync, activate, deactivate, prune, rollback,
+adopt and dedupe, newest first. Every change is appended to the audit journal with who made it, the git
+user or the CI user and job, when, on which instance, and the content hash of the workflow before and
+after the change.
+
+The journal is ~/.n8n/history.jsonl unless audit.journal is set in the config file. Set audit.sink_url
+to also post every record to a log collector or webhook.
+
+Examples:
+
+  # Show who changed a workflow in the last week
+  n8n history --workflow "Contact Form" --since 168h
+
+  # Export every deletion as JSON
+  n8n history --action delete --limit 0 --output json`,
+	Args:        cobra.ExactArgs(0),
+	Annotations: map[string]string{OfflineAnnotation: "true"},
+	RunE:        ShowHistory,
+}
+
+func init() {
+	historyCmd.Flags().String("workflow", "", "Only show changes to the workflow with this ID or name")
+	historyCmd.Flags().String("action", "", "Only show changes of this action (create, update, delete, activate, deactivate, update-tags, create-tag, delete-tag, create-variable)")
+	historyCmd.Flags().String("actor", "", "Only show changes made by actors containing this text")
+	historyCmd.Flags().String("since", "", "Only show changes since a duration ago (e.g. 24h) or a date (e.g. 2024-01-31)")
+	historyCmd.Flags().IntP("limit", "l", 50, "Maximum number of changes to show (0 for no limit)")
+	historyCmd.Flags().StringP("output", "o", "table", "Output format: table or json")
+	historyCmd.Flags().String("journal", "", "Path of the audit journal (default: audit.journal from the config file or ~/.n8n/history.jsonl)")
+	GetRootCmd().AddCommand(historyCmd)
+}
+
+// NewJournal opens the audit journal configured with audit.journal, audit.sink_url and audit.sink_token
+func NewJournal() *audit.Journal {
+	path := viper.GetString("audit.journal")
+	if path == "" {
+		path = audit.DefaultJournalPath()
+	}
+
+	var sink audit.Sink
+	if sinkURL := viper.GetString("audit.sink_url"); sinkURL != "" {
+		sink = audit.NewHTTPSink(sinkURL, viper.GetString("audit.sink_token"))
+	}
+
+	return audit.NewJournal(path, sink)
+}
+
+// ShowHistory prints the records of the audit journal matching the flags
+func ShowHistory(cmd *cobra.Command, args []string) error {
+	workflow, _ := cmd.Flags().GetString("workflow")
+	action, _ := cmd.Flags().GetString("action")
+	actor, _ := cmd.Flags().GetString("actor")
+	since, _ := cmd.Flags().GetString("since")
+	limit, _ := cmd.Flags().GetInt("limit")
+	output, _ := cmd.Flags().GetString("output")
+	journalPath, _ := cmd.Flags().GetString("journal")
+
+	filter := audit.Filter{Workflow: workflow, Action: action, Actor: actor}
+	if since != "" {
+		sinceTime, err := parseSince(since, time.Now())
+		if err != nil {
+			return err
+		}
+		filter.Since = sinceTime
+	}
+
+	journal := NewJournal()
+	if journalPath != "" {
+		journal.Path = journalPath
+	}
+
+	records, err := journal.Read(filter)
+	if err != nil {
+		return err
+	}
+
+	// Newest first, limited to the most recent changes
+	for i, j := 0, len(records)-1; i < j; i, j = i+1, j-1 {
+		records[i], records[j] = records[j], records[i]
+	}
+	if limit > 0 && len(records) > limit {
+		records = records[:limit]
+	}
+
+	switch strings.ToLower(output) {
+	case "json":
+		if records == nil {
+			records = []audit.Record{}
+		}
+		data, err := json.MarshalIndent(records, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshaling history to JSON: %w", err)
+		}
+		cmd.Println(string(data))
+		return nil
+	case "table":
+		if len(records) == 0 {
+			cmd.Println("No changes found")
+			return nil
+		}
+		return printHistoryTable(cmd, records)
+	default:
+		return fmt.Errorf("unsupported output format: %s. Supported formats: table, json", output)
+	}
+}
+
+// parseSince parses a duration before now or a date
+func parseSince(since string, now time.Time) (time.Time, error) {
+	if duration, err := time.ParseDuration(since); err == nil {
+		return now.Add(-duration), nil
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if parsed, err := time.Parse(layout, since); err == nil {
+			return parsed, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid --since '%s', use a duration like 24h or a date like 2024-01-31", since)
+}
+
+// printHistoryTable prints audit records in a table
+func printHistoryTable(cmd *cobra.Command, records []audit.Record) error {
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 3, ' ', 0)
+	if _, err := fmt.Fprintln(w, "TIME\tACTOR\tACTION\tWORKFLOW\tINSTANCE\tCHANGE"); err != nil {
+		return fmt.Errorf("error printing history table: %w", err)
+	}
+
+	for _, record := range records {
+		workflow := record.WorkflowID
+		switch {
+		case record.WorkflowName != "":
+			workflow = fmt.Sprintf("%s (%s)", record.WorkflowName, record.WorkflowID)
+		case workflow == "":
+			// Changes to tags and variables
+			workflow = "-"
+		}
+
+		change := fmt.Sprintf("%s -> %s", shortHash(record.BeforeHash), shortHash(record.AfterHash))
+		if record.Details != "" {
+			change = record.Details
+		}
+		if record.Error != "" {
+			change = "failed: " + strings.TrimSpace(record.Error)
+		}
+
+		actor := record.Actor
+		if record.CIJob != "" {
+			actor = fmt.Sprintf("%s (%s)", record.Actor, record.CIJob)
+		}
+
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", record.Time.Local().Format(time.DateTime), actor, record.Action,
+			workflow, record.Instance, change); err != nil {
+			return fmt.Errorf("error printing history table: %w", err)
+		}
+	}
+
+	return w.Flush()
+}
+
+// shortHash abbreviates a content hash, or returns a dash when there is none
+func shortHash(hash string) string {
+	if hash == "" {
+		return "-"
+	}
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
@@ -0,0 +1,174 @@
+/*
+Copyright © 2025 Eden Reich
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/edenreich/n8n-cli/logger"
+	"github.com/spf13/cobra"
+)
+
+// reconcileCmd represents the reconcile command
+var reconcileCmd = &cobra.Command{
+	Use:   "reconcile",
+	Short: "Continuously reconcile an n8n instance with a workflows directory",
+	Long: `Reconcile runs as a long-lived process that periodically compares the n8n instance against the
+workflow files in a directory and syncs the workflows that drifted. With --git-pull, the directory is
+updated with 'git pull' before every run, so the instance follows a git branch.
+
+Workflows changed in n8n are overwritten with the version from the directory, unless --force=false is set.
+Files without an ID are matched to the workflow with the same name (see --match-by), and the IDs of
+created workflows are written back into their files, so they are not created again on the next run.
+With --git-pull, the checkout is left untouched: files are neither refreshed nor get IDs written back,
+created workflows are found again by --match-by, which can't be id.
+Failed runs are retried with an exponential backoff, up to --max-backoff.
+
+An HTTP server is started on --listen with two endpoints:
+
+  /healthz  200 while reconciling succeeds, 503 after a failed run
+  /status   JSON with the result of the last run for every workflow
+
+Logs are written as structured JSON to stderr.
+
+Examples:
+
+  # Reconcile every minute from a git checkout
+  n8n reconcile --directory /srv/workflows --interval 1m --git-pull --prune
+
+  # Reconcile once and exit
+  n8n reconcile --directory workflows/ --once`,
+	Args: cobra.ExactArgs(0),
+	RunE: reconcileWorkflows,
+}
+
+func init() {
+	reconcileCmd.Flags().StringP("directory", "d", "", "Directory containing workflow files (JSON/YAML) (required)")
+	reconcileCmd.Flags().Duration("interval", time.Minute, "Time between reconcile runs")
+	reconcileCmd.Flags().Duration("max-backoff", 10*time.Minute, "Maximum time between runs while reconciling keeps failing")
+	reconcileCmd.Flags().String("listen", ":8080", "Address of the health and status endpoints")
+	reconcileCmd.Flags().Bool("git-pull", false, "Run 'git pull' in the directory before every run")
+	reconcileCmd.Flags().Bool("once", false, "Reconcile once and exit")
+	reconcileCmd.Flags().Bool("prune", false, "Remove managed workflows that are not present in the directory")
+	reconcileCmd.Flags().Int("max-deletions", 0, "Abort pruning when more workflows would be removed (0 for no limit)")
+	reconcileCmd.Flags().Bool("include-unmanaged", false, "Also prune workflows that are not managed by the CLI")
+	reconcileCmd.Flags().Bool("mark-managed", true, "Tag synced workflows as managed by the CLI")
+	reconcileCmd.Flags().Bool("force", true, "Overwrite workflows that were changed in n8n")
+	reconcileCmd.Flags().String("match-by", "name", "How files without a known workflow ID are matched to workflows in n8n (id, name or key)")
+	reconcileCmd.Flags().Bool("refresh", true, "Write the IDs of created workflows back into their files and refresh synced files with the remote state, ignored with --git-pull")
+	reconcileCmd.Flags().String("overlay", "", "Name of the overlay to apply from the overlays/<name>/ directory before uploading")
+	reconcileCmd.Flags().Bool("substitute", false, "Replace ${VAR} placeholders with values from the environment or .env file before uploading")
+	reconcileCmd.Flags().Bool("resolve-credentials", false, "Resolve node credentials by type and name on the target instance before uploading")
+	reconcileCmd.Flags().String("credentials-map", "", "File mapping credential types and names to IDs on the target instance (implies --resolve-credentials)")
+	// The daemon never asks for confirmation before pruning
+	reconcileCmd.Flags().Bool("yes", true, "Don't ask for confirmation before pruning")
+	// nolint:errcheck
+	reconcileCmd.Flags().MarkHidden("yes")
+	GetRootCmd().AddCommand(reconcileCmd)
+
+	// nolint:errcheck
+	reconcileCmd.MarkFlagRequired("directory")
+}
+
+// Reconciler reconciles an n8n instance with a workflows directory
+type Reconciler interface {
+	// Reconcile runs a single reconcile and records its result
+	Reconcile(ctx context.Context) error
+	// Run reconciles until the context is cancelled
+	Run(ctx context.Context, interval time.Duration, maxBackoff time.Duration)
+	// Handler serves the health and status endpoints
+	Handler() http.Handler
+}
+
+// newReconciler creates the reconciler of the reconcile command, see RegisterReconciler
+var newReconciler func(cmd *cobra.Command, directory string) (Reconciler, error)
+
+// RegisterReconciler sets how the reconcile command creates its reconciler. The workflows package
+// registers it, as reconciling syncs the workflows the same way the workflow commands do.
+func RegisterReconciler(factory func(cmd *cobra.Command, directory string) (Reconciler, error)) {
+	newReconciler = factory
+}
+
+// GetReconcileCmd returns the reconcile command for testing purposes
+func GetReconcileCmd() *cobra.Command {
+	return reconcileCmd
+}
+
+// reconcileWorkflows is the handler for the reconcile command
+func reconcileWorkflows(cmd *cobra.Command, args []string) error {
+	directory, _ := cmd.Flags().GetString("directory")
+	interval, _ := cmd.Flags().GetDuration("interval")
+	maxBackoff, _ := cmd.Flags().GetDuration("max-backoff")
+	listen, _ := cmd.Flags().GetString("listen")
+	once, _ := cmd.Flags().GetBool("once")
+
+	if directory == "" {
+		return fmt.Errorf("directory is required")
+	}
+	if interval <= 0 {
+		return fmt.Errorf("interval must be greater than zero")
+	}
+
+	if newReconciler == nil {
+		return fmt.Errorf("reconcile is not available, no reconciler is registered")
+	}
+	reconciler, err := newReconciler(cmd, directory)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if once {
+		return reconciler.Reconcile(ctx)
+	}
+
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		return fmt.Errorf("error listening on %s: %w", listen, err)
+	}
+
+	server := &http.Server{Handler: reconciler.Handler(), ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Errorw("Health server stopped", "error", err.Error())
+			stop()
+		}
+	}()
+
+	logger.Infow("Reconcile daemon started", "directory", directory, "interval", interval.String(), "listen", listener.Addr().String())
+	reconciler.Run(ctx, interval, maxBackoff)
+	logger.Infow("Reconcile daemon stopped")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
@@ -22,6 +22,7 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -31,6 +32,9 @@ import (
 	"github.com/spf13/viper"
 )
 
+// OfflineAnnotation marks commands that only work with local files and don't need an API key
+const OfflineAnnotation = "offline"
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "n8n",
@@ -58,6 +62,10 @@ through version control systems.`,
 			return nil
 		}
 
+		if cmd.Annotations[OfflineAnnotation] == "true" {
+			return nil
+		}
+
 		if IsWorkflowCommand(cmd) && viper.GetString("api_key") == "" {
 			return fmt.Errorf("API key is required. Set it using the --api-key flag or N8N_API_KEY environment variable")
 		}
@@ -70,10 +78,31 @@ through version control systems.`,
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
+
+		var exitErr *ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
 		os.Exit(1)
 	}
 }
 
+// ExitError is an error that makes the CLI exit with a specific exit code
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+// Error returns the message of the wrapped error
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
 // GetRootCmd returns the root command for testing purposes
 func GetRootCmd() *cobra.Command {
 	return rootCmd
@@ -101,7 +130,7 @@ func init() {
 
 // IsWorkflowCommand checks if the command or any of its parents is the workflows command
 func IsWorkflowCommand(cmd *cobra.Command) bool {
-	if cmd.Name() == "workflows" || cmd.Name() == "list" || cmd.Name() == "sync" || cmd.Name() == "activate" || cmd.Name() == "deactivate" || cmd.Name() == "refresh" {
+	if cmd.Name() == "workflows" || cmd.Name() == "list" || cmd.Name() == "sync" || cmd.Name() == "activate" || cmd.Name() == "deactivate" || cmd.Name() == "refresh" || cmd.Name() == "reconcile" {
 		return true
 	}
 
@@ -0,0 +1,82 @@
+/*
+Copyright © 2025 Eden Reich
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/spf13/cobra"
+)
+
+// schemaCmd represents the schema command
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print JSON Schemas of the file formats of the CLI",
+	Long: `Schema prints JSON Schemas of the file formats of the CLI, for editors to complete and validate the
+files as they are edited.`,
+	Annotations: map[string]string{OfflineAnnotation: "true"},
+}
+
+// schemaWorkflowCmd represents the schema workflow command
+var schemaWorkflowCmd = &cobra.Command{
+	Use:   "workflow",
+	Short: "Print the JSON Schema of workflow files",
+	Long: `Print the JSON Schema of workflow files in JSON and YAML, including workflow directories. The schema
+describes the workflow, node and settings fields, the connections between nodes and the parameters of
+known node types such as code, database and webhook nodes. Code parameters may hold the code or reference
+the file holding it.
+
+With --schema or schema: true in the config file, refresh and import write the schema to
+.n8n/workflow.schema.json in the workflow directory and add a header pointing to it to every YAML workflow
+file, which the YAML extension of VS Code uses for autocompletion and inline validation:
+
+  # yaml-language-server: $schema=.n8n/workflow.schema.json
+
+Examples:
+
+  # Write the schema to a file
+  n8n schema workflow > workflow.schema.json
+
+  # Keep the schema and the headers of YAML workflow files up to date
+  n8n workflows refresh --directory workflows/ --schema`,
+	Args:        cobra.ExactArgs(0),
+	Annotations: map[string]string{OfflineAnnotation: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := n8n.MarshalWorkflowSchema()
+		if err != nil {
+			return fmt.Errorf("error generating the workflow schema: %w", err)
+		}
+		cmd.Print(string(data))
+		return nil
+	},
+}
+
+// GetSchemaCmd returns the schema command for testing purposes
+func GetSchemaCmd() *cobra.Command {
+	return schemaCmd
+}
+
+func init() {
+	schemaCmd.AddCommand(schemaWorkflowCmd)
+	GetRootCmd().AddCommand(schemaCmd)
+}
@@ -3,6 +3,7 @@ package cmd
 
 import (
 	"fmt"
+	"path/filepath"
 	"reflect"
 	"strings"
 
@@ -20,15 +21,46 @@ func FormatAPIBaseURL(instanceURL string) string {
 	return instanceURL
 }
 
-// FindWorkflow looks up a workflow by exact name match in a list of workflows
+// AmbiguousWorkflowError is returned when more than one workflow matches a name
+type AmbiguousWorkflowError struct {
+	Name string
+	IDs  []string
+}
+
+func (e *AmbiguousWorkflowError) Error() string {
+	return fmt.Sprintf("workflow name '%s' is ambiguous, %d workflows match it (IDs: %s)", e.Name, len(e.IDs), strings.Join(e.IDs, ", "))
+}
+
+// FindWorkflow looks up a workflow by exact name match in a list of workflows.
+// It returns an *AmbiguousWorkflowError when several workflows have the name.
 func FindWorkflow(name string, workflows []n8n.Workflow) (string, error) {
+	return findWorkflow(name, workflows, func(wf n8n.Workflow) string { return wf.Name })
+}
+
+// FindWorkflowByFilename looks up the workflow a file was written for, by comparing the file name without
+// its extension to the sanitized workflow names. It returns an *AmbiguousWorkflowError when several match.
+func FindWorkflowByFilename(filename string, workflows []n8n.Workflow) (string, error) {
+	key := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	return findWorkflow(key, workflows, func(wf n8n.Workflow) string { return SanitizeFilename(wf.Name) })
+}
+
+// findWorkflow looks up the single workflow whose key equals the given one
+func findWorkflow(key string, workflows []n8n.Workflow, keyOf func(n8n.Workflow) string) (string, error) {
+	var ids []string
 	for _, wf := range workflows {
-		if wf.Name == name {
-			return *wf.Id, nil
+		if wf.Id != nil && keyOf(wf) == key {
+			ids = append(ids, *wf.Id)
 		}
 	}
 
-	return "", fmt.Errorf("workflow with name '%s' not found", name)
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("workflow with name '%s' not found", key)
+	case 1:
+		return ids[0], nil
+	default:
+		return "", &AmbiguousWorkflowError{Name: key, IDs: ids}
+	}
 }
 
 // SanitizeFilename converts a workflow name to a valid filename
@@ -66,11 +98,12 @@ func SanitizeFilename(name string) string {
 
 // DetectWorkflowDrift compares two workflows and returns true if they differ
 // This function uses reflect.DeepEqual for accurate structural comparison
-// If minimal is true, both workflows will be cleaned before comparison
+// If minimal is true, both workflows are brought into canonical form before comparison,
+// so node order, static data and float32 artifacts don't count as drift
 func DetectWorkflowDrift(actual n8n.Workflow, desired n8n.Workflow, minimal bool) bool {
 	if minimal {
-		actual = n8n.CleanWorkflow(actual)
-		desired = n8n.CleanWorkflow(desired)
+		actual = n8n.CanonicalWorkflow(actual)
+		desired = n8n.CanonicalWorkflow(desired)
 	}
 
 	return !reflect.DeepEqual(actual, desired)
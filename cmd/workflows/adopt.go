@@ -0,0 +1,128 @@
+/*
+Copyright © 2025 Eden Reich
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package workflows
+
+import (
+	"fmt"
+
+	rootcmd "github.com/edenreich/n8n-cli/cmd"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/spf13/cobra"
+)
+
+// AdoptCmd represents the adopt command
+var AdoptCmd = &cobra.Command{
+	Use:   "adopt ID",
+	Short: "Bring a workflow built in the n8n UI under management of the CLI",
+	Long: `Adopt marks an existing workflow as managed by the CLI and writes it to the directory.
+
+Sync tags every workflow it uploads with the managed tag ("n8n-cli" by default, configurable with
+managed_tag in the config file). Prune and refresh --all only operate on workflows carrying this tag,
+so workflows built by other people in the n8n UI are left alone. Adopting a workflow adds the tag
+and saves the workflow next to the other workflow files, from then on it is synced like any other.
+
+Examples:
+
+  # Adopt a workflow and save it as YAML
+  n8n workflows adopt 123 --directory workflows/ --output yaml`,
+	Args: cobra.ExactArgs(1),
+	RunE: adoptWorkflow,
+}
+
+func init() {
+	AdoptCmd.Flags().StringP("directory", "d", "", "Directory to write the workflow file to (required)")
+	AdoptCmd.Flags().StringP("output", "o", "", "Output format for the workflow file (json, yaml or dir). If not specified, keeps the format of an existing file or uses json")
+	AdoptCmd.Flags().Bool("dry-run", false, "Show what would be done without making changes")
+	rootcmd.GetWorkflowsCmd().AddCommand(AdoptCmd)
+
+	// nolint:errcheck
+	AdoptCmd.MarkFlagRequired("directory")
+}
+
+// adoptWorkflow is the handler for the adopt command
+func adoptWorkflow(cmd *cobra.Command, args []string) error {
+	client := newClient(cmd)
+
+	return AdoptWorkflowWithClient(cmd, client, args[0])
+}
+
+// AdoptWorkflowWithClient is the testable version of the adopt command that accepts a client interface
+func AdoptWorkflowWithClient(cmd *cobra.Command, client n8n.ClientInterface, workflowID string) error {
+	directory, _ := cmd.Flags().GetString("directory")
+	output, _ := cmd.Flags().GetString("output")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	if directory == "" {
+		return fmt.Errorf("directory is required")
+	}
+
+	workflow, err := client.GetWorkflow(workflowID)
+	if err != nil {
+		return fmt.Errorf("error fetching workflow %s: %w", workflowID, err)
+	}
+
+	if IsManaged(*workflow) {
+		cmd.Printf("Workflow '%s' (ID: %s) is already managed by the CLI\n", workflow.Name, workflowID)
+	} else {
+		dryRunMsg := fmt.Sprintf("Would mark workflow '%s' (ID: %s) as managed with tag '%s'", workflow.Name, workflowID, ManagedTag())
+		err := ExecuteOrDryRun(cmd, dryRun, dryRunMsg, func() (string, error) {
+			if err := ensureManagedTag(client, cmd, workflowID, workflow.Name); err != nil {
+				return "", err
+			}
+
+			workflow, err = client.GetWorkflow(workflowID)
+			if err != nil {
+				return "", fmt.Errorf("error fetching workflow %s: %w", workflowID, err)
+			}
+			return "", nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := ensureDirectoryExists(cmd, directory, dryRun); err != nil {
+		return err
+	}
+
+	localFiles, err := extractLocalWorkflows(directory)
+	if err != nil {
+		return err
+	}
+
+	if err := processWorkflow(cmd, *workflow, localFiles, directory, dryRun, false, output, true); err != nil {
+		return err
+	}
+
+	if dryRun {
+		return nil
+	}
+
+	state, err := LoadWorkflowState(directory)
+	if err != nil {
+		return err
+	}
+	if err := state.Record(*workflow); err != nil {
+		return err
+	}
+	return state.Save()
+}
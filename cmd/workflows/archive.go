@@ -0,0 +1,391 @@
+/*
+Copyright © 2025 Eden Reich
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package workflows
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	rootcmd "github.com/edenreich/n8n-cli/cmd"
+	"github.com/edenreich/n8n-cli/n8n"
+)
+
+// ArchiveFormatVersion is the version of the archive format written by export, newer versions can't be imported
+const ArchiveFormatVersion = 1
+
+// Files and directories inside a workflow archive
+const (
+	archiveManifestFile    = "manifest.json"
+	archiveTagsFile        = "tags.json"
+	archiveVariablesFile   = "variables.json"
+	archiveCredentialsFile = "credentials.json"
+	archiveWorkflowsDir    = "workflows"
+)
+
+// maxArchiveFileSize limits the size of a single file read from an archive
+const maxArchiveFileSize = 64 << 20
+
+// ArchiveEntry describes a workflow packaged in an archive
+type ArchiveEntry struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	File string `json:"file"`
+}
+
+// CredentialStub describes a credential used by the workflows of an archive, without its secrets
+type CredentialStub struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// ArchiveManifest describes the content of an archive and the SHA-256 checksum of every other file in it
+type ArchiveManifest struct {
+	Version   int               `json:"version"`
+	CreatedAt time.Time         `json:"createdAt"`
+	Workflows []ArchiveEntry    `json:"workflows"`
+	Checksums map[string]string `json:"checksums"`
+}
+
+// Archive is a set of workflows packaged with the tags, variables and credentials they use, to be
+// imported into another n8n instance. Workflows are in the order of the manifest entries.
+type Archive struct {
+	Manifest    ArchiveManifest
+	Workflows   []n8n.Workflow
+	Tags        []n8n.Tag
+	Variables   []n8n.Variable
+	Credentials []CredentialStub
+}
+
+// NewArchive packages workflows with the tags, variables and credentials they use. Credentials are taken
+// from the node references, so only their IDs, names and types end up in the archive.
+func NewArchive(workflows []n8n.Workflow, variables []n8n.Variable) *Archive {
+	archive := &Archive{
+		Manifest:  ArchiveManifest{Version: ArchiveFormatVersion, CreatedAt: time.Now().UTC().Truncate(time.Second)},
+		Variables: variables,
+	}
+
+	tags := make(map[string]n8n.Tag)
+	credentials := make(map[string]CredentialStub)
+	files := make(map[string]bool)
+
+	for _, workflow := range workflows {
+		workflow = n8n.CleanWorkflow(workflow)
+		workflow.StaticData = nil
+
+		id := ""
+		if workflow.Id != nil {
+			id = *workflow.Id
+		}
+
+		file := path.Join(archiveWorkflowsDir, rootcmd.SanitizeFilename(workflow.Name)+".json")
+		if files[strings.ToLower(file)] {
+			file = path.Join(archiveWorkflowsDir, rootcmd.SanitizeFilename(workflow.Name)+"_"+id+".json")
+		}
+		files[strings.ToLower(file)] = true
+
+		archive.Manifest.Workflows = append(archive.Manifest.Workflows, ArchiveEntry{ID: id, Name: workflow.Name, File: file})
+		archive.Workflows = append(archive.Workflows, workflow)
+
+		if workflow.Tags != nil {
+			for _, tag := range *workflow.Tags {
+				tags[tag.Name] = tag
+			}
+		}
+
+		for _, node := range workflow.Nodes {
+			if node.Credentials == nil {
+				continue
+			}
+			for credType, value := range *node.Credentials {
+				id, name := credentialIDAndName(value)
+				credentials[credentialKey(credType, id+"/"+name)] = CredentialStub{ID: id, Name: name, Type: credType}
+			}
+		}
+	}
+
+	for _, tag := range tags {
+		archive.Tags = append(archive.Tags, tag)
+	}
+	sort.Slice(archive.Tags, func(i, j int) bool { return archive.Tags[i].Name < archive.Tags[j].Name })
+
+	for _, credential := range credentials {
+		archive.Credentials = append(archive.Credentials, credential)
+	}
+	sort.Slice(archive.Credentials, func(i, j int) bool {
+		if archive.Credentials[i].Type != archive.Credentials[j].Type {
+			return archive.Credentials[i].Type < archive.Credentials[j].Type
+		}
+		return archive.Credentials[i].Name < archive.Credentials[j].Name
+	})
+
+	return archive
+}
+
+// files encodes the content of the archive and fills in the checksums of the manifest
+func (a *Archive) files() (map[string][]byte, error) {
+	files := make(map[string][]byte)
+
+	for i, entry := range a.Manifest.Workflows {
+		content, err := json.MarshalIndent(a.Workflows[i], "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("error encoding workflow '%s': %w", entry.Name, err)
+		}
+		files[entry.File] = content
+	}
+
+	documents := map[string]interface{}{
+		archiveTagsFile:        a.Tags,
+		archiveVariablesFile:   a.Variables,
+		archiveCredentialsFile: a.Credentials,
+	}
+	for file, document := range documents {
+		content, err := json.MarshalIndent(document, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("error encoding %s: %w", file, err)
+		}
+		files[file] = content
+	}
+
+	a.Manifest.Checksums = make(map[string]string, len(files))
+	for file, content := range files {
+		a.Manifest.Checksums[file] = checksum(content)
+	}
+
+	manifest, err := json.MarshalIndent(a.Manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error encoding archive manifest: %w", err)
+	}
+	files[archiveManifestFile] = manifest
+
+	return files, nil
+}
+
+// Write writes the archive as a gzipped tarball. The manifest comes first and the other files are sorted,
+// so the same content always gives the same archive.
+func (a *Archive) Write(archivePath string) error {
+	files, err := a.files()
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		if name != archiveManifestFile {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	names = append([]string{archiveManifestFile}, names...)
+
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	for _, name := range names {
+		header := &tar.Header{
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(files[name])),
+			ModTime:  a.Manifest.CreatedAt,
+			Typeflag: tar.TypeReg,
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return fmt.Errorf("error writing archive: %w", err)
+		}
+		if _, err := tarWriter.Write(files[name]); err != nil {
+			return fmt.Errorf("error writing archive: %w", err)
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return fmt.Errorf("error writing archive: %w", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return fmt.Errorf("error writing archive: %w", err)
+	}
+
+	if dir := filepath.Dir(archivePath); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("error creating directory: %w", err)
+		}
+	}
+
+	if err := os.WriteFile(archivePath, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("error writing archive: %w", err)
+	}
+
+	return nil
+}
+
+// ReadArchive reads an archive written by Write. Every file is checked against the checksums of the
+// manifest, and archives with missing, modified or unexpected files are rejected.
+func ReadArchive(archivePath string) (*Archive, error) {
+	files, err := readArchiveFiles(archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	content, ok := files[archiveManifestFile]
+	if !ok {
+		return nil, fmt.Errorf("archive %s has no %s", archivePath, archiveManifestFile)
+	}
+
+	archive := &Archive{}
+	if err := json.Unmarshal(content, &archive.Manifest); err != nil {
+		return nil, fmt.Errorf("error parsing archive manifest: %w", err)
+	}
+
+	if archive.Manifest.Version > ArchiveFormatVersion {
+		return nil, fmt.Errorf("archive format version %d is not supported, update n8n-cli to import it", archive.Manifest.Version)
+	}
+
+	if err := verifyArchiveFiles(files, archive.Manifest.Checksums); err != nil {
+		return nil, fmt.Errorf("archive %s is corrupted or was modified: %w", archivePath, err)
+	}
+
+	for _, entry := range archive.Manifest.Workflows {
+		content, ok := files[entry.File]
+		if !ok {
+			return nil, fmt.Errorf("archive %s is missing workflow '%s' (%s)", archivePath, entry.Name, entry.File)
+		}
+
+		workflow, err := n8n.NewWorkflowDecoder().DecodeFromJSON(content)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing workflow '%s' in archive: %w", entry.Name, err)
+		}
+		archive.Workflows = append(archive.Workflows, workflow)
+	}
+
+	documents := map[string]interface{}{
+		archiveTagsFile:        &archive.Tags,
+		archiveVariablesFile:   &archive.Variables,
+		archiveCredentialsFile: &archive.Credentials,
+	}
+	for file, document := range documents {
+		content, ok := files[file]
+		if !ok {
+			continue
+		}
+		if err := json.Unmarshal(content, document); err != nil {
+			return nil, fmt.Errorf("error parsing %s in archive: %w", file, err)
+		}
+	}
+
+	return archive, nil
+}
+
+// readArchiveFiles reads the regular files of a gzipped tarball by their cleaned names
+func readArchiveFiles(archivePath string) (map[string][]byte, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening archive: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading archive %s: %w", archivePath, err)
+	}
+
+	files := make(map[string][]byte)
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading archive %s: %w", archivePath, err)
+		}
+
+		if header.Typeflag == tar.TypeDir {
+			continue
+		}
+
+		name := path.Clean(header.Name)
+		if header.Typeflag != tar.TypeReg || path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return nil, fmt.Errorf("archive %s contains unexpected entry %s", archivePath, header.Name)
+		}
+		if header.Size > maxArchiveFileSize {
+			return nil, fmt.Errorf("archive %s contains file %s larger than %d bytes", archivePath, name, maxArchiveFileSize)
+		}
+
+		content, err := io.ReadAll(io.LimitReader(tarReader, maxArchiveFileSize))
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s from archive: %w", name, err)
+		}
+		files[name] = content
+	}
+
+	return files, nil
+}
+
+// verifyArchiveFiles checks that the files of an archive are exactly the ones listed in the checksums
+func verifyArchiveFiles(files map[string][]byte, checksums map[string]string) error {
+	var problems []string
+
+	for name, sum := range checksums {
+		content, ok := files[name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s is missing", name))
+			continue
+		}
+		if checksum(content) != sum {
+			problems = append(problems, fmt.Sprintf("%s doesn't match its checksum", name))
+		}
+	}
+
+	for name := range files {
+		if _, listed := checksums[name]; !listed && name != archiveManifestFile {
+			problems = append(problems, fmt.Sprintf("%s is not listed in the manifest", name))
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%s", strings.Join(problems, ", "))
+	}
+
+	return nil
+}
+
+// checksum returns the hex encoded SHA-256 checksum of a file
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
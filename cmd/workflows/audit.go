@@ -0,0 +1,233 @@
+/*
+Copyright © 2025 Eden Reich
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package workflows
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/edenreich/n8n-cli/audit"
+	rootcmd "github.com/edenreich/n8n-cli/cmd"
+	"github.com/edenreich/n8n-cli/logger"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// newClient creates the client for commands that change workflows. Unless audit.enabled is false in the
+// config file, every change made through it is recorded in the audit journal.
+func newClient(cmd *cobra.Command) n8n.ClientInterface {
+	instanceURL := viper.Get("instance_url").(string)
+	client := n8n.NewClient(instanceURL, viper.Get("api_key").(string)).WithFieldPolicy(fieldPolicy())
+
+	if viper.IsSet("audit.enabled") && !viper.GetBool("audit.enabled") {
+		return client
+	}
+
+	return NewAuditClient(client, rootcmd.NewJournal(), cmd.CommandPath(), instanceURL)
+}
+
+// fieldPolicy returns the policy for the fields outside the API schema configured with unknown_fields.allow
+// and unknown_fields.deny. The fields n8n manages itself are never sent.
+func fieldPolicy() n8n.FieldPolicy {
+	policy := n8n.DefaultFieldPolicy()
+	policy.Allow = viper.GetStringSlice("unknown_fields.allow")
+	policy.Deny = append(append([]string{}, policy.Deny...), viper.GetStringSlice("unknown_fields.deny")...)
+	return policy
+}
+
+// AuditClient is a client recording every change it makes to a workflow in the audit journal,
+// with the content hash of the workflow before and after the change
+type AuditClient struct {
+	n8n.ClientInterface
+	Journal  *audit.Journal
+	Actor    audit.Actor
+	Command  string
+	Instance string
+}
+
+// NewAuditClient wraps a client to record its changes in a journal, attributed to the detected actor
+func NewAuditClient(client n8n.ClientInterface, journal *audit.Journal, command string, instance string) *AuditClient {
+	return &AuditClient{
+		ClientInterface: client,
+		Journal:         journal,
+		Actor:           audit.DetectActor(),
+		Command:         command,
+		Instance:        instance,
+	}
+}
+
+// current returns the remote version of a workflow before it is changed, nil when it can't be fetched
+func (c *AuditClient) current(id string) *n8n.Workflow {
+	workflow, err := c.ClientInterface.GetWorkflow(id)
+	if err != nil {
+		return nil
+	}
+	return workflow
+}
+
+// record appends a change to the journal. Failing to record doesn't fail the change, which already happened.
+func (c *AuditClient) record(action string, id string, name string, before *n8n.Workflow, after *n8n.Workflow, details string, changeErr error) {
+	record := audit.Record{
+		Time:         time.Now().UTC(),
+		Actor:        c.Actor.Name,
+		CIJob:        c.Actor.CIJob,
+		Command:      c.Command,
+		Instance:     c.Instance,
+		WorkflowID:   id,
+		WorkflowName: name,
+		Action:       action,
+		Details:      details,
+	}
+
+	if before != nil {
+		record.BeforeHash, _ = n8n.WorkflowContentHash(*before)
+		if record.WorkflowName == "" {
+			record.WorkflowName = before.Name
+		}
+	}
+	if after != nil {
+		record.AfterHash, _ = n8n.WorkflowContentHash(*after)
+		if after.Name != "" {
+			record.WorkflowName = after.Name
+		}
+	}
+	if changeErr != nil {
+		record.Error = changeErr.Error()
+	}
+
+	if err := c.Journal.Append(record); err != nil {
+		logger.Warn("Error recording change in the audit journal: %v", err)
+	}
+}
+
+// CreateWorkflow creates a workflow and records its creation
+func (c *AuditClient) CreateWorkflow(workflow *n8n.Workflow) (*n8n.Workflow, error) {
+	created, err := c.ClientInterface.CreateWorkflow(workflow)
+
+	id := ""
+	if created != nil && created.Id != nil {
+		id = *created.Id
+	}
+	c.record(audit.ActionCreate, id, workflow.Name, nil, created, "", err)
+
+	return created, err
+}
+
+// UpdateWorkflow updates a workflow and records its content before and after the update
+func (c *AuditClient) UpdateWorkflow(id string, workflow *n8n.Workflow) (*n8n.Workflow, error) {
+	before := c.current(id)
+	updated, err := c.ClientInterface.UpdateWorkflow(id, workflow)
+	c.record(audit.ActionUpdate, id, workflow.Name, before, updated, "", err)
+
+	return updated, err
+}
+
+// DeleteWorkflow deletes a workflow and records its content before the deletion
+func (c *AuditClient) DeleteWorkflow(id string) error {
+	before := c.current(id)
+	err := c.ClientInterface.DeleteWorkflow(id)
+	c.record(audit.ActionDelete, id, "", before, nil, "", err)
+
+	return err
+}
+
+// ActivateWorkflow activates a workflow and records the activation
+func (c *AuditClient) ActivateWorkflow(id string) (*n8n.Workflow, error) {
+	before := c.current(id)
+	activated, err := c.ClientInterface.ActivateWorkflow(id)
+	c.record(audit.ActionActivate, id, "", before, activated, "", err)
+
+	return activated, err
+}
+
+// DeactivateWorkflow deactivates a workflow and records the deactivation
+func (c *AuditClient) DeactivateWorkflow(id string) (*n8n.Workflow, error) {
+	before := c.current(id)
+	deactivated, err := c.ClientInterface.DeactivateWorkflow(id)
+	c.record(audit.ActionDeactivate, id, "", before, deactivated, "", err)
+
+	return deactivated, err
+}
+
+// UpdateWorkflowTags updates the tags of a workflow and records the tags before and after the update
+func (c *AuditClient) UpdateWorkflowTags(id string, tagIds n8n.TagIds) (n8n.WorkflowTags, error) {
+	before, _ := c.ClientInterface.GetWorkflowTags(id)
+	after, err := c.ClientInterface.UpdateWorkflowTags(id, tagIds)
+
+	details := fmt.Sprintf("tags: [%s] -> [%s]", tagNames(before), tagNames(after))
+	if err != nil {
+		details = fmt.Sprintf("tags: [%s]", tagNames(before))
+	}
+	c.record(audit.ActionUpdateTags, id, "", nil, nil, details, err)
+
+	return after, err
+}
+
+// CreateTag creates a tag and records its creation
+func (c *AuditClient) CreateTag(tagName string) (*n8n.Tag, error) {
+	created, err := c.ClientInterface.CreateTag(tagName)
+
+	details := fmt.Sprintf("tag '%s'", tagName)
+	if created != nil && created.Id != nil {
+		details = fmt.Sprintf("tag '%s' (ID: %s)", tagName, *created.Id)
+	}
+	c.record(audit.ActionCreateTag, "", "", nil, nil, details, err)
+
+	return created, err
+}
+
+// DeleteTag deletes a tag and records its deletion with the name it had
+func (c *AuditClient) DeleteTag(id string) error {
+	details := fmt.Sprintf("tag ID: %s", id)
+	if tags, err := c.ClientInterface.GetTags(); err == nil && tags != nil && tags.Data != nil {
+		for _, tag := range *tags.Data {
+			if tag.Id != nil && *tag.Id == id {
+				details = fmt.Sprintf("tag '%s' (ID: %s)", tag.Name, id)
+				break
+			}
+		}
+	}
+
+	err := c.ClientInterface.DeleteTag(id)
+	c.record(audit.ActionDeleteTag, "", "", nil, nil, details, err)
+
+	return err
+}
+
+// CreateVariable creates a variable and records its creation. The value isn't recorded, it may be a secret.
+func (c *AuditClient) CreateVariable(variable n8n.Variable) error {
+	err := c.ClientInterface.CreateVariable(variable)
+	c.record(audit.ActionCreateVariable, "", "", nil, nil, fmt.Sprintf("variable '%s'", variable.Key), err)
+
+	return err
+}
+
+// tagNames joins the names of tags
+func tagNames(tags n8n.WorkflowTags) string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return strings.Join(names, ", ")
+}
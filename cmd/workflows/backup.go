@@ -0,0 +1,433 @@
+/*
+Copyright © 2025 Eden Reich
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package workflows
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/spf13/cobra"
+)
+
+// backupsDirectory is the directory inside the state directory where sync stores its backups
+const backupsDirectory = "backups"
+
+// backupManifestFile is the name of the file describing the content of a backup
+const backupManifestFile = "manifest.json"
+
+// BackupEntry describes a workflow saved in a backup
+type BackupEntry struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	File string `json:"file"`
+}
+
+// BackupTag describes a tag created after a backup was taken
+type BackupTag struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// BackupManifest describes the workflows saved in a backup and the workflows and tags created after it was taken
+type BackupManifest struct {
+	CreatedAt   time.Time     `json:"createdAt"`
+	Workflows   []BackupEntry `json:"workflows"`
+	Created     []string      `json:"created,omitempty"`
+	CreatedTags []BackupTag   `json:"createdTags,omitempty"`
+}
+
+// Backup stores the remote version of workflows before they are changed, so they can be restored later
+type Backup struct {
+	Path     string
+	Manifest BackupManifest
+	saved    map[string]bool
+}
+
+// NewBackup creates an empty backup, the directory is only created once something is saved in it
+func NewBackup(path string) *Backup {
+	return &Backup{
+		Path:     path,
+		Manifest: BackupManifest{CreatedAt: time.Now().UTC()},
+		saved:    make(map[string]bool),
+	}
+}
+
+// newBackupPath returns a timestamped backup directory inside the backups directory of a workflows directory
+func newBackupPath(directory string) string {
+	return filepath.Join(directory, stateDirectory, backupsDirectory, time.Now().UTC().Format("20060102T150405Z"))
+}
+
+// LoadBackup reads a backup from its directory
+func LoadBackup(path string) (*Backup, error) {
+	content, err := os.ReadFile(filepath.Join(path, backupManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("error reading backup manifest: %w", err)
+	}
+
+	backup := &Backup{Path: path, saved: make(map[string]bool)}
+	if err := json.Unmarshal(content, &backup.Manifest); err != nil {
+		return nil, fmt.Errorf("error parsing backup manifest: %w", err)
+	}
+
+	for _, entry := range backup.Manifest.Workflows {
+		backup.saved[entry.ID] = true
+	}
+	for _, id := range backup.Manifest.Created {
+		backup.saved[id] = true
+	}
+
+	return backup, nil
+}
+
+// Has reports whether the backup contains a workflow or recorded its creation
+func (b *Backup) Has(id string) bool {
+	return b.saved[id]
+}
+
+// Len returns the number of workflows saved or created since the backup was taken
+func (b *Backup) Len() int {
+	return len(b.Manifest.Workflows) + len(b.Manifest.Created)
+}
+
+// Empty reports whether nothing was saved or created since the backup was taken
+func (b *Backup) Empty() bool {
+	return b.Len() == 0 && len(b.Manifest.CreatedTags) == 0
+}
+
+// Save stores the remote version of a workflow, unless the backup already contains it
+func (b *Backup) Save(workflow n8n.Workflow) error {
+	if workflow.Id == nil || b.saved[*workflow.Id] {
+		return nil
+	}
+
+	if err := os.MkdirAll(b.Path, 0755); err != nil {
+		return fmt.Errorf("error creating backup directory: %w", err)
+	}
+
+	file := *workflow.Id + ".json"
+	content, err := json.MarshalIndent(workflow, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding workflow '%s' for backup: %w", workflow.Name, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(b.Path, file), content, 0644); err != nil {
+		return fmt.Errorf("error writing backup of workflow '%s': %w", workflow.Name, err)
+	}
+
+	b.saved[*workflow.Id] = true
+	b.Manifest.Workflows = append(b.Manifest.Workflows, BackupEntry{ID: *workflow.Id, Name: workflow.Name, File: file})
+
+	return b.writeManifest()
+}
+
+// RecordCreated remembers a workflow that didn't exist when the backup was taken.
+// Such a workflow has no previous version, so later changes to it are not saved.
+func (b *Backup) RecordCreated(id string) error {
+	b.saved[id] = true
+	b.Manifest.Created = append(b.Manifest.Created, id)
+	return b.writeManifest()
+}
+
+// RecordCreatedTag remembers a tag that didn't exist when the backup was taken
+func (b *Backup) RecordCreatedTag(tag n8n.Tag) error {
+	if tag.Id == nil {
+		return nil
+	}
+	b.Manifest.CreatedTags = append(b.Manifest.CreatedTags, BackupTag{ID: *tag.Id, Name: tag.Name})
+	return b.writeManifest()
+}
+
+// Workflow reads a saved workflow from the backup
+func (b *Backup) Workflow(entry BackupEntry) (n8n.Workflow, error) {
+	content, err := os.ReadFile(filepath.Join(b.Path, entry.File))
+	if err != nil {
+		return n8n.Workflow{}, fmt.Errorf("error reading backup of workflow '%s': %w", entry.Name, err)
+	}
+
+	var workflow n8n.Workflow
+	if err := json.Unmarshal(content, &workflow); err != nil {
+		return n8n.Workflow{}, fmt.Errorf("error parsing backup of workflow '%s': %w", entry.Name, err)
+	}
+
+	return workflow, nil
+}
+
+// writeManifest writes the manifest after every change, so the backup is usable even if the run is interrupted
+func (b *Backup) writeManifest() error {
+	if err := os.MkdirAll(b.Path, 0755); err != nil {
+		return fmt.Errorf("error creating backup directory: %w", err)
+	}
+
+	content, err := json.MarshalIndent(b.Manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding backup manifest: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(b.Path, backupManifestFile), content, 0644); err != nil {
+		return fmt.Errorf("error writing backup manifest: %w", err)
+	}
+
+	return nil
+}
+
+// BackupClient is a client that saves the remote version of a workflow in a backup
+// before the first change made to it, and records the workflows it creates
+type BackupClient struct {
+	n8n.ClientInterface
+	Backup *Backup
+}
+
+// snapshot saves the current remote version of a workflow before it is changed
+func (c *BackupClient) snapshot(id string) error {
+	if c.Backup.Has(id) {
+		return nil
+	}
+
+	workflow, err := c.ClientInterface.GetWorkflow(id)
+	if err != nil {
+		return fmt.Errorf("error backing up workflow %s: %w", id, err)
+	}
+
+	return c.Backup.Save(*workflow)
+}
+
+// CreateWorkflow creates a workflow and records it in the backup
+func (c *BackupClient) CreateWorkflow(workflow *n8n.Workflow) (*n8n.Workflow, error) {
+	created, err := c.ClientInterface.CreateWorkflow(workflow)
+	if err != nil {
+		return nil, err
+	}
+
+	if created != nil && created.Id != nil {
+		if err := c.Backup.RecordCreated(*created.Id); err != nil {
+			return created, err
+		}
+	}
+
+	return created, nil
+}
+
+// CreateTag creates a tag and records it in the backup
+func (c *BackupClient) CreateTag(tagName string) (*n8n.Tag, error) {
+	created, err := c.ClientInterface.CreateTag(tagName)
+	if err != nil {
+		return nil, err
+	}
+
+	if created != nil {
+		if err := c.Backup.RecordCreatedTag(*created); err != nil {
+			return created, err
+		}
+	}
+
+	return created, nil
+}
+
+// UpdateWorkflow backs up a workflow before updating it
+func (c *BackupClient) UpdateWorkflow(id string, workflow *n8n.Workflow) (*n8n.Workflow, error) {
+	if err := c.snapshot(id); err != nil {
+		return nil, err
+	}
+	return c.ClientInterface.UpdateWorkflow(id, workflow)
+}
+
+// DeleteWorkflow backs up a workflow before deleting it
+func (c *BackupClient) DeleteWorkflow(id string) error {
+	if err := c.snapshot(id); err != nil {
+		return err
+	}
+	return c.ClientInterface.DeleteWorkflow(id)
+}
+
+// ActivateWorkflow backs up a workflow before activating it
+func (c *BackupClient) ActivateWorkflow(id string) (*n8n.Workflow, error) {
+	if err := c.snapshot(id); err != nil {
+		return nil, err
+	}
+	return c.ClientInterface.ActivateWorkflow(id)
+}
+
+// DeactivateWorkflow backs up a workflow before deactivating it
+func (c *BackupClient) DeactivateWorkflow(id string) (*n8n.Workflow, error) {
+	if err := c.snapshot(id); err != nil {
+		return nil, err
+	}
+	return c.ClientInterface.DeactivateWorkflow(id)
+}
+
+// UpdateWorkflowTags backs up a workflow before changing its tags
+func (c *BackupClient) UpdateWorkflowTags(id string, tagIds n8n.TagIds) (n8n.WorkflowTags, error) {
+	if err := c.snapshot(id); err != nil {
+		return nil, err
+	}
+	return c.ClientInterface.UpdateWorkflowTags(id, tagIds)
+}
+
+// RestoreBackup restores every workflow of a backup to its saved version, including activation and tags.
+// Workflows deleted since the backup are created again, with a new ID, and workflows and tags created after
+// the backup was taken are deleted unless keepCreated is set.
+func RestoreBackup(client n8n.ClientInterface, cmd *cobra.Command, backup *Backup, dryRun bool, keepCreated bool) error {
+	var failed []string
+
+	for _, entry := range backup.Manifest.Workflows {
+		snapshot, err := backup.Workflow(entry)
+		if err != nil {
+			return err
+		}
+
+		if err := restoreWorkflow(client, cmd, snapshot, dryRun); err != nil {
+			cmd.Printf("Error restoring workflow '%s' (ID: %s): %v\n", entry.Name, entry.ID, err)
+			failed = append(failed, entry.Name)
+		}
+	}
+
+	if !keepCreated {
+		for _, id := range backup.Manifest.Created {
+			workflowID := id
+			dryRunMsg := fmt.Sprintf("Would delete workflow %s that didn't exist before", workflowID)
+			err := ExecuteOrDryRun(cmd, dryRun, dryRunMsg, func() (string, error) {
+				if err := client.DeleteWorkflow(workflowID); err != nil {
+					return "", fmt.Errorf("error deleting workflow %s: %w", workflowID, err)
+				}
+				return fmt.Sprintf("Deleted workflow %s that didn't exist before", workflowID), nil
+			})
+			if err != nil {
+				cmd.Printf("Error: %v\n", err)
+				failed = append(failed, workflowID)
+			}
+		}
+
+		// Tags are deleted last, once no restored workflow uses them anymore
+		for _, tag := range backup.Manifest.CreatedTags {
+			created := tag
+			dryRunMsg := fmt.Sprintf("Would delete tag '%s' that didn't exist before", created.Name)
+			err := ExecuteOrDryRun(cmd, dryRun, dryRunMsg, func() (string, error) {
+				if err := client.DeleteTag(created.ID); err != nil {
+					return "", fmt.Errorf("error deleting tag '%s': %w", created.Name, err)
+				}
+				return fmt.Sprintf("Deleted tag '%s' that didn't exist before", created.Name), nil
+			})
+			if err != nil {
+				cmd.Printf("Error: %v\n", err)
+				failed = append(failed, created.Name)
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to restore %d workflow(s) from backup %s", len(failed), backup.Path)
+	}
+
+	return nil
+}
+
+// restoreWorkflow restores the content, activation and tags of a single workflow
+func restoreWorkflow(client n8n.ClientInterface, cmd *cobra.Command, snapshot n8n.Workflow, dryRun bool) error {
+	id := *snapshot.Id
+	content := n8n.CleanWorkflow(snapshot)
+	content.Shared = nil
+
+	current, err := client.GetWorkflow(id)
+	if err != nil && !errors.Is(err, n8n.ErrWorkflowNotFound) {
+		return fmt.Errorf("error fetching workflow '%s' (ID: %s): %w", snapshot.Name, id, err)
+	}
+	if err != nil {
+		current = nil
+		dryRunMsg := fmt.Sprintf("Would recreate workflow '%s' (ID: %s no longer exists)", snapshot.Name, id)
+		err = ExecuteOrDryRun(cmd, dryRun, dryRunMsg, func() (string, error) {
+			created, err := client.CreateWorkflow(&content)
+			if err != nil {
+				return "", fmt.Errorf("error recreating workflow: %w", err)
+			}
+			id = *created.Id
+			return fmt.Sprintf("Recreated workflow '%s' with new ID %s (ID: %s no longer exists)", snapshot.Name, id, *snapshot.Id), nil
+		})
+	} else {
+		dryRunMsg := fmt.Sprintf("Would restore workflow '%s' (ID: %s)", snapshot.Name, id)
+		err = ExecuteOrDryRun(cmd, dryRun, dryRunMsg, func() (string, error) {
+			if _, err := client.UpdateWorkflow(id, &content); err != nil {
+				return "", fmt.Errorf("error restoring workflow: %w", err)
+			}
+			return fmt.Sprintf("Restored workflow '%s' (ID: %s)", snapshot.Name, id), nil
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	wasActive := snapshot.Active != nil && *snapshot.Active
+	isActive := current != nil && current.Active != nil && *current.Active
+	if wasActive != isActive {
+		action, pastAction := "deactivate", "Deactivated"
+		toggle := client.DeactivateWorkflow
+		if wasActive {
+			action, pastAction = "activate", "Activated"
+			toggle = client.ActivateWorkflow
+		}
+
+		dryRunMsg := fmt.Sprintf("Would %s workflow '%s' (ID: %s)", action, snapshot.Name, id)
+		err := ExecuteOrDryRun(cmd, dryRun, dryRunMsg, func() (string, error) {
+			if _, err := toggle(id); err != nil {
+				return "", fmt.Errorf("error trying to %s workflow: %w", action, err)
+			}
+			return fmt.Sprintf("%s workflow '%s' (ID: %s)", pastAction, snapshot.Name, id), nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return restoreWorkflowTags(client, cmd, snapshot, current, id, dryRun)
+}
+
+// restoreWorkflowTags restores the tags of a workflow by name, creating tags that were deleted since the backup
+func restoreWorkflowTags(client n8n.ClientInterface, cmd *cobra.Command, snapshot n8n.Workflow, current *n8n.Workflow, id string, dryRun bool) error {
+	var tags []n8n.Tag
+	if snapshot.Tags != nil {
+		for _, tag := range *snapshot.Tags {
+			tags = append(tags, n8n.Tag{Name: tag.Name})
+		}
+	}
+
+	if len(tags) > 0 {
+		workflow := snapshot
+		workflow.Tags = &tags
+		return HandleTagUpdates(client, cmd, &workflow, id, dryRun)
+	}
+
+	if current == nil || current.Tags == nil || len(*current.Tags) == 0 {
+		return nil
+	}
+
+	dryRunMsg := fmt.Sprintf("Would remove tags from workflow '%s' (ID: %s)", snapshot.Name, id)
+	return ExecuteOrDryRun(cmd, dryRun, dryRunMsg, func() (string, error) {
+		if _, err := client.UpdateWorkflowTags(id, n8n.TagIds{}); err != nil {
+			return "", fmt.Errorf("error removing workflow tags: %w", err)
+		}
+		return fmt.Sprintf("Removed tags from workflow '%s' (ID: %s)", snapshot.Name, id), nil
+	})
+}
@@ -0,0 +1,198 @@
+/*
+Copyright © 2025 Eden Reich
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package workflows
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// codeDir returns the directory the code files of a workflow file are extracted to, relative to the
+// workflow file. It is named after the workflow file, so Contact_Form.yaml keeps its code in Contact_Form/.
+func codeDir(filePath string) string {
+	base := filepath.Base(filePath)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// readCodeFile returns the function reading code files referenced by a workflow file
+func readCodeFile(filePath string) func(string) ([]byte, error) {
+	return func(codePath string) ([]byte, error) {
+		return os.ReadFile(filepath.Join(filepath.Dir(filePath), filepath.FromSlash(codePath)))
+	}
+}
+
+// shouldExtractCode reports whether the code of a workflow written to a file is extracted into code files:
+// with --extract-code, extract_code in the config file, or when the file already references code files
+func shouldExtractCode(cmd *cobra.Command, filePath string) bool {
+	if extract, _ := cmd.Flags().GetBool("extract-code"); extract {
+		return true
+	}
+	if viper.GetBool("extract_code") {
+		return true
+	}
+	return fileHasCodeReferences(filePath)
+}
+
+// fileHasCodeReferences reports whether a workflow file references code files
+func fileHasCodeReferences(filePath string) bool {
+	if filePath == "" {
+		return false
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return false
+	}
+
+	workflow, err := n8n.NewWorkflowDecoder().DecodeFromBytes(content)
+	if err != nil {
+		return false
+	}
+
+	return n8n.HasCodeFileReferences(workflow)
+}
+
+// codeFilesChanged reports whether writing the given code files next to a workflow file would change them
+func codeFilesChanged(filePath string, files []n8n.CodeFile) bool {
+	existing := existingCodeFiles(filePath)
+	if len(existing) != len(files) {
+		return true
+	}
+
+	for _, file := range files {
+		content, err := os.ReadFile(filepath.Join(filepath.Dir(filePath), filepath.FromSlash(file.Path)))
+		if err != nil || !bytes.Equal(content, file.Content) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeCodeFiles writes the code files of a workflow file and removes the code files of nodes that no
+// longer exist. Other files in the code directory are left alone.
+func writeCodeFiles(filePath string, files []n8n.CodeFile) error {
+	keep := make(map[string]bool)
+	for _, file := range files {
+		codePath := filepath.Join(filepath.Dir(filePath), filepath.FromSlash(file.Path))
+		keep[codePath] = true
+
+		if err := os.MkdirAll(filepath.Dir(codePath), 0755); err != nil {
+			return fmt.Errorf("error creating code directory: %w", err)
+		}
+		if err := os.WriteFile(codePath, file.Content, 0644); err != nil {
+			return fmt.Errorf("error writing code file %s: %w", codePath, err)
+		}
+	}
+
+	for _, codePath := range existingCodeFiles(filePath) {
+		if keep[codePath] {
+			continue
+		}
+		if err := os.Remove(codePath); err != nil {
+			return fmt.Errorf("error removing code file %s: %w", codePath, err)
+		}
+	}
+
+	return nil
+}
+
+// existingCodeFiles returns the paths of the code files in the code directory of a workflow file
+func existingCodeFiles(filePath string) []string {
+	dir := filepath.Join(filepath.Dir(filePath), codeDir(filePath))
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if !entry.IsDir() && n8n.IsCodeFile(entry.Name()) {
+			paths = append(paths, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(paths)
+
+	return paths
+}
+
+// workflowFileHash returns the hash of a workflow file together with its code files, or of all files of a
+// workflow directory, or false when the workflow can't be read
+func workflowFileHash(filePath string) ([32]byte, bool) {
+	hash := sha256.New()
+	if isWorkflowDirectory(filePath) {
+		if !workflowDirectoryHash(filePath, hash) {
+			return [32]byte{}, false
+		}
+
+		var sum [32]byte
+		copy(sum[:], hash.Sum(nil))
+		return sum, true
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return [32]byte{}, false
+	}
+
+	hash.Write(content)
+	for _, codePath := range existingCodeFiles(filePath) {
+		code, err := os.ReadFile(codePath)
+		if err != nil {
+			continue
+		}
+		hash.Write([]byte(codePath))
+		hash.Write(code)
+	}
+
+	var sum [32]byte
+	copy(sum[:], hash.Sum(nil))
+	return sum, true
+}
+
+// workflowFileForCode returns the workflow file a code file belongs to, or false when it isn't in the
+// code directory of a workflow file
+func workflowFileForCode(codePath string) (string, bool) {
+	if !n8n.IsCodeFile(codePath) {
+		return "", false
+	}
+
+	dir := filepath.Dir(codePath)
+	for _, ext := range []string{".yaml", ".yml", ".json"} {
+		filePath := dir + ext
+		if _, err := os.Stat(filePath); err == nil {
+			return filePath, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,287 @@
+/*
+Copyright © 2025 Eden Reich
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package workflows
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/edenreich/n8n-cli/logger"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// CredentialMapping maps a credential, identified by its type and name, to its ID on the target instance
+type CredentialMapping struct {
+	Type string `yaml:"type" json:"type"`
+	Name string `yaml:"name" json:"name"`
+	ID   string `yaml:"id" json:"id"`
+}
+
+// CredentialMappingFile is the format of the file passed with --credentials-map
+type CredentialMappingFile struct {
+	Credentials []CredentialMapping `yaml:"credentials" json:"credentials"`
+}
+
+// UnresolvedCredential describes a node credential reference that doesn't exist on the target instance
+type UnresolvedCredential struct {
+	Workflow string
+	Node     string
+	Type     string
+	Name     string
+	ID       string
+	Reason   string
+}
+
+// CredentialResolver rewrites node credential IDs by looking the credentials up by type and name.
+// Credential IDs differ between n8n instances while names are chosen by the user, so names are
+// the only stable way to reference a credential across environments.
+type CredentialResolver struct {
+	byName map[string]map[string]bool
+	known  map[string]bool
+	mapped map[string]string
+}
+
+// NewCredentialResolver creates an empty credential resolver
+func NewCredentialResolver() *CredentialResolver {
+	return &CredentialResolver{
+		byName: make(map[string]map[string]bool),
+		known:  make(map[string]bool),
+		mapped: make(map[string]string),
+	}
+}
+
+// credentialKey builds the lookup key of a credential
+func credentialKey(credType string, value string) string {
+	return credType + "/" + value
+}
+
+// Add registers a credential that exists on the target instance
+func (r *CredentialResolver) Add(credType string, name string, id string) {
+	if id == "" {
+		return
+	}
+
+	r.known[credentialKey(credType, id)] = true
+	if name == "" {
+		return
+	}
+
+	key := credentialKey(credType, name)
+	if r.byName[key] == nil {
+		r.byName[key] = make(map[string]bool)
+	}
+	r.byName[key][id] = true
+}
+
+// AddMapping registers an explicit mapping, which takes precedence over credentials found on the instance
+func (r *CredentialResolver) AddMapping(mapping CredentialMapping) {
+	r.mapped[credentialKey(mapping.Type, mapping.Name)] = mapping.ID
+	r.known[credentialKey(mapping.Type, mapping.ID)] = true
+}
+
+// LoadMappingFile reads credential mappings from a YAML or JSON file
+func (r *CredentialResolver) LoadMappingFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading credentials map: %w", err)
+	}
+
+	var file CredentialMappingFile
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return fmt.Errorf("error parsing credentials map: %w", err)
+	}
+
+	for i, mapping := range file.Credentials {
+		if mapping.Type == "" || mapping.Name == "" || mapping.ID == "" {
+			return fmt.Errorf("credentials map entry %d must have a type, name and id", i+1)
+		}
+		r.AddMapping(mapping)
+	}
+
+	return nil
+}
+
+// LoadFromInstance indexes the credentials referenced by the workflows of the target instance.
+// The public API has no endpoint to list credentials, so credentials that no remote workflow
+// uses yet have to be provided with a mapping file.
+func (r *CredentialResolver) LoadFromInstance(client n8n.ClientInterface) error {
+	workflowList, err := client.GetAllWorkflows()
+	if err != nil {
+		return fmt.Errorf("error fetching workflows from n8n: %w", err)
+	}
+
+	if workflowList == nil || workflowList.Data == nil {
+		return nil
+	}
+
+	for _, workflow := range *workflowList.Data {
+		for _, node := range workflow.Nodes {
+			if node.Credentials == nil {
+				continue
+			}
+			for credType, value := range *node.Credentials {
+				id, name := credentialIDAndName(value)
+				r.Add(credType, name, id)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Resolve rewrites the credential IDs of the workflow nodes and returns the references that could not be resolved
+func (r *CredentialResolver) Resolve(workflow *n8n.Workflow) []UnresolvedCredential {
+	var unresolved []UnresolvedCredential
+
+	for _, node := range workflow.Nodes {
+		if node.Credentials == nil {
+			continue
+		}
+
+		nodeName := ""
+		if node.Name != nil {
+			nodeName = *node.Name
+		}
+
+		for credType, value := range *node.Credentials {
+			id, name := credentialIDAndName(value)
+
+			resolvedID, reason := r.resolve(credType, name, id)
+			if reason != "" {
+				unresolved = append(unresolved, UnresolvedCredential{
+					Workflow: workflow.Name,
+					Node:     nodeName,
+					Type:     credType,
+					Name:     name,
+					ID:       id,
+					Reason:   reason,
+				})
+				continue
+			}
+
+			if resolvedID != id {
+				logger.Debug("Resolved credential %s '%s' of node '%s' from ID %s to %s", credType, name, nodeName, id, resolvedID)
+				(*node.Credentials)[credType] = map[string]interface{}{"id": resolvedID, "name": name}
+			}
+		}
+	}
+
+	return unresolved
+}
+
+// resolve returns the ID of a credential on the target instance, or the reason it can't be resolved
+func (r *CredentialResolver) resolve(credType string, name string, id string) (string, string) {
+	if name != "" {
+		if mappedID, ok := r.mapped[credentialKey(credType, name)]; ok {
+			return mappedID, ""
+		}
+
+		ids := r.byName[credentialKey(credType, name)]
+		if len(ids) == 1 {
+			for resolvedID := range ids {
+				return resolvedID, ""
+			}
+		}
+		if len(ids) > 1 {
+			if ids[id] {
+				return id, ""
+			}
+			return "", "ambiguous, several credentials share this name"
+		}
+	}
+
+	if id != "" && r.known[credentialKey(credType, id)] {
+		return id, ""
+	}
+
+	return "", "not found on the target instance"
+}
+
+// credentialIDAndName extracts the ID and name of a node credential reference
+func credentialIDAndName(value interface{}) (string, string) {
+	reference, ok := value.(map[string]interface{})
+	if !ok {
+		return "", ""
+	}
+
+	id, _ := reference["id"].(string)
+	name, _ := reference["name"].(string)
+	return id, name
+}
+
+// FormatUnresolvedCredentials builds an error listing every unresolved credential reference
+func FormatUnresolvedCredentials(unresolved []UnresolvedCredential) error {
+	sort.SliceStable(unresolved, func(i, j int) bool {
+		if unresolved[i].Workflow != unresolved[j].Workflow {
+			return unresolved[i].Workflow < unresolved[j].Workflow
+		}
+		return unresolved[i].Node < unresolved[j].Node
+	})
+
+	var builder strings.Builder
+	builder.WriteString("unresolved credentials, nothing was uploaded:")
+	for _, credential := range unresolved {
+		fmt.Fprintf(&builder, "\n  - workflow '%s', node '%s': %s '%s'", credential.Workflow, credential.Node, credential.Type, credential.Name)
+		if credential.ID != "" {
+			fmt.Fprintf(&builder, " (ID: %s)", credential.ID)
+		}
+		fmt.Fprintf(&builder, " %s", credential.Reason)
+	}
+	builder.WriteString("\nCreate the missing credentials in n8n or add them to the file passed with --credentials-map")
+
+	return fmt.Errorf("%s", builder.String())
+}
+
+// resolveWorkflowCredentials resolves the credential references of all workflows before any of them is uploaded
+func resolveWorkflowCredentials(client n8n.ClientInterface, cmd *cobra.Command, localWorkflows []LocalWorkflow) error {
+	resolve, _ := cmd.Flags().GetBool("resolve-credentials")
+	mapFile, _ := cmd.Flags().GetString("credentials-map")
+	if !resolve && mapFile == "" {
+		return nil
+	}
+
+	resolver := NewCredentialResolver()
+	if err := resolver.LoadFromInstance(client); err != nil {
+		return err
+	}
+
+	if mapFile != "" {
+		if err := resolver.LoadMappingFile(mapFile); err != nil {
+			return err
+		}
+	}
+
+	var unresolved []UnresolvedCredential
+	for i := range localWorkflows {
+		unresolved = append(unresolved, resolver.Resolve(&localWorkflows[i].Workflow)...)
+	}
+
+	if len(unresolved) > 0 {
+		return FormatUnresolvedCredentials(unresolved)
+	}
+
+	return nil
+}
@@ -28,7 +28,6 @@ import (
 	rootcmd "github.com/edenreich/n8n-cli/cmd"
 	"github.com/edenreich/n8n-cli/n8n"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 // DeactivateCommand represents the command to deactivate a workflow
@@ -57,10 +56,7 @@ func deactivateWorkflow(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("this command requires a workflow ID")
 	}
 
-	apiKey := viper.Get("api_key").(string)
-	instanceURL := viper.Get("instance_url").(string)
-
-	client := n8n.NewClient(instanceURL, apiKey)
+	client := newClient(cmd)
 
 	workflowID := args[0]
 	workflow, err := client.DeactivateWorkflow(workflowID)
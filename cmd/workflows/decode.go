@@ -0,0 +1,202 @@
+/*
+Copyright © 2025 Eden Reich
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package workflows
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/edenreich/n8n-cli/config"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/spf13/cobra"
+)
+
+// overlaysDirectory is the directory next to the workflow files that holds per-environment overlays
+const overlaysDirectory = "overlays"
+
+// decodeWorkflowFile reads and decodes a workflow file, applying the overlay and substitution flags of the command
+func decodeWorkflowFile(cmd *cobra.Command, filePath string) (n8n.Workflow, error) {
+	if isWorkflowDirectory(filePath) {
+		return decodeWorkflowDirectory(cmd, filePath)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return n8n.Workflow{}, fmt.Errorf("error reading file: %w", err)
+	}
+
+	decoder, err := newWorkflowDecoder(cmd, filePath)
+	if err != nil {
+		return n8n.Workflow{}, err
+	}
+
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".json":
+		workflow, err := decoder.DecodeFromJSON(content)
+		if err != nil {
+			if inWorkflowFile(err, filePath) {
+				return n8n.Workflow{}, err
+			}
+			return n8n.Workflow{}, fmt.Errorf("error parsing JSON workflow: %w", err)
+		}
+		return workflow, nil
+	case ".yaml", ".yml":
+		workflow, err := decoder.DecodeFromYAML(content)
+		if err != nil {
+			if inWorkflowFile(err, filePath) {
+				return n8n.Workflow{}, err
+			}
+			return n8n.Workflow{}, fmt.Errorf("error parsing YAML workflow: %w", err)
+		}
+		return workflow, nil
+	default:
+		return n8n.Workflow{}, fmt.Errorf("unsupported file format: %s", filepath.Ext(filePath))
+	}
+}
+
+// decodeWorkflowDirectory reads and decodes a workflow directory, applying the overlay and substitution flags of the command
+func decodeWorkflowDirectory(cmd *cobra.Command, dirPath string) (n8n.Workflow, error) {
+	files, err := readWorkflowDirectory(dirPath)
+	if err != nil {
+		return n8n.Workflow{}, err
+	}
+
+	decoder, err := newWorkflowDecoder(cmd, dirPath)
+	if err != nil {
+		return n8n.Workflow{}, err
+	}
+
+	workflow, err := decoder.DecodeFromDirectory(files)
+	if err != nil {
+		if inWorkflowFile(err, dirPath) {
+			return n8n.Workflow{}, err
+		}
+		return n8n.Workflow{}, fmt.Errorf("error parsing workflow directory: %w", err)
+	}
+	return workflow, nil
+}
+
+// inWorkflowFile sets the path of the file to the decode errors located in a workflow file or directory, and
+// reports whether the error is located. The decoder names the files of directories relative to them.
+func inWorkflowFile(err error, filePath string) bool {
+	var located *n8n.DecodeError
+	if !errors.As(err, &located) {
+		return false
+	}
+
+	if isWorkflowDirectory(filePath) {
+		file := located.File
+		if file == "" {
+			file = n8n.DirectoryWorkflowFile
+		}
+		located.File = filepath.Join(filePath, filepath.FromSlash(file))
+	} else {
+		located.File = filePath
+	}
+	return true
+}
+
+// workflowFileError wraps an error processing a workflow file, naming the file unless the error is located in it
+func workflowFileError(message string, filePath string, err error) error {
+	var located *n8n.DecodeError
+	if errors.As(err, &located) && located.File != "" {
+		return fmt.Errorf("%s: %w", message, err)
+	}
+	return fmt.Errorf("%s %s: %w", message, filePath, err)
+}
+
+// newWorkflowDecoder creates a decoder configured from the overlay and substitute flags of the command.
+// Commands that don't define these flags get a plain decoder.
+func newWorkflowDecoder(cmd *cobra.Command, filePath string) (*n8n.WorkflowDecoder, error) {
+	decoder := n8n.NewWorkflowDecoder().WithCodeFiles(readCodeFile(filePath))
+
+	overlay, _ := cmd.Flags().GetString("overlay")
+	if overlay != "" {
+		overlayPath, err := findOverlayFile(filePath, overlay)
+		if err != nil {
+			return nil, err
+		}
+
+		if overlayPath != "" {
+			content, err := os.ReadFile(overlayPath)
+			if err != nil {
+				return nil, fmt.Errorf("error reading overlay file: %w", err)
+			}
+			decoder.WithOverlay(content)
+		}
+	}
+
+	if substitute, _ := cmd.Flags().GetBool("substitute"); substitute {
+		decoder.WithVariables(config.LookupEnv)
+	}
+
+	return decoder, nil
+}
+
+// usesRendering reports whether the command applies overlays or variable substitution to workflow files
+func usesRendering(cmd *cobra.Command) bool {
+	overlay, _ := cmd.Flags().GetString("overlay")
+	substitute, _ := cmd.Flags().GetBool("substitute")
+	return overlay != "" || substitute
+}
+
+// findOverlayFile returns the overlay file for a workflow file, or an empty path when the overlay doesn't patch it
+func findOverlayFile(filePath string, overlay string) (string, error) {
+	overlayDir := filepath.Join(filepath.Dir(filePath), overlaysDirectory, overlay)
+	if info, err := os.Stat(overlayDir); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("overlay '%s' not found in %s", overlay, filepath.Dir(overlayDir))
+	}
+
+	stem := workflowStem(filePath)
+	for _, ext := range []string{".yaml", ".yml", ".json"} {
+		candidate := filepath.Join(overlayDir, stem+ext)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+
+	return "", nil
+}
+
+// workflowFileForOverlay returns the workflow file an overlay file patches, using the same lookup as findOverlayFile
+func workflowFileForOverlay(overlayPath string) (string, bool) {
+	overlayDir := filepath.Dir(overlayPath)
+	if filepath.Base(filepath.Dir(overlayDir)) != overlaysDirectory || !isWorkflowFile(overlayPath) {
+		return "", false
+	}
+
+	paths, err := workflowFilePaths(filepath.Dir(filepath.Dir(overlayDir)))
+	if err != nil {
+		return "", false
+	}
+
+	for _, filePath := range paths {
+		if overlayFile, err := findOverlayFile(filePath, filepath.Base(overlayDir)); err == nil && overlayFile == overlayPath {
+			return filePath, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,292 @@
+/*
+Copyright © 2025 Eden Reich
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package workflows
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	rootcmd "github.com/edenreich/n8n-cli/cmd"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/spf13/cobra"
+)
+
+// DedupeCmd represents the dedupe command
+var DedupeCmd = &cobra.Command{
+	Use:   "dedupe",
+	Short: "Find workflows sharing the same name in n8n and remove the duplicates",
+	Long: `Dedupe lists the workflows in n8n that share the same name, which typically happens when files
+without an ID were synced more than once. For every name, one workflow is kept: the one in the local
+files when --directory is given, otherwise a managed, then an active, then the most recently updated one.
+Only duplicates managed by the CLI are deleted, unless --include-unmanaged is set. Deleted workflows
+are backed up in the .n8n/backups directory of --directory, or of the current directory without it,
+and can be restored with rollback.
+
+Examples:
+
+  # List duplicate workflows
+  n8n workflows dedupe
+
+  # Keep the workflows in the directory and delete their duplicates
+  n8n workflows dedupe --directory workflows/ --delete`,
+	Args: cobra.ExactArgs(0),
+	RunE: dedupeWorkflows,
+}
+
+func init() {
+	DedupeCmd.Flags().StringP("directory", "d", "", "Directory containing workflow files, the workflows in it are kept and deleted workflows are backed up in it instead of the current directory")
+	DedupeCmd.Flags().Bool("delete", false, "Delete the duplicates instead of only listing them")
+	DedupeCmd.Flags().Bool("include-unmanaged", false, "Also delete duplicates that are not managed by the CLI")
+	DedupeCmd.Flags().BoolP("yes", "y", false, "Don't ask for confirmation before deleting")
+	DedupeCmd.Flags().Bool("dry-run", false, "Show what would be deleted without making changes")
+	rootcmd.GetWorkflowsCmd().AddCommand(DedupeCmd)
+}
+
+// DuplicateGroup is a set of remote workflows sharing the same name
+type DuplicateGroup struct {
+	Name string
+	// Keep is the workflow that is kept, nil when it can't be decided which one to keep
+	Keep *n8n.Workflow
+	// Reason explains why Keep was chosen, or why no workflow could be chosen
+	Reason     string
+	Duplicates []n8n.Workflow
+}
+
+// dedupeWorkflows is the handler for the dedupe command
+func dedupeWorkflows(cmd *cobra.Command, args []string) error {
+	client := newClient(cmd)
+
+	return DedupeWorkflowsWithClient(cmd, client)
+}
+
+// DedupeWorkflowsWithClient is the testable version of the dedupe command that accepts a client interface
+func DedupeWorkflowsWithClient(cmd *cobra.Command, client n8n.ClientInterface) error {
+	directory, _ := cmd.Flags().GetString("directory")
+	deleteDuplicates, _ := cmd.Flags().GetBool("delete")
+	yes, _ := cmd.Flags().GetBool("yes")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	localIDs := make(map[string]bool)
+	if directory != "" {
+		localFiles, err := extractLocalWorkflows(directory)
+		if err != nil {
+			return err
+		}
+		for workflowID := range localFiles {
+			localIDs[workflowID] = true
+		}
+	}
+
+	workflowList, err := client.GetAllWorkflows()
+	if err != nil {
+		return fmt.Errorf("error fetching workflows: %w", err)
+	}
+
+	var remoteWorkflows []n8n.Workflow
+	if workflowList != nil && workflowList.Data != nil {
+		remoteWorkflows = *workflowList.Data
+	}
+
+	groups := FindDuplicateWorkflows(remoteWorkflows, localIDs)
+	if len(groups) == 0 {
+		cmd.Println("No duplicate workflow names found")
+		return nil
+	}
+
+	var duplicates []n8n.Workflow
+	for _, group := range groups {
+		ids := make([]string, 0, len(group.Duplicates))
+		for _, workflow := range group.Duplicates {
+			ids = append(ids, *workflow.Id)
+		}
+
+		if group.Keep == nil {
+			cmd.Printf("Duplicate name '%s': can't decide which workflow to keep, %s (IDs: %s)\n", group.Name, group.Reason, strings.Join(ids, ", "))
+			continue
+		}
+
+		cmd.Printf("Duplicate name '%s': keeping ID %s (%s), duplicates: %s\n", group.Name, *group.Keep.Id, group.Reason, strings.Join(ids, ", "))
+		duplicates = append(duplicates, group.Duplicates...)
+	}
+
+	if len(duplicates) == 0 {
+		return nil
+	}
+
+	if !deleteDuplicates {
+		cmd.Printf("Found %d duplicate workflow(s), remove them with --delete\n", len(duplicates))
+		return nil
+	}
+
+	// Like pruning, workflows created in the n8n UI are only deleted when asked for
+	if !includeUnmanaged(cmd) {
+		var managed []n8n.Workflow
+		for _, workflow := range duplicates {
+			if IsManaged(workflow) {
+				managed = append(managed, workflow)
+			}
+		}
+		if skipped := len(duplicates) - len(managed); skipped > 0 {
+			cmd.Printf("Skipped %d duplicate workflow(s) not managed by the CLI, use --include-unmanaged to delete them\n", skipped)
+		}
+		duplicates = managed
+	}
+
+	if len(duplicates) == 0 {
+		return nil
+	}
+
+	if !dryRun && !yes && isInteractive(cmd) {
+		confirmed, err := confirmPrune(cmd, duplicates, false)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			cmd.Println("Dedupe cancelled, no workflows were removed")
+			return nil
+		}
+	}
+
+	// Deleted workflows are always backed up, in the current directory when no workflows directory is given
+	var backup *Backup
+	if !dryRun {
+		backupDirectory := directory
+		if backupDirectory == "" {
+			backupDirectory = "."
+		}
+		backup = NewBackup(newBackupPath(backupDirectory))
+		client = &BackupClient{ClientInterface: client, Backup: backup}
+	}
+
+	for _, workflow := range duplicates {
+		workflowID := *workflow.Id
+		dryRunMsg := fmt.Sprintf("Would delete duplicate workflow '%s' (ID: %s)", workflow.Name, workflowID)
+		err := ExecuteOrDryRun(cmd, dryRun, dryRunMsg, func() (string, error) {
+			if err := client.DeleteWorkflow(workflowID); err != nil {
+				return "", fmt.Errorf("error deleting workflow %s (%s): %w", workflow.Name, workflowID, err)
+			}
+			return fmt.Sprintf("Deleted duplicate workflow '%s' (ID: %s)", workflow.Name, workflowID), nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if backup != nil && backup.Len() > 0 {
+		cmd.Printf("Saved the deleted workflows to %s, restore them with: n8n workflows rollback %s\n", backup.Path, backup.Path)
+	}
+
+	return nil
+}
+
+// FindDuplicateWorkflows groups the workflows sharing the same name and decides which one of each group to keep:
+// the one in the local files, otherwise a managed one, an active one, or the most recently updated one
+func FindDuplicateWorkflows(workflows []n8n.Workflow, localIDs map[string]bool) []DuplicateGroup {
+	byName := make(map[string][]n8n.Workflow)
+	var names []string
+	for _, workflow := range workflows {
+		if workflow.Id == nil {
+			continue
+		}
+		if _, seen := byName[workflow.Name]; !seen {
+			names = append(names, workflow.Name)
+		}
+		byName[workflow.Name] = append(byName[workflow.Name], workflow)
+	}
+	sort.Strings(names)
+
+	var groups []DuplicateGroup
+	for _, name := range names {
+		candidates := byName[name]
+		if len(candidates) < 2 {
+			continue
+		}
+
+		local := 0
+		for _, workflow := range candidates {
+			if localIDs[*workflow.Id] {
+				local++
+			}
+		}
+		if local > 1 {
+			groups = append(groups, DuplicateGroup{Name: name, Reason: fmt.Sprintf("%d of them are in the local files", local), Duplicates: candidates})
+			continue
+		}
+
+		sort.SliceStable(candidates, func(i, j int) bool {
+			pi, pj := keepPriority(candidates[i], localIDs), keepPriority(candidates[j], localIDs)
+			if pi != pj {
+				return pi < pj
+			}
+			return updatedAfter(candidates[i], candidates[j])
+		})
+
+		keep := candidates[0]
+		groups = append(groups, DuplicateGroup{
+			Name:       name,
+			Keep:       &keep,
+			Reason:     keepReason(keep, candidates[1], localIDs),
+			Duplicates: candidates[1:],
+		})
+	}
+
+	return groups
+}
+
+// keepPriority ranks which workflow of a duplicate group to keep, lower is kept first
+func keepPriority(workflow n8n.Workflow, localIDs map[string]bool) int {
+	switch {
+	case localIDs[*workflow.Id]:
+		return 0
+	case IsManaged(workflow):
+		return 1
+	case workflow.Active != nil && *workflow.Active:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// keepReason explains why a workflow was kept over the next candidate
+func keepReason(keep n8n.Workflow, next n8n.Workflow, localIDs map[string]bool) string {
+	if keepPriority(keep, localIDs) == keepPriority(next, localIDs) {
+		return "most recently updated"
+	}
+
+	switch keepPriority(keep, localIDs) {
+	case 0:
+		return "in the local files"
+	case 1:
+		return "managed by the CLI"
+	default:
+		return "active"
+	}
+}
+
+// updatedAfter reports whether a workflow was updated more recently than another
+func updatedAfter(a n8n.Workflow, b n8n.Workflow) bool {
+	if a.UpdatedAt == nil || b.UpdatedAt == nil {
+		return a.UpdatedAt != nil
+	}
+	return a.UpdatedAt.After(*b.UpdatedAt)
+}
@@ -0,0 +1,216 @@
+/*
+Copyright © 2025 Eden Reich
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package workflows
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/edenreich/n8n-cli/n8n"
+)
+
+// executeWorkflowNodeType is the node type that calls another workflow as a sub-workflow
+const executeWorkflowNodeType = "n8n-nodes-base.executeWorkflow"
+
+// WorkflowReference is a reference from a workflow to another workflow by ID
+type WorkflowReference struct {
+	// Node is the name of the Execute Workflow node, empty for the error workflow setting
+	Node       string
+	WorkflowID string
+}
+
+// describe returns a human readable description of where the reference is made
+func (r WorkflowReference) describe() string {
+	if r.Node == "" {
+		return "error workflow setting"
+	}
+	return fmt.Sprintf("node '%s'", r.Node)
+}
+
+// WorkflowReferences returns the workflows referenced by the error workflow setting and the Execute Workflow nodes.
+// References made with expressions or loaded from other sources than the database are ignored.
+func WorkflowReferences(workflow *n8n.Workflow) []WorkflowReference {
+	var references []WorkflowReference
+
+	if workflow.Settings.ErrorWorkflow != nil && *workflow.Settings.ErrorWorkflow != "" {
+		references = append(references, WorkflowReference{WorkflowID: *workflow.Settings.ErrorWorkflow})
+	}
+
+	for _, node := range workflow.Nodes {
+		if node.Type == nil || *node.Type != executeWorkflowNodeType || node.Parameters == nil {
+			continue
+		}
+
+		if source, ok := (*node.Parameters)["source"].(string); ok && source != "database" {
+			continue
+		}
+
+		id := subWorkflowID((*node.Parameters)["workflowId"])
+		if id == "" || strings.HasPrefix(id, "=") {
+			continue
+		}
+
+		name := ""
+		if node.Name != nil {
+			name = *node.Name
+		}
+		references = append(references, WorkflowReference{Node: name, WorkflowID: id})
+	}
+
+	return references
+}
+
+// subWorkflowID extracts the workflow ID from a workflowId parameter, which is either a plain
+// string or a resource locator object with the ID in its value
+func subWorkflowID(parameter interface{}) string {
+	switch value := parameter.(type) {
+	case string:
+		return value
+	case map[string]interface{}:
+		id, _ := value["value"].(string)
+		return id
+	default:
+		return ""
+	}
+}
+
+// RewriteWorkflowReferences replaces referenced workflow IDs with the IDs they got on the target instance
+func RewriteWorkflowReferences(workflow *n8n.Workflow, ids map[string]string) {
+	if workflow.Settings.ErrorWorkflow != nil {
+		if target, ok := ids[*workflow.Settings.ErrorWorkflow]; ok {
+			workflow.Settings.ErrorWorkflow = &target
+		}
+	}
+
+	for _, node := range workflow.Nodes {
+		if node.Type == nil || *node.Type != executeWorkflowNodeType || node.Parameters == nil {
+			continue
+		}
+
+		parameters := *node.Parameters
+		switch value := parameters["workflowId"].(type) {
+		case string:
+			if target, ok := ids[value]; ok {
+				parameters["workflowId"] = target
+			}
+		case map[string]interface{}:
+			id, _ := value["value"].(string)
+			if target, ok := ids[id]; ok {
+				locator := make(map[string]interface{}, len(value))
+				for key, item := range value {
+					locator[key] = item
+				}
+				locator["value"] = target
+				parameters["workflowId"] = locator
+			}
+		}
+	}
+}
+
+// SortWorkflowsByDependencies orders workflows so that referenced workflows are synced before the workflows
+// referencing them. References to workflows that are neither in the directory nor on the instance and
+// circular references are reported as errors.
+func SortWorkflowsByDependencies(client n8n.ClientInterface, localWorkflows []LocalWorkflow) ([]LocalWorkflow, error) {
+	indexByID := make(map[string]int)
+	for i, local := range localWorkflows {
+		if local.Workflow.Id != nil && *local.Workflow.Id != "" {
+			indexByID[*local.Workflow.Id] = i
+		}
+	}
+
+	dependencies := make([][]int, len(localWorkflows))
+	dependents := make([][]int, len(localWorkflows))
+	remoteExists := make(map[string]bool)
+	var missing []string
+
+	for i := range localWorkflows {
+		workflow := &localWorkflows[i].Workflow
+		for _, reference := range WorkflowReferences(workflow) {
+			if j, ok := indexByID[reference.WorkflowID]; ok {
+				if j != i {
+					dependencies[i] = append(dependencies[i], j)
+					dependents[j] = append(dependents[j], i)
+				}
+				continue
+			}
+
+			exists, checked := remoteExists[reference.WorkflowID]
+			if !checked {
+				// Only a workflow n8n doesn't know is missing, other errors don't tell whether it exists
+				_, err := client.GetWorkflow(reference.WorkflowID)
+				if err != nil && !errors.Is(err, n8n.ErrWorkflowNotFound) {
+					return nil, fmt.Errorf("error checking workflow %s referenced by workflow '%s': %w", reference.WorkflowID, workflow.Name, err)
+				}
+				exists = err == nil
+				remoteExists[reference.WorkflowID] = exists
+			}
+
+			if !exists {
+				missing = append(missing, fmt.Sprintf("workflow '%s' references missing workflow %s in its %s", workflow.Name, reference.WorkflowID, reference.describe()))
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("unresolved workflow references, they are neither in the directory nor on the n8n instance:\n  - %s", strings.Join(missing, "\n  - "))
+	}
+
+	pending := make([]int, len(localWorkflows))
+	var ready []int
+	for i := range localWorkflows {
+		pending[i] = len(dependencies[i])
+		if pending[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	sorted := make([]LocalWorkflow, 0, len(localWorkflows))
+	done := make([]bool, len(localWorkflows))
+	for len(ready) > 0 {
+		i := ready[0]
+		ready = ready[1:]
+		sorted = append(sorted, localWorkflows[i])
+		done[i] = true
+
+		for _, dependent := range dependents[i] {
+			pending[dependent]--
+			if pending[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+		sort.Ints(ready)
+	}
+
+	if len(sorted) < len(localWorkflows) {
+		var cycle []string
+		for i, local := range localWorkflows {
+			if !done[i] {
+				cycle = append(cycle, fmt.Sprintf("'%s'", local.Workflow.Name))
+			}
+		}
+		return nil, fmt.Errorf("circular workflow references involving %s", strings.Join(cycle, ", "))
+	}
+
+	return sorted, nil
+}
@@ -0,0 +1,206 @@
+/*
+Copyright © 2025 Eden Reich
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package workflows
+
+import (
+	"bytes"
+	"fmt"
+	"hash"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/edenreich/n8n-cli/n8n"
+)
+
+// outputDirectory is the output format that stores every workflow in its own directory
+const outputDirectory = "dir"
+
+// isWorkflowDirectory reports whether a path is a workflow directory, a directory with a workflow.yaml
+func isWorkflowDirectory(filePath string) bool {
+	info, err := os.Stat(filepath.Join(filePath, n8n.DirectoryWorkflowFile))
+	return err == nil && !info.IsDir()
+}
+
+// isDirectoryFormat reports whether a workflow path is stored as a workflow directory rather than a single file
+func isDirectoryFormat(filePath string) bool {
+	return !isWorkflowFile(filePath)
+}
+
+// workflowFormat returns the format a workflow path is stored in: json, yaml or dir
+func workflowFormat(filePath string) string {
+	if isDirectoryFormat(filePath) {
+		return outputDirectory
+	}
+	if strings.EqualFold(filepath.Ext(filePath), ".json") {
+		return "json"
+	}
+	return "yaml"
+}
+
+// fileFormat normalizes the value of an --output flag, returning an empty string for unknown formats
+func fileFormat(output string) string {
+	switch strings.ToLower(output) {
+	case "json":
+		return "json"
+	case "yaml", "yml":
+		return "yaml"
+	case outputDirectory:
+		return outputDirectory
+	}
+	return ""
+}
+
+// workflowStem returns the name of a workflow file without its extension, or the name of a workflow directory
+func workflowStem(filePath string) string {
+	base := filepath.Base(filePath)
+	if isDirectoryFormat(filePath) {
+		return base
+	}
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// readWorkflowDirectory reads workflow.yaml and the files in nodes/ of a workflow directory, keyed by their
+// slash separated path relative to the directory
+func readWorkflowDirectory(dirPath string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+
+	content, err := os.ReadFile(filepath.Join(dirPath, n8n.DirectoryWorkflowFile))
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+	files[n8n.DirectoryWorkflowFile] = content
+
+	nodesDir := filepath.Join(dirPath, n8n.DirectoryNodesDir)
+	err = filepath.WalkDir(nodesDir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && filePath == nodesDir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(dirPath, filePath)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(relPath)] = content
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading workflow directory %s: %w", dirPath, err)
+	}
+
+	return files, nil
+}
+
+// serializeWorkflowDirectory serializes a workflow to the files of a workflow directory
+func serializeWorkflowDirectory(workflow n8n.Workflow, minimal bool) (map[string][]byte, error) {
+	files, err := n8n.NewWorkflowEncoder(minimal).EncodeToDirectory(workflow)
+	if err != nil {
+		return nil, fmt.Errorf("error serializing workflow '%s' to a directory: %w", workflow.Name, err)
+	}
+	return files, nil
+}
+
+// workflowDirectoryChanged reports whether writing the given files to a workflow directory would change it
+func workflowDirectoryChanged(dirPath string, files map[string][]byte) bool {
+	existing, err := readWorkflowDirectory(dirPath)
+	if err != nil || len(existing) != len(files) {
+		return true
+	}
+
+	for filePath, content := range files {
+		if !bytes.Equal(existing[filePath], content) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeWorkflowDirectory writes the files of a workflow directory and removes the files in nodes/ that don't
+// belong to the workflow anymore, such as the files of deleted or renamed nodes
+func writeWorkflowDirectory(dirPath string, files map[string][]byte) error {
+	existing, err := readWorkflowDirectory(dirPath)
+	if err != nil {
+		existing = nil
+	}
+
+	for _, filePath := range n8n.DirectoryFilePaths(files) {
+		fullPath := filepath.Join(dirPath, filepath.FromSlash(filePath))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			return fmt.Errorf("error creating directory: %w", err)
+		}
+		if err := os.WriteFile(fullPath, files[filePath], 0644); err != nil {
+			return fmt.Errorf("error writing file %s: %w", fullPath, err)
+		}
+	}
+
+	for filePath := range existing {
+		if _, ok := files[filePath]; ok {
+			continue
+		}
+		fullPath := filepath.Join(dirPath, filepath.FromSlash(filePath))
+		if err := os.Remove(fullPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error removing file %s: %w", fullPath, err)
+		}
+	}
+
+	return nil
+}
+
+// workflowDirectoryHash writes the content of the files of a workflow directory to a hash
+func workflowDirectoryHash(dirPath string, h hash.Hash) bool {
+	files, err := readWorkflowDirectory(dirPath)
+	if err != nil {
+		return false
+	}
+
+	for _, filePath := range n8n.DirectoryFilePaths(files) {
+		h.Write([]byte(filePath))
+		h.Write(files[filePath])
+	}
+	return true
+}
+
+// workflowDirectoryForFile returns the workflow directory a file belongs to, or false when it isn't part of one
+func workflowDirectoryForFile(filePath string) (string, bool) {
+	dir := filepath.Dir(filePath)
+	if filepath.Base(filePath) == n8n.DirectoryWorkflowFile && isWorkflowDirectory(dir) {
+		return dir, true
+	}
+
+	if filepath.Base(dir) == n8n.DirectoryNodesDir && isWorkflowDirectory(filepath.Dir(dir)) {
+		return filepath.Dir(dir), true
+	}
+
+	return "", false
+}
@@ -0,0 +1,288 @@
+/*
+Copyright © 2025 Eden Reich
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package workflows
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	rootcmd "github.com/edenreich/n8n-cli/cmd"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// DriftExitCode is the exit code of the drift command when the instance differs from the directory
+const DriftExitCode = 2
+
+// Kinds of drift between the directory and the instance
+const (
+	DriftInSync     = "in-sync"
+	DriftChanged    = "changed"
+	DriftOnlyRemote = "only-remote"
+	DriftOnlyLocal  = "only-local"
+)
+
+// WorkflowDrift describes how a workflow on the instance differs from the directory
+type WorkflowDrift struct {
+	Kind     string
+	ID       string
+	Name     string
+	FilePath string
+	// Reasons lists what differs for changed workflows
+	Reasons []string
+	// RemoteChanged is set when the workflow was changed in n8n since it was last refreshed or synced
+	RemoteChanged bool
+}
+
+// DriftReport is the result of comparing the instance against a workflows directory
+type DriftReport struct {
+	Drift  []WorkflowDrift
+	InSync []WorkflowDrift
+}
+
+// HasDrift reports whether any workflow differs between the directory and the instance
+func (r *DriftReport) HasDrift() bool {
+	return len(r.Drift) > 0
+}
+
+// DriftCmd represents the drift command
+var DriftCmd = &cobra.Command{
+	Use:   "drift",
+	Short: "Compare the n8n instance against the workflows directory without changing anything",
+	Long: `Drift compares the workflows on the n8n instance against the workflow files in a directory and
+lists the workflows that differ, exist only in n8n or exist only locally. Nothing is changed.
+
+Workflows only in n8n are reported when they are managed by the CLI, unless --include-unmanaged is set.
+
+Exit codes:
+
+  0  The instance is in sync with the directory
+  1  An error occurred
+  2  Drift was found
+
+Examples:
+
+  # Check a production instance for manual edits in a nightly job
+  n8n workflows drift --directory workflows/ --overlay production`,
+	Args: cobra.ExactArgs(0),
+	RunE: driftWorkflows,
+}
+
+func init() {
+	DriftCmd.Flags().StringP("directory", "d", "", "Directory containing workflow files (JSON/YAML) (required)")
+	DriftCmd.Flags().Bool("include-unmanaged", false, "Also report workflows only in n8n that are not managed by the CLI")
+	DriftCmd.Flags().String("overlay", "", "Name of the overlay to apply from the overlays/<name>/ directory before comparing")
+	DriftCmd.Flags().Bool("substitute", false, "Replace ${VAR} placeholders with values from the environment or .env file before comparing")
+	rootcmd.GetWorkflowsCmd().AddCommand(DriftCmd)
+
+	// nolint:errcheck
+	DriftCmd.MarkFlagRequired("directory")
+}
+
+// driftWorkflows is the handler for the drift command
+func driftWorkflows(cmd *cobra.Command, args []string) error {
+	apiKey := viper.Get("api_key").(string)
+	instanceURL := viper.Get("instance_url").(string)
+
+	client := n8n.NewClient(instanceURL, apiKey)
+
+	return DriftWorkflowsWithClient(cmd, client)
+}
+
+// DriftWorkflowsWithClient is the testable version of the drift command that accepts a client interface.
+// It returns a rootcmd.ExitError with DriftExitCode when drift was found.
+func DriftWorkflowsWithClient(cmd *cobra.Command, client n8n.ClientInterface) error {
+	directory, _ := cmd.Flags().GetString("directory")
+	if directory == "" {
+		return fmt.Errorf("directory is required")
+	}
+
+	report, err := DetectDrift(client, cmd, directory)
+	if err != nil {
+		return err
+	}
+
+	printDriftReport(cmd, report)
+
+	if report.HasDrift() {
+		cmd.SilenceUsage = true
+		return &rootcmd.ExitError{
+			Code: DriftExitCode,
+			Err:  fmt.Errorf("drift found in %d workflow(s)", len(report.Drift)),
+		}
+	}
+
+	return nil
+}
+
+// DetectDrift compares the workflows on the instance against the workflow files in a directory
+func DetectDrift(client n8n.ClientInterface, cmd *cobra.Command, directory string) (*DriftReport, error) {
+	localWorkflows, err := LoadWorkflowFiles(cmd, directory)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := LoadWorkflowState(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	workflowList, err := client.GetAllWorkflows()
+	if err != nil {
+		return nil, fmt.Errorf("error getting workflows from n8n: %w", err)
+	}
+
+	remoteWorkflows := make(map[string]n8n.Workflow)
+	if workflowList != nil && workflowList.Data != nil {
+		for _, workflow := range *workflowList.Data {
+			if workflow.Id != nil && *workflow.Id != "" {
+				remoteWorkflows[*workflow.Id] = workflow
+			}
+		}
+	}
+
+	report := &DriftReport{}
+	localIDs := make(map[string]bool)
+
+	for _, local := range localWorkflows {
+		drift := WorkflowDrift{Name: local.Workflow.Name, FilePath: local.FilePath}
+
+		if local.Workflow.Id == nil || *local.Workflow.Id == "" {
+			drift.Kind = DriftOnlyLocal
+			report.Drift = append(report.Drift, drift)
+			continue
+		}
+
+		drift.ID = *local.Workflow.Id
+		localIDs[drift.ID] = true
+
+		remote, exists := remoteWorkflows[drift.ID]
+		if !exists {
+			drift.Kind = DriftOnlyLocal
+			report.Drift = append(report.Drift, drift)
+			continue
+		}
+
+		drift.Reasons = workflowDriftReasons(&local.Workflow, &remote)
+		if len(drift.Reasons) == 0 {
+			drift.Kind = DriftInSync
+			report.InSync = append(report.InSync, drift)
+			continue
+		}
+
+		_, remoteChanged, err := state.RemoteChanged(remote)
+		if err != nil {
+			return nil, err
+		}
+
+		drift.Kind = DriftChanged
+		drift.RemoteChanged = remoteChanged
+		report.Drift = append(report.Drift, drift)
+	}
+
+	unmanaged := includeUnmanaged(cmd)
+	var onlyRemote []WorkflowDrift
+	for id, remote := range remoteWorkflows {
+		if localIDs[id] || (!unmanaged && !IsManaged(remote)) {
+			continue
+		}
+		onlyRemote = append(onlyRemote, WorkflowDrift{Kind: DriftOnlyRemote, ID: id, Name: remote.Name})
+	}
+	sort.Slice(onlyRemote, func(i, j int) bool { return onlyRemote[i].Name < onlyRemote[j].Name })
+	report.Drift = append(report.Drift, onlyRemote...)
+
+	return report, nil
+}
+
+// workflowDriftReasons lists what differs between a local workflow and its remote version
+func workflowDriftReasons(local *n8n.Workflow, remote *n8n.Workflow) []string {
+	var reasons []string
+
+	changes := DetectWorkflowChanges(local, remote)
+	if changes.NeedsUpdate {
+		reasons = append(reasons, "content differs")
+	}
+	if changes.NeedsActivation {
+		reasons = append(reasons, "inactive in n8n")
+	}
+	if changes.NeedsDeactivation {
+		reasons = append(reasons, "active in n8n")
+	}
+	if local.Tags != nil && len(*local.Tags) > 0 && !sameTagNames(*local.Tags, remote.Tags) {
+		reasons = append(reasons, "tags differ")
+	}
+
+	return reasons
+}
+
+// sameTagNames reports whether two tag lists carry the same tag names, ignoring the managed tag
+func sameTagNames(local []n8n.Tag, remote *[]n8n.Tag) bool {
+	names := func(tags []n8n.Tag) []string {
+		var result []string
+		for _, tag := range tags {
+			if tag.Name != ManagedTag() {
+				result = append(result, tag.Name)
+			}
+		}
+		sort.Strings(result)
+		return result
+	}
+
+	var remoteTags []n8n.Tag
+	if remote != nil {
+		remoteTags = *remote
+	}
+
+	return strings.Join(names(local), "\x00") == strings.Join(names(remoteTags), "\x00")
+}
+
+// printDriftReport prints the workflows that differ between the directory and the instance
+func printDriftReport(cmd *cobra.Command, report *DriftReport) {
+	for _, drift := range report.Drift {
+		switch drift.Kind {
+		case DriftChanged:
+			origin := "local changes not synced"
+			if drift.RemoteChanged {
+				origin = "changed in n8n since last refresh"
+			}
+			cmd.Printf("Changed: '%s' (ID: %s) from %s: %s (%s)\n", drift.Name, drift.ID, filepath.Base(drift.FilePath), strings.Join(drift.Reasons, ", "), origin)
+		case DriftOnlyRemote:
+			cmd.Printf("Only in n8n: '%s' (ID: %s)\n", drift.Name, drift.ID)
+		case DriftOnlyLocal:
+			if drift.ID != "" {
+				cmd.Printf("Only local: '%s' (ID: %s) from %s\n", drift.Name, drift.ID, filepath.Base(drift.FilePath))
+			} else {
+				cmd.Printf("Only local: '%s' from %s\n", drift.Name, filepath.Base(drift.FilePath))
+			}
+		}
+	}
+
+	if report.HasDrift() {
+		cmd.Printf("Drift found in %d workflow(s), %d in sync\n", len(report.Drift), len(report.InSync))
+		return
+	}
+
+	cmd.Printf("No drift found, %d workflow(s) in sync\n", len(report.InSync))
+}
@@ -0,0 +1,256 @@
+/*
+Copyright © 2025 Eden Reich
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package workflows
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+
+	rootcmd "github.com/edenreich/n8n-cli/cmd"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// ExportCmd represents the export command
+var ExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Package workflows into an archive to import into another n8n instance",
+	Long: `Export packages workflows from the n8n instance into a gzipped tarball, together with the tags, variables
+and credentials they use, to hand off a feature set to another instance with 'n8n workflows import --archive'.
+
+Workflows called by the selected workflows through Execute Workflow nodes or the error workflow setting are
+included too. Credentials are exported as stubs with their name and type only, their secrets never leave the
+instance: create credentials with the same names on the target instance before importing. The archive has a
+manifest with the checksum of every file, so changes made to it after the export are detected on import.
+
+Examples:
+
+  # Export the workflows tagged "billing"
+  n8n workflows export --archive billing.tar.gz --tag billing
+
+  # Export two workflows by ID
+  n8n workflows export --archive handoff.tar.gz --id Yk6hVJ9vDx2mPq1c --id 3fTq8Lm2Zc7WbN0e`,
+	Args: cobra.NoArgs,
+	RunE: exportWorkflows,
+}
+
+func init() {
+	ExportCmd.Flags().String("archive", "", "Path of the archive to write (required)")
+	ExportCmd.Flags().StringSlice("name", nil, "Only export workflows whose name matches one of these glob patterns")
+	ExportCmd.Flags().StringSlice("tag", nil, "Only export workflows carrying one of these tags")
+	ExportCmd.Flags().StringSlice("id", nil, "Only export workflows with one of these IDs")
+	rootcmd.GetWorkflowsCmd().AddCommand(ExportCmd)
+
+	// nolint:errcheck
+	ExportCmd.MarkFlagRequired("archive")
+}
+
+// exportWorkflows is the handler for the export command
+func exportWorkflows(cmd *cobra.Command, args []string) error {
+	archivePath, _ := cmd.Flags().GetString("archive")
+
+	apiKey := viper.Get("api_key").(string)
+	instanceURL := viper.Get("instance_url").(string)
+	client := n8n.NewClient(instanceURL, apiKey)
+
+	_, err := ExportArchive(cmd, client, archivePath)
+	return err
+}
+
+// ExportArchive writes the selected workflows of the instance and the workflows they call to an archive
+func ExportArchive(cmd *cobra.Command, client n8n.ClientInterface, archivePath string) (*Archive, error) {
+	selector, err := NewWorkflowSelector(cmd, "")
+	if err != nil {
+		return nil, err
+	}
+
+	workflowList, err := client.GetAllWorkflows()
+	if err != nil {
+		return nil, fmt.Errorf("error fetching workflows from n8n: %w", err)
+	}
+
+	remoteWorkflows := make(map[string]n8n.Workflow)
+	var selected []n8n.Workflow
+	if workflowList != nil && workflowList.Data != nil {
+		for _, workflow := range *workflowList.Data {
+			if workflow.Id == nil {
+				continue
+			}
+			remoteWorkflows[*workflow.Id] = workflow
+			if selector.Matches(workflow, "") {
+				selected = append(selected, workflow)
+			}
+		}
+	}
+
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no workflows on the instance match the selection")
+	}
+
+	workflows, err := includeReferencedWorkflows(client, cmd, selected, remoteWorkflows)
+	if err != nil {
+		return nil, err
+	}
+
+	variables, err := referencedVariables(client, cmd, workflows)
+	if err != nil {
+		return nil, err
+	}
+
+	archive := NewArchive(workflows, variables)
+	if err := archive.Write(archivePath); err != nil {
+		return nil, err
+	}
+
+	cmd.Printf("Exported %d workflow(s), %d tag(s), %d variable(s) and %d credential(s) to %s\n",
+		len(archive.Workflows), len(archive.Tags), len(archive.Variables), len(archive.Credentials), archivePath)
+	return archive, nil
+}
+
+// includeReferencedWorkflows adds the workflows the given workflows call, directly or through other workflows
+func includeReferencedWorkflows(client n8n.ClientInterface, cmd *cobra.Command, selected []n8n.Workflow, remoteWorkflows map[string]n8n.Workflow) ([]n8n.Workflow, error) {
+	included := make(map[string]bool)
+	for _, workflow := range selected {
+		included[*workflow.Id] = true
+	}
+
+	workflows := append([]n8n.Workflow{}, selected...)
+	for i := 0; i < len(workflows); i++ {
+		for _, reference := range WorkflowReferences(&workflows[i]) {
+			if included[reference.WorkflowID] {
+				continue
+			}
+
+			referenced, ok := remoteWorkflows[reference.WorkflowID]
+			if !ok {
+				fetched, err := client.GetWorkflow(reference.WorkflowID)
+				if err != nil {
+					return nil, fmt.Errorf("workflow '%s' references workflow %s in its %s, which doesn't exist on the instance",
+						workflows[i].Name, reference.WorkflowID, reference.describe())
+				}
+				referenced = *fetched
+			}
+
+			included[reference.WorkflowID] = true
+			workflows = append(workflows, referenced)
+			cmd.Printf("Including workflow '%s' (ID: %s) referenced by '%s'\n", referenced.Name, reference.WorkflowID, workflows[i].Name)
+		}
+	}
+
+	return workflows, nil
+}
+
+// variableReferencePattern matches $vars.NAME and $vars["NAME"] in expressions and code
+var variableReferencePattern = regexp.MustCompile(`\$vars(?:\.([A-Za-z_][A-Za-z0-9_]*)|\[\s*["']([^"']+)["']\s*\])`)
+
+// VariableReferences returns the names of the variables the nodes of a workflow use, sorted by name
+func VariableReferences(workflow *n8n.Workflow) []string {
+	content, err := json.Marshal(workflow.Nodes)
+	if err != nil {
+		return nil
+	}
+
+	var nodes interface{}
+	if err := json.Unmarshal(content, &nodes); err != nil {
+		return nil
+	}
+
+	names := make(map[string]bool)
+	collectVariableReferences(nodes, names)
+
+	references := make([]string, 0, len(names))
+	for name := range names {
+		references = append(references, name)
+	}
+	sort.Strings(references)
+
+	return references
+}
+
+// collectVariableReferences adds the variables used by the strings of a decoded value to names
+func collectVariableReferences(value interface{}, names map[string]bool) {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for _, item := range typed {
+			collectVariableReferences(item, names)
+		}
+	case []interface{}:
+		for _, item := range typed {
+			collectVariableReferences(item, names)
+		}
+	case string:
+		for _, match := range variableReferencePattern.FindAllStringSubmatch(typed, -1) {
+			if match[1] != "" {
+				names[match[1]] = true
+			} else {
+				names[match[2]] = true
+			}
+		}
+	}
+}
+
+// referencedVariables fetches the variables used by the workflows. Variables that don't exist on the
+// instance are reported and left out.
+func referencedVariables(client n8n.ClientInterface, cmd *cobra.Command, workflows []n8n.Workflow) ([]n8n.Variable, error) {
+	usedBy := make(map[string]string)
+	var names []string
+	for i := range workflows {
+		for _, name := range VariableReferences(&workflows[i]) {
+			if _, seen := usedBy[name]; !seen {
+				usedBy[name] = workflows[i].Name
+				names = append(names, name)
+			}
+		}
+	}
+
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	variableList, err := client.GetVariables()
+	if err != nil {
+		return nil, fmt.Errorf("error fetching variables from n8n: %w", err)
+	}
+
+	existing := make(map[string]n8n.Variable)
+	if variableList != nil && variableList.Data != nil {
+		for _, variable := range *variableList.Data {
+			existing[variable.Key] = variable
+		}
+	}
+
+	sort.Strings(names)
+	var variables []n8n.Variable
+	for _, name := range names {
+		variable, ok := existing[name]
+		if !ok {
+			cmd.Printf("Warning: variable '%s' used by workflow '%s' doesn't exist on the instance\n", name, usedBy[name])
+			continue
+		}
+		variables = append(variables, n8n.Variable{Key: variable.Key, Value: variable.Value, Type: variable.Type})
+	}
+
+	return variables, nil
+}
@@ -0,0 +1,209 @@
+/*
+Copyright © 2025 Eden Reich
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package workflows
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	rootcmd "github.com/edenreich/n8n-cli/cmd"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// FmtCmd represents the fmt command
+var FmtCmd = &cobra.Command{
+	Use:   "fmt [FILE...]",
+	Short: "Rewrite workflow files in canonical form",
+	Long: `Fmt rewrites the workflow files in a directory in canonical form: nodes are sorted by name, keys are
+sorted, float32 artifacts like 1.100000023841858 are normalized and volatile fields like static data are
+removed. Files that are already formatted are left alone. Workflow files are read as they are, without
+overlays, substitution or inlining code files.
+
+With --check, nothing is written and the command fails when a file is not formatted, for use in CI.
+
+Examples:
+
+  # Format every workflow file in the directory
+  n8n workflows fmt --directory workflows/
+
+  # Fail when a workflow file is not formatted
+  n8n workflows fmt --directory workflows/ --check`,
+	Args:        cobra.ArbitraryArgs,
+	Annotations: map[string]string{rootcmd.OfflineAnnotation: "true"},
+	RunE:        formatWorkflows,
+}
+
+func init() {
+	FmtCmd.Flags().StringP("directory", "d", "", "Directory containing workflow files (JSON/YAML) (required)")
+	FmtCmd.Flags().Bool("check", false, "Don't write anything, fail when a workflow file is not formatted")
+	FmtCmd.Flags().Bool("no-truncate", false, "Include all fields in the workflow files, including null and optional fields")
+	addSelectorFlags(FmtCmd)
+	rootcmd.GetWorkflowsCmd().AddCommand(FmtCmd)
+
+	// nolint:errcheck
+	FmtCmd.MarkFlagRequired("directory")
+}
+
+// canonicalOutput reports whether workflow files are written in canonical form, with --canonical or
+// canonical in the config file
+func canonicalOutput(cmd *cobra.Command) bool {
+	if canonical, _ := cmd.Flags().GetBool("canonical"); canonical {
+		return true
+	}
+	return viper.GetBool("canonical")
+}
+
+// formatWorkflows is the handler for the fmt command
+func formatWorkflows(cmd *cobra.Command, args []string) error {
+	directory, _ := cmd.Flags().GetString("directory")
+	check, _ := cmd.Flags().GetBool("check")
+	noTruncate, _ := cmd.Flags().GetBool("no-truncate")
+
+	if directory == "" {
+		return fmt.Errorf("directory is required")
+	}
+
+	unformatted, err := FormatWorkflowFiles(cmd, directory, check, !noTruncate)
+	if err != nil {
+		return err
+	}
+
+	if check && len(unformatted) > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d workflow file(s) not formatted, run 'n8n workflows fmt' to format them", len(unformatted))
+	}
+
+	return nil
+}
+
+// FormatWorkflowFiles rewrites the selected workflow files of a directory in canonical form and returns the
+// paths of the files that were not formatted. With check, nothing is written.
+func FormatWorkflowFiles(cmd *cobra.Command, directory string, check bool, minimal bool) ([]string, error) {
+	selector, err := NewWorkflowSelector(cmd, directory)
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := workflowFilePaths(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	var unformatted []string
+	for _, filePath := range paths {
+		formatted, err := formatWorkflowFile(filePath, minimal)
+		if err != nil {
+			return nil, workflowFileError("error formatting workflow file", filePath, err)
+		}
+		if !selector.Matches(formatted.workflow, filePath) || !formatted.changed {
+			continue
+		}
+
+		unformatted = append(unformatted, filePath)
+		if check {
+			cmd.Printf("Not formatted: %s\n", filePath)
+			continue
+		}
+
+		if err := formatted.write(); err != nil {
+			return nil, fmt.Errorf("error writing workflow file %s: %w", filePath, err)
+		}
+		cmd.Printf("Formatted %s\n", filePath)
+	}
+
+	if len(unformatted) == 0 {
+		cmd.Println("All workflow files are formatted")
+	}
+
+	return unformatted, nil
+}
+
+// formattedWorkflow is a workflow file brought into canonical form
+type formattedWorkflow struct {
+	workflow n8n.Workflow
+	changed  bool
+	write    func() error
+}
+
+// formatWorkflowFile brings a workflow file or directory into canonical form. Code file references are kept.
+func formatWorkflowFile(filePath string, minimal bool) (formattedWorkflow, error) {
+	if isWorkflowDirectory(filePath) {
+		files, err := readWorkflowDirectory(filePath)
+		if err != nil {
+			return formattedWorkflow{}, err
+		}
+
+		workflow, err := n8n.NewWorkflowDecoder().DecodeFromDirectory(files)
+		if err != nil {
+			inWorkflowFile(err, filePath)
+			return formattedWorkflow{}, err
+		}
+
+		formatted, err := serializeWorkflowDirectory(n8n.CanonicalWorkflow(workflow), minimal)
+		if err != nil {
+			return formattedWorkflow{}, err
+		}
+		formatted[n8n.DirectoryWorkflowFile] = withSchemaHeader(formatted[n8n.DirectoryWorkflowFile],
+			readSchemaHeader(files[n8n.DirectoryWorkflowFile]))
+
+		return formattedWorkflow{
+			workflow: workflow,
+			changed:  workflowDirectoryChanged(filePath, formatted),
+			write:    func() error { return writeWorkflowDirectory(filePath, formatted) },
+		}, nil
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return formattedWorkflow{}, fmt.Errorf("error reading file: %w", err)
+	}
+
+	decoder := n8n.NewWorkflowDecoder()
+	var workflow n8n.Workflow
+	if workflowFormat(filePath) == "json" {
+		workflow, err = decoder.DecodeFromJSON(content)
+	} else {
+		workflow, err = decoder.DecodeFromYAML(content)
+	}
+	if err != nil {
+		inWorkflowFile(err, filePath)
+		return formattedWorkflow{}, err
+	}
+
+	formatted, _, err := serializeWorkflow(n8n.CanonicalWorkflow(workflow), filePath, minimal, false)
+	if err != nil {
+		return formattedWorkflow{}, err
+	}
+	if workflowFormat(filePath) == "yaml" {
+		formatted = withSchemaHeader(formatted, readSchemaHeader(content))
+	}
+
+	// A trailing newline added by an editor doesn't count as a difference
+	return formattedWorkflow{
+		workflow: workflow,
+		changed:  !bytes.Equal(bytes.TrimSpace(content), bytes.TrimSpace(formatted)),
+		write:    func() error { return os.WriteFile(filePath, formatted, 0644) },
+	}, nil
+}
@@ -0,0 +1,406 @@
+/*
+Copyright © 2025 Eden Reich
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package workflows
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	rootcmd "github.com/edenreich/n8n-cli/cmd"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// ImportCmd represents the import command
+var ImportCmd = &cobra.Command{
+	Use:   "import [FILE|DIRECTORY]...",
+	Short: "Import workflows exported from n8n into the workflows directory or an archive into n8n",
+	Long: `Import converts workflows exported from n8n into workflow files in the directory. It reads workflows
+downloaded from the n8n editor, files written by 'n8n export:workflow' with or without --separate or --backup,
+and arrays of workflows. Directories are read for their .json files.
+
+Fields like pinData and meta are kept, read-only fields like versionId are dropped. Workflows keep their IDs, so
+syncing to the instance they were exported from updates them, and syncing to another instance creates them.
+Importing a workflow that already has a file in the directory updates that file.
+
+With --archive, the workflows of an archive written by 'n8n workflows export' are imported into the n8n
+instance instead. The archive is checked against the checksums of its manifest, credentials are looked up by
+type and name, missing tags and variables are created and existing variables are kept. Workflows get new IDs
+on the instance and the references between them are rewritten. Workflows with the same name as an archived
+workflow are updated, unless --match-by id is set. New workflows are not activated and updated workflows keep
+their activation, so they can be checked before they go live.
+
+Examples:
+
+  # Import a workflow downloaded from the editor
+  n8n workflows import ~/Downloads/Contact_Form.json --directory workflows/
+
+  # Import a backup made with 'n8n export:workflow --backup --output backups/' as YAML files
+  n8n workflows import backups/ --directory workflows/ --output yaml
+
+  # Import an archive into the n8n instance
+  n8n workflows import --archive billing.tar.gz`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if archive, _ := cmd.Flags().GetString("archive"); archive != "" {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
+	Annotations: map[string]string{rootcmd.OfflineAnnotation: "true"},
+	RunE:        importWorkflows,
+}
+
+func init() {
+	ImportCmd.Flags().StringP("directory", "d", "", "Directory to write the workflow files to (required unless --archive is set)")
+	ImportCmd.Flags().StringP("output", "o", "", "Output format for new workflow files (json, yaml or dir). If not specified, keeps the format of an existing file or uses json")
+	ImportCmd.Flags().Bool("dry-run", false, "Show what would be imported without making changes")
+	ImportCmd.Flags().Bool("no-truncate", false, "Include all fields in the workflow files, including null and optional fields")
+	ImportCmd.Flags().Bool("schema", false, "Write the JSON Schema of workflow files to .n8n/ and reference it from YAML workflow files")
+	ImportCmd.Flags().String("archive", "", "Import the archive written by 'n8n workflows export' into the n8n instance")
+	ImportCmd.Flags().String("match-by", MatchByName, "How archived workflows are matched to workflows in n8n (name, key or id to always create them)")
+	ImportCmd.Flags().String("credentials-map", "", "File mapping credential types and names to IDs on the target instance")
+	rootcmd.GetWorkflowsCmd().AddCommand(ImportCmd)
+}
+
+// importWorkflows is the handler for the import command
+func importWorkflows(cmd *cobra.Command, args []string) error {
+	directory, _ := cmd.Flags().GetString("directory")
+	output, _ := cmd.Flags().GetString("output")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	noTruncate, _ := cmd.Flags().GetBool("no-truncate")
+	archivePath, _ := cmd.Flags().GetString("archive")
+
+	if archivePath != "" {
+		if viper.GetString("api_key") == "" {
+			return fmt.Errorf("API key is required. Set it using the --api-key flag or N8N_API_KEY environment variable")
+		}
+		_, err := ImportArchive(cmd, newClient(cmd), archivePath, dryRun)
+		return err
+	}
+
+	if directory == "" {
+		return fmt.Errorf("directory is required")
+	}
+
+	_, err := ImportWorkflows(cmd, args, directory, output, dryRun, !noTruncate)
+	return err
+}
+
+// ImportWorkflows writes the workflows exported from n8n in the given files and directories to workflow
+// files in the directory and returns the number of imported workflows
+func ImportWorkflows(cmd *cobra.Command, sources []string, directory string, output string, dryRun bool, minimal bool) (int, error) {
+	var workflows []n8n.Workflow
+	for _, source := range sources {
+		exported, err := readExportedWorkflows(source)
+		if err != nil {
+			return 0, err
+		}
+		workflows = append(workflows, exported...)
+	}
+
+	if err := ensureDirectoryExists(cmd, directory, dryRun); err != nil {
+		return 0, err
+	}
+	if err := ensureWorkflowSchema(cmd, directory, dryRun); err != nil {
+		return 0, err
+	}
+
+	localFiles, err := extractLocalWorkflows(directory)
+	if err != nil {
+		return 0, err
+	}
+
+	owners := make(map[string]string, len(localFiles))
+	for workflowID, filePath := range localFiles {
+		owners[filePath] = workflowID
+	}
+
+	imported := 0
+	for _, workflow := range workflows {
+		if workflow.Id == nil || *workflow.Id == "" {
+			cmd.Printf("Skipping workflow '%s' with no ID\n", workflow.Name)
+			continue
+		}
+
+		// Workflows with the same name would be written to the same file
+		filePath, _ := determineFilePathAndAction(workflow, localFiles, directory, output, false)
+		if owner, ok := owners[filePath]; ok && owner != *workflow.Id {
+			cmd.Printf("Skipping workflow '%s' (ID: %s): %s already holds workflow %s\n", workflow.Name, *workflow.Id, filePath, owner)
+			continue
+		}
+
+		if err := processWorkflow(cmd, workflow, localFiles, directory, dryRun, false, output, minimal); err != nil {
+			return imported, err
+		}
+
+		localFiles[*workflow.Id] = filePath
+		owners[filePath] = *workflow.Id
+		imported++
+	}
+
+	cmd.Printf("Imported %d of %d workflow(s) into %s\n", imported, len(workflows), directory)
+	return imported, nil
+}
+
+// readExportedWorkflows decodes the workflows of an exported file, or of the JSON files in a directory
+func readExportedWorkflows(source string) ([]n8n.Workflow, error) {
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, fmt.Errorf("error accessing %s: %w", source, err)
+	}
+
+	filePaths := []string{source}
+	if info.IsDir() {
+		entries, err := os.ReadDir(source)
+		if err != nil {
+			return nil, fmt.Errorf("error reading directory %s: %w", source, err)
+		}
+
+		filePaths = nil
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.EqualFold(filepath.Ext(entry.Name()), ".json") {
+				filePaths = append(filePaths, filepath.Join(source, entry.Name()))
+			}
+		}
+	}
+
+	var workflows []n8n.Workflow
+	for _, filePath := range filePaths {
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading file: %w", err)
+		}
+
+		exported, err := n8n.NewWorkflowDecoder().DecodeExport(content)
+		if err != nil {
+			inWorkflowFile(err, filePath)
+			return nil, workflowFileError("error parsing exported workflows in", filePath, err)
+		}
+		workflows = append(workflows, exported...)
+	}
+
+	return workflows, nil
+}
+
+// ImportArchive imports the workflows of an archive into the instance, after creating the tags and variables
+// they use. Nothing is changed when the archive fails verification or credentials can't be resolved.
+func ImportArchive(cmd *cobra.Command, client n8n.ClientInterface, archivePath string, dryRun bool) ([]WorkflowResult, error) {
+	archive, err := ReadArchive(archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd.Printf("Verified %s: %d workflow(s), %d tag(s), %d variable(s) and %d credential(s)\n",
+		archivePath, len(archive.Workflows), len(archive.Tags), len(archive.Variables), len(archive.Credentials))
+
+	localWorkflows := make([]LocalWorkflow, len(archive.Workflows))
+	for i, workflow := range archive.Workflows {
+		localWorkflows[i] = LocalWorkflow{Workflow: workflow, FilePath: archive.Manifest.Workflows[i].File}
+	}
+
+	if err := resolveArchiveCredentials(client, cmd, localWorkflows); err != nil {
+		return nil, err
+	}
+
+	localWorkflows, err = SortWorkflowsByDependencies(client, localWorkflows)
+	if err != nil {
+		return nil, err
+	}
+
+	tagIDs, err := importArchiveTags(client, cmd, archive.Tags, dryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := importArchiveVariables(client, cmd, archive.Variables, dryRun); err != nil {
+		return nil, err
+	}
+
+	// The IDs of the source instance mean nothing on the target instance, workflows are matched by name instead
+	sourceIDs := make(map[string]string)
+	for i := range localWorkflows {
+		workflow := &localWorkflows[i].Workflow
+		if workflow.Id != nil {
+			sourceIDs[localWorkflows[i].FilePath] = *workflow.Id
+		}
+		workflow.Id = nil
+		workflow.Active = nil
+		remapTagIDs(workflow, tagIDs)
+	}
+
+	matches, err := MatchRemoteWorkflows(client, cmd, localWorkflows)
+	if err != nil {
+		return nil, err
+	}
+
+	targetIDs := make(map[string]string)
+	var results []WorkflowResult
+	for i := range localWorkflows {
+		local := &localWorkflows[i]
+		RewriteWorkflowReferences(&local.Workflow, targetIDs)
+
+		if remoteID, matched := matches[local.FilePath]; matched {
+			local.Workflow.Id = &remoteID
+		}
+
+		result, err := ProcessWorkflow(client, cmd, &local.Workflow, local.FilePath, dryRun, nil)
+		if err != nil {
+			return results, fmt.Errorf("error importing workflow '%s': %w", local.Workflow.Name, err)
+		}
+		results = append(results, result)
+
+		if sourceID := sourceIDs[local.FilePath]; sourceID != "" && result.WorkflowID != "" {
+			targetIDs[sourceID] = result.WorkflowID
+		}
+	}
+
+	cmd.Printf("Imported %d workflow(s) from %s\n", len(results), archivePath)
+	return results, nil
+}
+
+// resolveArchiveCredentials rewrites the credential IDs of archived workflows to the IDs of the credentials
+// with the same type and name on the instance, or in the file passed with --credentials-map
+func resolveArchiveCredentials(client n8n.ClientInterface, cmd *cobra.Command, localWorkflows []LocalWorkflow) error {
+	resolver := NewCredentialResolver()
+	if err := resolver.LoadFromInstance(client); err != nil {
+		return err
+	}
+
+	if mapFile, _ := cmd.Flags().GetString("credentials-map"); mapFile != "" {
+		if err := resolver.LoadMappingFile(mapFile); err != nil {
+			return err
+		}
+	}
+
+	var unresolved []UnresolvedCredential
+	for i := range localWorkflows {
+		unresolved = append(unresolved, resolver.Resolve(&localWorkflows[i].Workflow)...)
+	}
+
+	if len(unresolved) > 0 {
+		return FormatUnresolvedCredentials(unresolved)
+	}
+
+	return nil
+}
+
+// importArchiveTags creates the archived tags that don't exist on the instance and returns the IDs of the
+// tags on the instance by their archived IDs
+func importArchiveTags(client n8n.ClientInterface, cmd *cobra.Command, tags []n8n.Tag, dryRun bool) (map[string]string, error) {
+	tagIDs := make(map[string]string)
+	if len(tags) == 0 {
+		return tagIDs, nil
+	}
+
+	existingTags, err := getExistingTagsMap(client)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tag := range tags {
+		targetID, exists := existingTags[tag.Name]
+		if !exists {
+			dryRunMsg := fmt.Sprintf("Would create tag '%s'", tag.Name)
+			err := ExecuteOrDryRun(cmd, dryRun, dryRunMsg, func() (string, error) {
+				created, err := client.CreateTag(tag.Name)
+				if err != nil {
+					return "", fmt.Errorf("error creating tag '%s': %w", tag.Name, err)
+				}
+				if created.Id != nil {
+					targetID = *created.Id
+				}
+				return fmt.Sprintf("Created tag '%s' (ID: %s)", tag.Name, targetID), nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if tag.Id != nil && targetID != "" {
+			tagIDs[*tag.Id] = targetID
+		}
+	}
+
+	return tagIDs, nil
+}
+
+// remapTagIDs replaces the archived tag IDs of a workflow with the IDs on the instance. Tags without a known
+// ID are looked up by name when the workflow is imported.
+func remapTagIDs(workflow *n8n.Workflow, tagIDs map[string]string) {
+	if workflow.Tags == nil {
+		return
+	}
+
+	tags := make([]n8n.Tag, len(*workflow.Tags))
+	for i, tag := range *workflow.Tags {
+		tags[i] = n8n.Tag{Name: tag.Name}
+		if tag.Id != nil {
+			if targetID, ok := tagIDs[*tag.Id]; ok {
+				tags[i].Id = &targetID
+			}
+		}
+	}
+	workflow.Tags = &tags
+}
+
+// importArchiveVariables creates the archived variables that don't exist on the instance. Existing variables
+// keep their values, they usually differ between instances on purpose.
+func importArchiveVariables(client n8n.ClientInterface, cmd *cobra.Command, variables []n8n.Variable, dryRun bool) error {
+	if len(variables) == 0 {
+		return nil
+	}
+
+	variableList, err := client.GetVariables()
+	if err != nil {
+		return fmt.Errorf("error fetching variables from n8n: %w", err)
+	}
+
+	existing := make(map[string]bool)
+	if variableList != nil && variableList.Data != nil {
+		for _, variable := range *variableList.Data {
+			existing[variable.Key] = true
+		}
+	}
+
+	for _, variable := range variables {
+		if existing[variable.Key] {
+			cmd.Printf("Keeping existing variable '%s'\n", variable.Key)
+			continue
+		}
+
+		dryRunMsg := fmt.Sprintf("Would create variable '%s'", variable.Key)
+		err := ExecuteOrDryRun(cmd, dryRun, dryRunMsg, func() (string, error) {
+			if err := client.CreateVariable(variable); err != nil {
+				return "", fmt.Errorf("error creating variable '%s': %w", variable.Key, err)
+			}
+			return fmt.Sprintf("Created variable '%s'", variable.Key), nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
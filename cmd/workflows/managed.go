@@ -0,0 +1,121 @@
+/*
+Copyright © 2025 Eden Reich
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package workflows
+
+import (
+	"fmt"
+
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// defaultManagedTag is the tag that marks workflows managed by the CLI when managed_tag isn't configured
+const defaultManagedTag = "n8n-cli"
+
+// ManagedTag returns the tag that marks workflows managed by the CLI, configurable with managed_tag in the config file
+func ManagedTag() string {
+	if tag := viper.GetString("managed_tag"); tag != "" {
+		return tag
+	}
+	return defaultManagedTag
+}
+
+// IsManaged reports whether a workflow carries the managed tag
+func IsManaged(workflow n8n.Workflow) bool {
+	return hasAnyTag(workflow, []string{ManagedTag()})
+}
+
+// includeUnmanaged reports whether the command should also operate on workflows that are not managed by the CLI
+func includeUnmanaged(cmd *cobra.Command) bool {
+	include, _ := cmd.Flags().GetBool("include-unmanaged")
+	return include
+}
+
+// markManaged adds the managed tag to the tags declared in a workflow file, so sync keeps it when it replaces the tags.
+// Workflows that don't declare tags are left untouched, the tag is added to them in n8n with ensureManagedTag.
+func markManaged(workflow *n8n.Workflow) {
+	if workflow.Tags == nil || len(*workflow.Tags) == 0 || IsManaged(*workflow) {
+		return
+	}
+
+	tags := append(*workflow.Tags, n8n.Tag{Name: ManagedTag()})
+	workflow.Tags = &tags
+}
+
+// ensureManagedTag adds the managed tag to a workflow in n8n, keeping the tags it already has
+func ensureManagedTag(client n8n.ClientInterface, cmd *cobra.Command, workflowID string, workflowName string) error {
+	tags, err := client.GetWorkflowTags(workflowID)
+	if err != nil {
+		return fmt.Errorf("error fetching tags of workflow %s (%s): %w", workflowName, workflowID, err)
+	}
+
+	managedTag := ManagedTag()
+	for _, tag := range tags {
+		if tag.Name == managedTag {
+			return nil
+		}
+	}
+
+	existingTags, err := getExistingTagsMap(client)
+	if err != nil {
+		return fmt.Errorf("error fetching existing tags: %w", err)
+	}
+
+	if err := addWorkflowTag(client, workflowID, tags, managedTag, existingTags); err != nil {
+		return fmt.Errorf("error marking workflow %s (%s) as managed: %w", workflowName, workflowID, err)
+	}
+
+	cmd.Printf("Marked workflow '%s' (ID: %s) as managed with tag '%s'\n", workflowName, workflowID, managedTag)
+	return nil
+}
+
+// addWorkflowTag adds a tag to the current tags of a workflow, creating the tag if it doesn't exist yet
+func addWorkflowTag(client n8n.ClientInterface, workflowID string, currentTags n8n.WorkflowTags, tagName string, existingTags map[string]string) error {
+	tagID, exists := existingTags[tagName]
+	if !exists {
+		tag, err := client.CreateTag(tagName)
+		if err != nil {
+			return fmt.Errorf("error creating tag '%s': %w", tagName, err)
+		}
+		if tag == nil || tag.Id == nil {
+			return fmt.Errorf("error creating tag '%s': no ID returned", tagName)
+		}
+		tagID = *tag.Id
+		existingTags[tagName] = tagID
+	}
+
+	tagIDs := n8n.TagIds{{Id: tagID}}
+	for _, tag := range currentTags {
+		if tag.Id != nil && *tag.Id != tagID {
+			tagIDs = append(tagIDs, struct {
+				Id string `json:"id"`
+			}{Id: *tag.Id})
+		}
+	}
+
+	if _, err := client.UpdateWorkflowTags(workflowID, tagIDs); err != nil {
+		return fmt.Errorf("error tagging workflow: %w", err)
+	}
+
+	return nil
+}
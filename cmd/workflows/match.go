@@ -0,0 +1,131 @@
+/*
+Copyright © 2025 Eden Reich
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package workflows
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	rootcmd "github.com/edenreich/n8n-cli/cmd"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/spf13/cobra"
+)
+
+// Strategies matching local workflow files to remote workflows, set with --match-by
+const (
+	// MatchByID only matches by the ID in the file, files without a known ID create new workflows
+	MatchByID = "id"
+	// MatchByName matches files without a known ID to the remote workflow with the same name
+	MatchByName = "name"
+	// MatchByKey matches files without a known ID to the remote workflow whose file name is the same
+	MatchByKey = "key"
+)
+
+// MatchRemoteWorkflows finds the remote workflow every local workflow corresponds to when it has no ID, or an
+// ID that doesn't exist on the instance, using the --match-by strategy. It returns the matched remote IDs by
+// file path. Ambiguous matches fail the whole run before anything is uploaded, since guessing would update
+// the wrong workflow.
+func MatchRemoteWorkflows(client n8n.ClientInterface, cmd *cobra.Command, localWorkflows []LocalWorkflow) (map[string]string, error) {
+	matchBy, _ := cmd.Flags().GetString("match-by")
+
+	switch matchBy {
+	case "", MatchByID:
+		return nil, nil
+	case MatchByName, MatchByKey:
+	default:
+		return nil, fmt.Errorf("invalid --match-by '%s', use one of: %s, %s, %s", matchBy, MatchByID, MatchByName, MatchByKey)
+	}
+
+	workflowList, err := client.GetAllWorkflows()
+	if err != nil {
+		return nil, fmt.Errorf("error fetching workflows to match by %s: %w", matchBy, err)
+	}
+
+	var remoteWorkflows []n8n.Workflow
+	if workflowList != nil && workflowList.Data != nil {
+		remoteWorkflows = *workflowList.Data
+	}
+
+	remoteNames := make(map[string]string)
+	for _, remote := range remoteWorkflows {
+		if remote.Id != nil {
+			remoteNames[*remote.Id] = remote.Name
+		}
+	}
+
+	// Files with an ID that exists on the instance keep it, other files can't be matched to the same workflow
+	claimedBy := make(map[string]string)
+	for _, local := range localWorkflows {
+		if local.Workflow.Id == nil {
+			continue
+		}
+		if _, exists := remoteNames[*local.Workflow.Id]; exists {
+			claimedBy[*local.Workflow.Id] = local.FilePath
+		}
+	}
+
+	matches := make(map[string]string)
+	var problems []string
+	for _, local := range localWorkflows {
+		if local.Workflow.Id != nil {
+			if _, exists := remoteNames[*local.Workflow.Id]; exists {
+				continue
+			}
+		}
+
+		var remoteID string
+		if matchBy == MatchByName {
+			remoteID, err = rootcmd.FindWorkflow(local.Workflow.Name, remoteWorkflows)
+		} else {
+			remoteID, err = rootcmd.FindWorkflowByFilename(local.FilePath, remoteWorkflows)
+		}
+
+		var ambiguous *rootcmd.AmbiguousWorkflowError
+		if errors.As(err, &ambiguous) {
+			problems = append(problems, fmt.Sprintf("%s: %d workflows in n8n match by %s '%s' (IDs: %s)",
+				filepath.Base(local.FilePath), len(ambiguous.IDs), matchBy, ambiguous.Name, strings.Join(ambiguous.IDs, ", ")))
+			continue
+		}
+		if err != nil {
+			continue
+		}
+
+		if other, claimed := claimedBy[remoteID]; claimed {
+			problems = append(problems, fmt.Sprintf("%s and %s both match workflow '%s' (ID: %s)",
+				filepath.Base(other), filepath.Base(local.FilePath), remoteNames[remoteID], remoteID))
+			continue
+		}
+
+		claimedBy[remoteID] = local.FilePath
+		matches[local.FilePath] = remoteID
+		cmd.Printf("Matched %s to workflow '%s' (ID: %s) by %s\n", filepath.Base(local.FilePath), remoteNames[remoteID], remoteID, matchBy)
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("ambiguous workflow matches, nothing was synced. Set the workflow IDs in the files or remove the duplicates with 'n8n workflows dedupe':\n  - %s",
+			strings.Join(problems, "\n  - "))
+	}
+
+	return matches, nil
+}
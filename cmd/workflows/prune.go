@@ -0,0 +1,280 @@
+/*
+Copyright © 2025 Eden Reich
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package workflows
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+)
+
+// PruneWorkflows removes workflows from n8n that are not in the local workflow files.
+// Only workflows managed by the CLI (see ManagedTag) and matching the --prune-tag, --prune-project and
+// --prune-pattern scopes are considered, workflows listed under prune.protected in the config file are
+// never touched, and with --archive workflows are deactivated and tagged instead of deleted.
+func PruneWorkflows(client n8n.ClientInterface, cmd *cobra.Command, localWorkflowIDs map[string]bool) error {
+	limit := n8n.MaxLimit
+	workflowList, err := client.GetWorkflows(&limit)
+	if err != nil {
+		return fmt.Errorf("error getting workflows from n8n: %w", err)
+	}
+
+	if workflowList == nil || workflowList.Data == nil {
+		return fmt.Errorf("no workflows found in n8n instance")
+	}
+
+	dryRun := false
+	if cmd.Flags().Changed("dry-run") {
+		dryRun, _ = cmd.Flags().GetBool("dry-run")
+	}
+	archive, _ := cmd.Flags().GetBool("archive")
+	archiveTag, _ := cmd.Flags().GetString("archive-tag")
+	maxDeletions, _ := cmd.Flags().GetInt("max-deletions")
+	yes, _ := cmd.Flags().GetBool("yes")
+
+	if archiveTag == "" {
+		archiveTag = "archived"
+	}
+
+	candidates, err := selectPruneCandidates(cmd, *workflowList.Data, localWorkflowIDs, archive, archiveTag)
+	if err != nil {
+		return err
+	}
+
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	if maxDeletions > 0 && len(candidates) > maxDeletions {
+		return fmt.Errorf("refusing to prune %d workflows, more than the %d allowed by --max-deletions", len(candidates), maxDeletions)
+	}
+
+	if !dryRun && !yes && isInteractive(cmd) {
+		confirmed, err := confirmPrune(cmd, candidates, archive)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			cmd.Println("Prune cancelled, no workflows were removed")
+			return nil
+		}
+	}
+
+	var existingTags map[string]string
+	for _, workflow := range candidates {
+		workflowID := *workflow.Id
+		workflowName := workflow.Name
+
+		if !archive {
+			dryRunMsg := fmt.Sprintf("Would delete workflow '%s' (ID: %s) that was not in local files", workflowName, workflowID)
+			err := ExecuteOrDryRun(cmd, dryRun, dryRunMsg, func() (string, error) {
+				if err := client.DeleteWorkflow(workflowID); err != nil {
+					return "", fmt.Errorf("error deleting workflow %s (%s): %w", workflowName, workflowID, err)
+				}
+				return fmt.Sprintf("Deleted workflow '%s' (ID: %s) that was not in local files", workflowName, workflowID), nil
+			})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		dryRunMsg := fmt.Sprintf("Would archive workflow '%s' (ID: %s) that was not in local files", workflowName, workflowID)
+		err := ExecuteOrDryRun(cmd, dryRun, dryRunMsg, func() (string, error) {
+			if existingTags == nil {
+				existingTags, err = getExistingTagsMap(client)
+				if err != nil {
+					return "", fmt.Errorf("error fetching existing tags: %w", err)
+				}
+			}
+
+			if err := archiveWorkflow(client, workflow, archiveTag, existingTags); err != nil {
+				return "", fmt.Errorf("error archiving workflow %s (%s): %w", workflowName, workflowID, err)
+			}
+			return fmt.Sprintf("Archived workflow '%s' (ID: %s) that was not in local files", workflowName, workflowID), nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// selectPruneCandidates returns the managed remote workflows that are not in the local files, match the prune
+// scopes and are not protected
+func selectPruneCandidates(cmd *cobra.Command, workflows []n8n.Workflow, localWorkflowIDs map[string]bool, archive bool, archiveTag string) ([]n8n.Workflow, error) {
+	tags, _ := cmd.Flags().GetStringSlice("prune-tag")
+	project, _ := cmd.Flags().GetString("prune-project")
+	pattern, _ := cmd.Flags().GetString("prune-pattern")
+	protected := viper.GetStringSlice("prune.protected")
+	unmanaged := includeUnmanaged(cmd)
+
+	if pattern != "" {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid --prune-pattern '%s': %w", pattern, err)
+		}
+	}
+
+	var candidates []n8n.Workflow
+	for _, workflow := range workflows {
+		if workflow.Id == nil || *workflow.Id == "" || localWorkflowIDs[*workflow.Id] {
+			continue
+		}
+
+		if !unmanaged && !IsManaged(workflow) {
+			continue
+		}
+		if len(tags) > 0 && !hasAnyTag(workflow, tags) {
+			continue
+		}
+		if project != "" && !inProject(workflow, project) {
+			continue
+		}
+		if pattern != "" {
+			if matched, _ := path.Match(pattern, workflow.Name); !matched {
+				continue
+			}
+		}
+		if archive && hasAnyTag(workflow, []string{archiveTag}) {
+			continue
+		}
+
+		if isProtected(workflow, protected) {
+			cmd.Printf("Skipping protected workflow '%s' (ID: %s)\n", workflow.Name, *workflow.Id)
+			continue
+		}
+
+		candidates = append(candidates, workflow)
+	}
+
+	return candidates, nil
+}
+
+// hasAnyTag reports whether a workflow carries at least one of the given tags
+func hasAnyTag(workflow n8n.Workflow, names []string) bool {
+	if workflow.Tags == nil {
+		return false
+	}
+
+	for _, tag := range *workflow.Tags {
+		for _, name := range names {
+			if tag.Name == name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// inProject reports whether a workflow is shared with a project, matched by ID or name
+func inProject(workflow n8n.Workflow, project string) bool {
+	if workflow.Shared == nil {
+		return false
+	}
+
+	for _, shared := range *workflow.Shared {
+		if shared.ProjectId != nil && *shared.ProjectId == project {
+			return true
+		}
+		if shared.Project != nil {
+			if shared.Project.Id != nil && *shared.Project.Id == project {
+				return true
+			}
+			if shared.Project.Name != nil && *shared.Project.Name == project {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isProtected reports whether a workflow matches one of the protected IDs or name patterns from the config file
+func isProtected(workflow n8n.Workflow, protected []string) bool {
+	for _, entry := range protected {
+		if workflow.Id != nil && *workflow.Id == entry {
+			return true
+		}
+		if matched, _ := path.Match(entry, workflow.Name); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isInteractive reports whether the command can ask the user for confirmation
+func isInteractive(cmd *cobra.Command) bool {
+	file, ok := cmd.InOrStdin().(*os.File)
+	if !ok {
+		return true
+	}
+
+	return term.IsTerminal(int(file.Fd()))
+}
+
+// confirmPrune lists the workflows that are about to be removed and asks the user to confirm
+func confirmPrune(cmd *cobra.Command, candidates []n8n.Workflow, archive bool) (bool, error) {
+	action := "deleted"
+	if archive {
+		action = "archived"
+	}
+
+	cmd.Printf("The following %d workflow(s) will be %s:\n", len(candidates), action)
+	for _, workflow := range candidates {
+		cmd.Printf("  - %s (ID: %s)\n", workflow.Name, *workflow.Id)
+	}
+	cmd.Print("Continue? [y/N]: ")
+
+	answer, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	if err != nil && answer == "" {
+		return false, nil
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+// archiveWorkflow deactivates a workflow and adds the archive tag to its tags
+func archiveWorkflow(client n8n.ClientInterface, workflow n8n.Workflow, archiveTag string, existingTags map[string]string) error {
+	if workflow.Active != nil && *workflow.Active {
+		if _, err := client.DeactivateWorkflow(*workflow.Id); err != nil {
+			return fmt.Errorf("error deactivating workflow: %w", err)
+		}
+	}
+
+	var currentTags n8n.WorkflowTags
+	if workflow.Tags != nil {
+		currentTags = *workflow.Tags
+	}
+
+	return addWorkflowTag(client, *workflow.Id, currentTags, archiveTag, existingTags)
+}
@@ -0,0 +1,348 @@
+/*
+Copyright © 2025 Eden Reich
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package workflows
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	rootcmd "github.com/edenreich/n8n-cli/cmd"
+	"github.com/edenreich/n8n-cli/logger"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootcmd.RegisterReconciler(newDaemonReconciler)
+}
+
+// newDaemonReconciler creates the reconciler of the reconcile command, syncing with the configured instance
+func newDaemonReconciler(cmd *cobra.Command, directory string) (rootcmd.Reconciler, error) {
+	gitPull, _ := cmd.Flags().GetBool("git-pull")
+	matchBy, _ := cmd.Flags().GetString("match-by")
+	if gitPull && matchBy == MatchByID {
+		return nil, fmt.Errorf("--git-pull can't be used with --match-by %s, the IDs of created workflows aren't written back into the checkout", MatchByID)
+	}
+
+	return NewReconciler(cmd, newClient(cmd), directory), nil
+}
+
+// ReconcileWorkflowResult is the result of the last reconcile run for a single workflow
+type ReconcileWorkflowResult struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name"`
+	File string `json:"file,omitempty"`
+	// Drift is the kind of drift found before syncing, see DriftInSync and the other kinds
+	Drift string `json:"drift"`
+	// Action is what the run did to the workflow: none, created or updated
+	Action string `json:"action"`
+}
+
+// ReconcileStatus is the state of the reconcile daemon served by the status endpoint
+type ReconcileStatus struct {
+	LastRun             *time.Time                `json:"lastRun,omitempty"`
+	NextRun             *time.Time                `json:"nextRun,omitempty"`
+	Duration            string                    `json:"duration,omitempty"`
+	Success             bool                      `json:"success"`
+	Error               string                    `json:"error,omitempty"`
+	ConsecutiveFailures int                       `json:"consecutiveFailures"`
+	Revision            string                    `json:"revision,omitempty"`
+	Workflows           []ReconcileWorkflowResult `json:"workflows"`
+}
+
+// Reconciler syncs the workflows of a directory that drifted from the instance and keeps the result of the last run
+type Reconciler struct {
+	cmd       *cobra.Command
+	syncCmd   *cobra.Command
+	client    n8n.ClientInterface
+	directory string
+
+	mu     sync.RWMutex
+	status ReconcileStatus
+}
+
+var _ rootcmd.Reconciler = (*Reconciler)(nil)
+
+// NewReconciler creates a reconciler for a directory, configured from the flags of the command
+func NewReconciler(cmd *cobra.Command, client n8n.ClientInterface, directory string) *Reconciler {
+	return &Reconciler{
+		cmd:       cmd,
+		syncCmd:   newReconcileSyncCmd(cmd),
+		client:    client,
+		directory: directory,
+		status:    ReconcileStatus{Workflows: []ReconcileWorkflowResult{}},
+	}
+}
+
+// newReconcileSyncCmd returns the command the reconciler syncs with. It carries the flags of the reconcile
+// command and sets the other sync options explicitly, instead of relying on the zero values of missing flags.
+// A checkout updated with --git-pull is never written to, so that pulling keeps fast-forwarding.
+func newReconcileSyncCmd(cmd *cobra.Command) *cobra.Command {
+	syncCmd := &cobra.Command{Use: SyncCmd.Use}
+	if gitPull, _ := cmd.Flags().GetBool("git-pull"); gitPull {
+		syncCmd.Flags().Bool("refresh", false, "")
+	}
+
+	defaults := &cobra.Command{}
+	defaults.Flags().Bool("dry-run", false, "")
+	defaults.Flags().Bool("refresh", true, "")
+	defaults.Flags().String("output", "", "")
+	defaults.Flags().Bool("all", false, "")
+	defaults.Flags().Bool("theirs", false, "")
+	defaults.Flags().Bool("merge", false, "")
+	defaults.Flags().String("match-by", MatchByName, "")
+	defaults.Flags().StringSlice("prune-tag", nil, "")
+	defaults.Flags().String("prune-project", "", "")
+	defaults.Flags().String("prune-pattern", "", "")
+	defaults.Flags().Bool("archive", false, "")
+	defaults.Flags().String("archive-tag", "archived", "")
+	addSelectorFlags(defaults)
+
+	syncCmd.SetIn(cmd.InOrStdin())
+	syncCmd.SetErr(cmd.ErrOrStderr())
+	syncCmd.Flags().AddFlagSet(cmd.Flags())
+	syncCmd.Flags().AddFlagSet(defaults.Flags())
+	return syncCmd
+}
+
+// Status returns a copy of the result of the last reconcile run
+func (r *Reconciler) Status() ReconcileStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	status := r.status
+	status.Workflows = append([]ReconcileWorkflowResult{}, r.status.Workflows...)
+	return status
+}
+
+// Run reconciles until the context is cancelled, waiting the interval between runs and backing off while runs fail
+func (r *Reconciler) Run(ctx context.Context, interval time.Duration, maxBackoff time.Duration) {
+	for {
+		// Errors are logged and reported by the status endpoint, the next run retries
+		_ = r.Reconcile(ctx)
+
+		r.mu.Lock()
+		delay := ReconcileDelay(interval, maxBackoff, r.status.ConsecutiveFailures)
+		next := time.Now().Add(delay)
+		r.status.NextRun = &next
+		r.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// ReconcileDelay returns the time to wait before the next run. The interval is doubled for every
+// failed run after the first one, up to maxBackoff.
+func ReconcileDelay(interval time.Duration, maxBackoff time.Duration, failures int) time.Duration {
+	delay := interval
+	for i := 1; i < failures && (maxBackoff <= 0 || delay < maxBackoff); i++ {
+		delay *= 2
+	}
+
+	if maxBackoff > 0 && delay > maxBackoff {
+		return maxBackoff
+	}
+	return delay
+}
+
+// Reconcile runs a single reconcile: it pulls the directory when configured, detects drift and syncs
+// the directory if anything drifted. The result is recorded in the status.
+func (r *Reconciler) Reconcile(ctx context.Context) error {
+	start := time.Now()
+
+	revision, err := r.pull(ctx)
+	var results []ReconcileWorkflowResult
+	if err == nil {
+		results, err = r.apply()
+	}
+
+	r.mu.Lock()
+	r.status.LastRun = &start
+	r.status.Duration = time.Since(start).Round(time.Millisecond).String()
+	r.status.Success = err == nil
+	r.status.Error = ""
+	if revision != "" {
+		r.status.Revision = revision
+	}
+	if results != nil {
+		r.status.Workflows = results
+	}
+	if err != nil {
+		r.status.Error = err.Error()
+		r.status.ConsecutiveFailures++
+	} else {
+		r.status.ConsecutiveFailures = 0
+	}
+	failures := r.status.ConsecutiveFailures
+	r.mu.Unlock()
+
+	if err != nil {
+		logger.Errorw("Reconcile failed", "directory", r.directory, "error", err.Error(), "consecutiveFailures", failures)
+		return err
+	}
+
+	logger.Infow("Reconcile completed", "directory", r.directory, "workflows", len(results), "duration", time.Since(start).String())
+	return nil
+}
+
+// pull updates the directory with git pull when --git-pull is set and returns the checked out revision
+func (r *Reconciler) pull(ctx context.Context) (string, error) {
+	gitPull, _ := r.cmd.Flags().GetBool("git-pull")
+	if !gitPull {
+		return "", nil
+	}
+
+	output, err := exec.CommandContext(ctx, "git", "-C", r.directory, "pull", "--ff-only", "--autostash").CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("error pulling %s: %w: %s", r.directory, err, strings.TrimSpace(string(output)))
+	}
+
+	output, err = exec.CommandContext(ctx, "git", "-C", r.directory, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("error reading the revision of %s: %w", r.directory, err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// apply detects drift and syncs the directory when anything drifted, returning the result for every workflow
+func (r *Reconciler) apply() ([]ReconcileWorkflowResult, error) {
+	report, err := DetectDrift(r.client, r.cmd, r.directory)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []ReconcileWorkflowResult
+	for _, drift := range append(report.InSync, report.Drift...) {
+		results = append(results, ReconcileWorkflowResult{
+			ID:     drift.ID,
+			Name:   drift.Name,
+			File:   drift.FilePath,
+			Drift:  drift.Kind,
+			Action: "none",
+		})
+	}
+
+	prune, _ := r.cmd.Flags().GetBool("prune")
+	if !needsSync(report, prune) {
+		return results, nil
+	}
+
+	output := new(bytes.Buffer)
+	r.syncCmd.SetOut(output)
+
+	synced, err := SyncWorkflowsWithClient(r.syncCmd, r.client, r.directory, false, prune)
+	logger.Debug("Sync output:\n%s", output.String())
+
+	// Created workflows get their ID written into their files, so the next run doesn't create them again
+	refresh, _ := r.syncCmd.Flags().GetBool("refresh")
+	if refresh && !usesRendering(r.syncCmd) {
+		for _, result := range synced {
+			if !result.Created || result.Remote == nil {
+				continue
+			}
+			if writeErr := writeWorkflowID(result.FilePath, *result.Remote, canonicalOutput(r.syncCmd)); writeErr != nil {
+				logger.Warn("Error writing the ID of workflow '%s' to %s: %v", result.Name, result.FilePath, writeErr)
+			}
+		}
+	}
+
+	for _, result := range synced {
+		for i := range results {
+			if results[i].File != result.FilePath {
+				continue
+			}
+
+			results[i].ID = result.WorkflowID
+			switch {
+			case result.Created:
+				results[i].Action = "created"
+			case result.Updated:
+				results[i].Action = "updated"
+			default:
+				continue
+			}
+
+			logger.Infow("Workflow reconciled", "workflow", results[i].Name, "id", results[i].ID, "action", results[i].Action, "drift", results[i].Drift)
+		}
+	}
+
+	return results, err
+}
+
+// needsSync reports whether the drift can be fixed by a sync. Workflows only in n8n are only removed when pruning.
+func needsSync(report *DriftReport, prune bool) bool {
+	for _, drift := range report.Drift {
+		if drift.Kind != DriftOnlyRemote || prune {
+			return true
+		}
+	}
+	return false
+}
+
+// Handler returns the HTTP handler serving the health and status endpoints
+func (r *Reconciler) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, req *http.Request) {
+		status := r.Status()
+
+		response := map[string]interface{}{"status": "ok"}
+		code := http.StatusOK
+		if status.ConsecutiveFailures > 0 {
+			response = map[string]interface{}{
+				"status":              "failing",
+				"error":               status.Error,
+				"consecutiveFailures": status.ConsecutiveFailures,
+			}
+			code = http.StatusServiceUnavailable
+		}
+
+		writeJSON(w, code, response)
+	})
+
+	mux.HandleFunc("/status", func(w http.ResponseWriter, req *http.Request) {
+		writeJSON(w, http.StatusOK, r.Status())
+	})
+
+	return mux
+}
+
+// writeJSON writes a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logger.Warn("Error writing response: %v", err)
+	}
+}
@@ -22,12 +22,17 @@ THE SOFTWARE.
 package workflows
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"path"
 	"path/filepath"
 	"strings"
+	"syscall"
 
 	rootcmd "github.com/edenreich/n8n-cli/cmd"
+	"github.com/edenreich/n8n-cli/logger"
 	"github.com/edenreich/n8n-cli/n8n"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -35,11 +40,18 @@ import (
 
 // refreshCmd represents the refresh command
 var refreshCmd = &cobra.Command{
-	Use:   "refresh",
+	Use:   "refresh [FILE...]",
 	Short: "Refresh the state of workflows in the directory from n8n instance",
 	Long: `Refresh command fetches and updates the state of workflows in the directory from a specified n8n instance.
-By default, only workflows that already exist in the directory will be refreshed. Use the --all flag to refresh all workflows.`,
-	Args: cobra.ExactArgs(0),
+By default, only workflows that already exist in the directory will be refreshed. Use the --all flag to refresh all workflows.
+With --all, workflows that are not managed by the CLI are skipped unless --include-unmanaged is set, use
+'n8n workflows adopt' to bring a workflow built in the n8n UI under management.
+
+With --watch, refresh keeps running and rewrites the files of workflows edited in the n8n UI within
+seconds. Files with local changes that weren't synced yet are never overwritten.
+
+Use --name, --tag, --id, file arguments or --changed-since to only refresh some of the workflows.`,
+	Args: cobra.ArbitraryArgs,
 	RunE: RefreshWorkflows,
 }
 
@@ -47,9 +59,16 @@ func init() {
 	refreshCmd.Flags().StringP("directory", "d", "", "Directory containing workflow files (JSON/YAML) (required)")
 	refreshCmd.Flags().Bool("dry-run", false, "Show what would be updated without making changes")
 	refreshCmd.Flags().Bool("overwrite", false, "Overwrite existing files even if they have a different name")
-	refreshCmd.Flags().StringP("output", "o", "json", "Output format for new workflow files (json or yaml)")
+	refreshCmd.Flags().StringP("output", "o", "json", "Output format for new workflow files (json, yaml or dir)")
 	refreshCmd.Flags().Bool("no-truncate", false, "Include all fields in output files, including null and optional fields")
 	refreshCmd.Flags().Bool("all", false, "Refresh all workflows from n8n instance, not just those in the directory")
+	refreshCmd.Flags().Bool("include-unmanaged", false, "With --all, also refresh workflows that are not managed by the CLI")
+	refreshCmd.Flags().Bool("watch", false, "Keep running and refresh workflow files when the workflows change in n8n")
+	refreshCmd.Flags().Duration("poll-interval", defaultPollInterval, "How often to check n8n for changed workflows in watch mode")
+	refreshCmd.Flags().Bool("canonical", false, "Write workflow files in canonical form, with sorted nodes and without volatile fields")
+	refreshCmd.Flags().Bool("schema", false, "Write the JSON Schema of workflow files to .n8n/ and reference it from YAML workflow files")
+	refreshCmd.Flags().Bool("extract-code", false, "Write the code, SQL and HTML of nodes to .js, .py, .sql and .html files next to the workflow file")
+	addSelectorFlags(refreshCmd)
 	rootcmd.GetWorkflowsCmd().AddCommand(refreshCmd)
 
 	// nolint:errcheck
@@ -77,7 +96,18 @@ func RefreshWorkflows(cmd *cobra.Command, args []string) error {
 
 	minimal := !noTruncate
 
-	return RefreshWorkflowsWithClient(cmd, client, directory, dryRun, overwrite, output, minimal, all)
+	if err := RefreshWorkflowsWithClient(cmd, client, directory, dryRun, overwrite, output, minimal, all); err != nil {
+		return err
+	}
+
+	if watch, _ := cmd.Flags().GetBool("watch"); watch {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		return WatchRemoteWorkflows(ctx, cmd, client, directory, dryRun, output, minimal, all)
+	}
+
+	return nil
 }
 
 // RefreshWorkflowsWithClient is the testable version of RefreshWorkflows that accepts a client interface
@@ -85,12 +115,25 @@ func RefreshWorkflowsWithClient(cmd *cobra.Command, client n8n.ClientInterface,
 	if err := ensureDirectoryExists(cmd, directory, dryRun); err != nil {
 		return err
 	}
+	if err := ensureWorkflowSchema(cmd, directory, dryRun); err != nil {
+		return err
+	}
 
 	localFiles, err := extractLocalWorkflows(directory)
 	if err != nil {
 		return err
 	}
 
+	state, err := LoadWorkflowState(directory)
+	if err != nil {
+		return err
+	}
+
+	selector, err := NewWorkflowSelector(cmd, directory)
+	if err != nil {
+		return err
+	}
+
 	if all || len(localFiles) == 0 {
 		cmd.Println("Refreshing all workflows from n8n instance")
 
@@ -105,25 +148,61 @@ func RefreshWorkflowsWithClient(cmd *cobra.Command, client n8n.ClientInterface,
 			return nil
 		}
 
+		unmanaged := includeUnmanaged(cmd)
+		skipped := 0
 		for _, workflow := range *workflowList.Data {
+			filePath := ""
+			if workflow.Id != nil {
+				filePath = localFiles[*workflow.Id]
+			}
+			if !selector.Matches(workflow, filePath) {
+				continue
+			}
+
+			if !unmanaged && !IsManaged(workflow) && !isLocalWorkflow(workflow, localFiles) {
+				skipped++
+				continue
+			}
+
 			if err := processWorkflow(cmd, workflow, localFiles, directory, dryRun, overwrite, output, minimal); err != nil {
 				return err
 			}
+			if !dryRun {
+				if err := state.Record(workflow); err != nil {
+					return err
+				}
+			}
+		}
+
+		if skipped > 0 {
+			cmd.Printf("Skipped %d workflow(s) not managed by the CLI, use --include-unmanaged to refresh them or 'n8n workflows adopt' to manage them\n", skipped)
 		}
 	} else {
 		cmd.Println("Refreshing only workflows that exist in the directory")
 
 		refreshed := 0
-		for workflowID := range localFiles {
+		for workflowID, filePath := range localFiles {
+			if !selector.MatchesLocation(workflowID, filePath) {
+				continue
+			}
+
 			workflow, err := client.GetWorkflow(workflowID)
 			if err != nil {
 				cmd.Printf("Warning: Could not fetch workflow with ID %s: %v\n", workflowID, err)
 				continue
 			}
+			if !selector.Matches(*workflow, filePath) {
+				continue
+			}
 
 			if err := processWorkflow(cmd, *workflow, localFiles, directory, dryRun, overwrite, output, minimal); err != nil {
 				return err
 			}
+			if !dryRun {
+				if err := state.Record(*workflow); err != nil {
+					return err
+				}
+			}
 			refreshed++
 		}
 
@@ -132,10 +211,23 @@ func RefreshWorkflowsWithClient(cmd *cobra.Command, client n8n.ClientInterface,
 		}
 	}
 
+	if err := state.Save(); err != nil {
+		return err
+	}
+
 	cmd.Println("Workflow refresh completed successfully")
 	return nil
 }
 
+// isLocalWorkflow reports whether a workflow already has a file in the directory
+func isLocalWorkflow(workflow n8n.Workflow, localFiles map[string]string) bool {
+	if workflow.Id == nil {
+		return false
+	}
+	_, exists := localFiles[*workflow.Id]
+	return exists
+}
+
 // ensureDirectoryExists checks if the directory exists and creates it if needed
 func ensureDirectoryExists(cmd *cobra.Command, directory string, dryRun bool) error {
 	_, err := os.Stat(directory)
@@ -172,26 +264,27 @@ func extractLocalWorkflows(directory string) (map[string]string, error) {
 	}
 
 	for _, file := range files {
+		filePath := filepath.Join(directory, file.Name())
 		if file.IsDir() {
+			if !isWorkflowDirectory(filePath) {
+				continue
+			}
+		} else if !isWorkflowFile(file.Name()) {
 			continue
 		}
 
-		ext := strings.ToLower(filepath.Ext(file.Name()))
-		if ext != ".json" && ext != ".yaml" && ext != ".yml" {
+		workflowID, err := ExtractWorkflowIDFromFile(filePath)
+		if err != nil {
+			logger.Warn("%v", workflowFileError("Skipping workflow file", filePath, err))
 			continue
 		}
-
-		filePath := filepath.Join(directory, file.Name())
-		workflowID, err := ExtractWorkflowIDFromFile(filePath)
-		if err != nil || workflowID == "" {
+		if workflowID == "" {
 			continue
 		}
 
+		// A workflow converted to another format is kept in the newer format: a directory over YAML over JSON
 		if existingPath, exists := localFiles[workflowID]; exists {
-			existingExt := strings.ToLower(filepath.Ext(existingPath))
-			currentExt := strings.ToLower(filepath.Ext(filePath))
-
-			if (currentExt == ".yaml" || currentExt == ".yml") && existingExt == ".json" {
+			if formatRank(filePath) > formatRank(existingPath) {
 				localFiles[workflowID] = filePath
 			}
 			continue
@@ -203,60 +296,117 @@ func extractLocalWorkflows(directory string) (map[string]string, error) {
 	return localFiles, nil
 }
 
+// formatRank orders the formats of workflow paths, preferring directories over YAML over JSON
+func formatRank(filePath string) int {
+	switch workflowFormat(filePath) {
+	case outputDirectory:
+		return 2
+	case "yaml":
+		return 1
+	}
+	return 0
+}
+
 // determineFilePathAndAction decides what file path and action to take for a workflow
 func determineFilePathAndAction(workflow n8n.Workflow, localFiles map[string]string, directory string, output string, overwrite bool) (string, string) {
 	sanitizedName := rootcmd.SanitizeFilename(workflow.Name)
 
-	extension := ".json"
+	format := fileFormat(output)
+	existingPath, exists := localFiles[*workflow.Id]
 
-	if existingPath, exists := localFiles[*workflow.Id]; exists && output == "" {
-		existingExt := strings.ToLower(filepath.Ext(existingPath))
-		if existingExt == ".yaml" || existingExt == ".yml" {
-			extension = existingExt
+	extension := ".json"
+	if exists && output == "" {
+		switch workflowFormat(existingPath) {
+		case outputDirectory:
+			extension = ""
+		case "yaml":
+			extension = strings.ToLower(filepath.Ext(existingPath))
 		}
-	} else if strings.ToLower(output) == "yaml" || strings.ToLower(output) == "yml" {
+	} else if format == "yaml" {
 		extension = ".yaml"
+	} else if format == outputDirectory {
+		extension = ""
 	}
 
 	defaultPath := filepath.Join(directory, sanitizedName+extension)
 
-	existingPath, exists := localFiles[*workflow.Id]
 	if !exists || overwrite {
 		return defaultPath, "Creating"
 	}
 
-	existingExt := filepath.Ext(existingPath)
-	if (strings.ToLower(output) == "yaml" || strings.ToLower(output) == "yml") && strings.ToLower(existingExt) == ".json" {
-		return defaultPath, "Converting"
-	}
-
-	if strings.ToLower(output) == "json" && (strings.ToLower(existingExt) == ".yaml" || strings.ToLower(existingExt) == ".yml") {
+	if format != "" && format != workflowFormat(existingPath) {
 		return defaultPath, "Converting"
 	}
 
 	return existingPath, "Updating"
 }
 
-// serializeWorkflow serializes a workflow to JSON or YAML
-func serializeWorkflow(workflow n8n.Workflow, filePath string, minimal bool) ([]byte, error) {
+// serializeWorkflow serializes a workflow to JSON or YAML. With extractCode, the code, SQL and HTML of
+// nodes is returned as separate files referenced from the workflow.
+func serializeWorkflow(workflow n8n.Workflow, filePath string, minimal bool, extractCode bool) ([]byte, []n8n.CodeFile, error) {
 	encoder := n8n.NewWorkflowEncoder(minimal)
 
+	var codeFiles []n8n.CodeFile
+	if extractCode {
+		workflow, codeFiles = encoder.WithCodeFiles(codeDir(filePath)).ExtractCode(workflow)
+	}
+
 	ext := strings.ToLower(filepath.Ext(filePath))
 	if ext == ".yaml" || ext == ".yml" {
 		yamlData, err := encoder.EncodeToYAML(workflow)
 		if err != nil {
-			return nil, fmt.Errorf("error serializing workflow '%s' to YAML: %w", workflow.Name, err)
+			return nil, nil, fmt.Errorf("error serializing workflow '%s' to YAML: %w", workflow.Name, err)
 		}
 
-		return yamlData, nil
+		return yamlData, codeFiles, nil
 	}
 
 	jsonData, err := encoder.EncodeToJSON(workflow)
 	if err != nil {
-		return nil, fmt.Errorf("error serializing workflow '%s' to JSON: %w", workflow.Name, err)
+		return nil, nil, fmt.Errorf("error serializing workflow '%s' to JSON: %w", workflow.Name, err)
+	}
+
+	return jsonData, codeFiles, nil
+}
+
+// writeWorkflowFile writes a serialized workflow and its code files
+func writeWorkflowFile(filePath string, content []byte, codeFiles []n8n.CodeFile, extractCode bool) error {
+	if extractCode {
+		if err := writeCodeFiles(filePath, codeFiles); err != nil {
+			return err
+		}
 	}
 
-	return jsonData, nil
+	return os.WriteFile(filePath, content, 0644)
+}
+
+// preserveYAMLFormatting patches the existing YAML file with the newly serialized content, so comments, key
+// order and block strings in the file survive. The serialized content is returned when the file can't be patched.
+func preserveYAMLFormatting(filePath string, content []byte) []byte {
+	existing, err := os.ReadFile(filePath)
+	if err != nil {
+		return content
+	}
+
+	patched, err := n8n.PatchYAML(existing, content)
+	if err != nil {
+		logger.Warn("Could not keep the formatting of %s: %v", filePath, err)
+		return content
+	}
+
+	return patched
+}
+
+// preserveYAMLDirectoryFormatting keeps the comments and formatting of the existing YAML files of a workflow directory
+func preserveYAMLDirectoryFormatting(dirPath string, files map[string][]byte) map[string][]byte {
+	preserved := make(map[string][]byte, len(files))
+	for filePath, content := range files {
+		if path.Ext(filePath) == ".yaml" {
+			content = preserveYAMLFormatting(filepath.Join(dirPath, filepath.FromSlash(filePath)), content)
+		}
+		preserved[filePath] = content
+	}
+	return preserved
 }
 
 // workflowNeedsUpdate compares existing workflow file content with new content
@@ -301,19 +451,53 @@ func processWorkflow(cmd *cobra.Command, workflow n8n.Workflow, localFiles map[s
 	filePath, action := determineFilePathAndAction(workflow, localFiles, directory, output, overwrite)
 	existingPath := localFiles[*workflow.Id]
 
-	content, err := serializeWorkflow(workflow, filePath, minimal)
-	if err != nil {
-		return err
+	// Canonical files are written as they are serialized, other YAML files keep their comments and formatting
+	canonical := canonicalOutput(cmd)
+	if canonical {
+		workflow = n8n.CanonicalWorkflow(workflow)
 	}
+	preserveFormatting := action == "Updating" && !canonical
 
-	needsUpdate := true
-	if action == "Updating" {
-		needsUpdate = workflowNeedsUpdate(filePath, existingPath, content, minimal)
-		if !needsUpdate {
-			cmd.Printf("No changes for workflow '%s' (ID: %s) in file: %s\n",
-				workflow.Name, *workflow.Id, filePath)
-			return nil
+	var needsUpdate bool
+	var write func() error
+	if isDirectoryFormat(filePath) {
+		files, err := serializeWorkflowDirectory(workflow, minimal)
+		if err != nil {
+			return err
 		}
+		if preserveFormatting {
+			files = preserveYAMLDirectoryFormatting(filePath, files)
+		}
+		header := yamlSchemaHeader(cmd, directory, filepath.Join(filePath, n8n.DirectoryWorkflowFile))
+		files[n8n.DirectoryWorkflowFile] = withSchemaHeader(files[n8n.DirectoryWorkflowFile], header)
+
+		needsUpdate = action != "Updating" || workflowDirectoryChanged(filePath, files)
+		write = func() error { return writeWorkflowDirectory(filePath, files) }
+	} else {
+		extractCode := shouldExtractCode(cmd, filePath) || fileHasCodeReferences(existingPath)
+		content, codeFiles, err := serializeWorkflow(workflow, filePath, minimal, extractCode)
+		if err != nil {
+			return err
+		}
+		header := ""
+		if workflowFormat(filePath) == "yaml" {
+			if preserveFormatting {
+				content = preserveYAMLFormatting(filePath, content)
+			}
+			header = yamlSchemaHeader(cmd, directory, filePath)
+			content = withSchemaHeader(content, header)
+		}
+
+		needsUpdate = action != "Updating" || workflowNeedsUpdate(filePath, existingPath, content, minimal) ||
+			(extractCode && codeFilesChanged(filePath, codeFiles)) ||
+			(header != "" && !hasSchemaHeader(filePath, header))
+		write = func() error { return writeWorkflowFile(filePath, content, codeFiles, extractCode) }
+	}
+
+	if action == "Updating" && !needsUpdate {
+		cmd.Printf("No changes for workflow '%s' (ID: %s) in file: %s\n",
+			workflow.Name, *workflow.Id, filePath)
+		return nil
 	}
 
 	if dryRun {
@@ -327,7 +511,7 @@ func processWorkflow(cmd *cobra.Command, workflow n8n.Workflow, localFiles map[s
 		return nil
 	}
 
-	if err := os.WriteFile(filePath, content, 0644); err != nil {
+	if err := write(); err != nil {
 		return fmt.Errorf("error writing workflow '%s' to file: %w", workflow.Name, err)
 	}
 
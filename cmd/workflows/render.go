@@ -0,0 +1,99 @@
+/*
+Copyright © 2025 Eden Reich
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package workflows
+
+import (
+	"fmt"
+	"strings"
+
+	rootcmd "github.com/edenreich/n8n-cli/cmd"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/spf13/cobra"
+)
+
+// RenderCmd represents the render command
+var RenderCmd = &cobra.Command{
+	Use:   "render FILE",
+	Short: "Print a workflow file after applying overlays and variable substitution",
+	Long: `Render prints the final workflow that sync would upload for a workflow file.
+
+Overlays are patch files stored in an overlays/<name>/ directory next to the workflow files,
+named like the workflow file they patch. Maps are merged recursively, a null value removes a key
+and nodes are merged by name, so an overlay only needs to contain what differs per environment.
+
+Variable substitution replaces ${VAR} and ${VAR:-default} placeholders with values from the
+environment or the .env file. Use $${VAR} to keep a placeholder literally.
+
+Examples:
+
+  # Render a workflow with the production overlay
+  n8n workflows render workflows/Contact_Form.yaml --overlay production
+
+  # Render a workflow with placeholders resolved from .env
+  n8n workflows render workflows/Contact_Form.yaml --substitute --output json`,
+	Args:        cobra.ExactArgs(1),
+	Annotations: map[string]string{rootcmd.OfflineAnnotation: "true"},
+	RunE:        renderWorkflow,
+}
+
+func init() {
+	RenderCmd.Flags().String("overlay", "", "Name of the overlay to apply from the overlays/<name>/ directory")
+	RenderCmd.Flags().Bool("substitute", false, "Replace ${VAR} placeholders with values from the environment or .env file")
+	RenderCmd.Flags().StringP("output", "o", "", "Output format (json or yaml). If not specified, uses the format of the file")
+	rootcmd.GetWorkflowsCmd().AddCommand(RenderCmd)
+}
+
+// renderWorkflow is the handler for the render command
+func renderWorkflow(cmd *cobra.Command, args []string) error {
+	filePath := args[0]
+	output, _ := cmd.Flags().GetString("output")
+
+	workflow, err := decodeWorkflowFile(cmd, filePath)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		output = workflowFormat(filePath)
+		if output == outputDirectory {
+			output = "yaml"
+		}
+	}
+
+	encoder := n8n.NewWorkflowEncoder(true)
+
+	var content []byte
+	switch strings.ToLower(output) {
+	case "yaml", "yml":
+		content, err = encoder.EncodeToYAML(workflow)
+	case "json":
+		content, err = encoder.EncodeToJSON(workflow)
+	default:
+		return fmt.Errorf("unsupported output format: %s. Supported formats: json, yaml", output)
+	}
+	if err != nil {
+		return fmt.Errorf("error encoding workflow: %w", err)
+	}
+
+	_, err = fmt.Fprintln(cmd.OutOrStdout(), strings.TrimSuffix(string(content), "\n"))
+	return err
+}
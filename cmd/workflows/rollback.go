@@ -0,0 +1,83 @@
+/*
+Copyright © 2025 Eden Reich
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package workflows
+
+import (
+	rootcmd "github.com/edenreich/n8n-cli/cmd"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/spf13/cobra"
+)
+
+// RollbackCmd represents the rollback command
+var RollbackCmd = &cobra.Command{
+	Use:   "rollback BACKUP",
+	Short: "Restore workflows from a backup taken by sync",
+	Long: `Restores every workflow saved in a backup to the exact version it had before sync changed it,
+including its activation state and tags.
+
+Sync saves a backup in <directory>/.n8n/backups/<timestamp>/ before changing or deleting workflows
+and prints its path. Workflows deleted since the backup are created again with a new ID. Workflows
+and tags created by the sync run are deleted, unless --keep-created is used.
+
+Examples:
+
+  # Preview what a rollback would change
+  n8n workflows rollback workflows/.n8n/backups/20250501T100000Z --dry-run
+
+  # Restore the workflows
+  n8n workflows rollback workflows/.n8n/backups/20250501T100000Z`,
+	Args: cobra.ExactArgs(1),
+	RunE: rollbackWorkflows,
+}
+
+func init() {
+	RollbackCmd.Flags().Bool("dry-run", false, "Show what would be restored without making changes")
+	RollbackCmd.Flags().Bool("keep-created", false, "Keep workflows and tags that were created after the backup was taken")
+	rootcmd.GetWorkflowsCmd().AddCommand(RollbackCmd)
+}
+
+// rollbackWorkflows is the handler for the rollback command
+func rollbackWorkflows(cmd *cobra.Command, args []string) error {
+	client := newClient(cmd)
+
+	return RollbackWorkflowsWithClient(cmd, client, args[0])
+}
+
+// RollbackWorkflowsWithClient is the testable version of the rollback command that accepts a client interface
+func RollbackWorkflowsWithClient(cmd *cobra.Command, client n8n.ClientInterface, backupPath string) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	keepCreated, _ := cmd.Flags().GetBool("keep-created")
+
+	backup, err := LoadBackup(backupPath)
+	if err != nil {
+		return err
+	}
+
+	cmd.Printf("Restoring %d workflow(s) from backup taken at %s\n", len(backup.Manifest.Workflows), backup.Manifest.CreatedAt.Format("2006-01-02 15:04:05 MST"))
+
+	if err := RestoreBackup(client, cmd, backup, dryRun, keepCreated); err != nil {
+		return err
+	}
+
+	cmd.Println("Rollback completed successfully")
+	return nil
+}
@@ -0,0 +1,142 @@
+/*
+Copyright © 2025 Eden Reich
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package workflows
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// schemaFileName is the name of the workflow file schema that refresh writes to the state directory, where it
+// isn't mistaken for a workflow file
+const schemaFileName = "workflow.schema.json"
+
+// schemaHeaderPrefix starts the comment that tells the YAML language server which schema a file follows
+const schemaHeaderPrefix = "# yaml-language-server: $schema="
+
+// schemaEnabled reports whether the workflow schema and the headers referencing it are written, from the
+// --schema flag or the schema key of the config file
+func schemaEnabled(cmd *cobra.Command) bool {
+	if schema, _ := cmd.Flags().GetBool("schema"); schema {
+		return true
+	}
+	return viper.GetBool("schema")
+}
+
+// schemaPath returns the path of the workflow schema in a workflow directory
+func schemaPath(directory string) string {
+	return filepath.Join(directory, stateDirectory, schemaFileName)
+}
+
+// ensureWorkflowSchema writes the workflow schema to the state directory when it is enabled and out of date
+func ensureWorkflowSchema(cmd *cobra.Command, directory string, dryRun bool) error {
+	if !schemaEnabled(cmd) || dryRun {
+		return nil
+	}
+
+	data, err := n8n.MarshalWorkflowSchema()
+	if err != nil {
+		return fmt.Errorf("error generating the workflow schema: %w", err)
+	}
+
+	path := schemaPath(directory)
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, data) {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error creating directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("error writing the workflow schema: %w", err)
+	}
+	return nil
+}
+
+// yamlSchemaHeader returns the schema header of a YAML workflow file: the one referencing the schema of the
+// workflow directory when the schema is enabled, otherwise the one the existing file has, if any
+func yamlSchemaHeader(cmd *cobra.Command, directory string, yamlPath string) string {
+	if !schemaEnabled(cmd) {
+		content, err := os.ReadFile(yamlPath)
+		if err != nil {
+			return ""
+		}
+		return readSchemaHeader(content)
+	}
+
+	ref, err := filepath.Rel(filepath.Dir(yamlPath), schemaPath(directory))
+	if err != nil {
+		return ""
+	}
+	return schemaHeaderPrefix + filepath.ToSlash(ref)
+}
+
+// readSchemaHeader returns the schema header among the leading comments of YAML content
+func readSchemaHeader(content []byte) string {
+	for _, line := range bytes.Split(content, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if bytes.HasPrefix(line, []byte(schemaHeaderPrefix)) {
+			return string(line)
+		}
+		if len(line) > 0 && line[0] != '#' {
+			break
+		}
+	}
+	return ""
+}
+
+// withSchemaHeader returns YAML content starting with the given schema header, replacing the schema header
+// among its leading comments
+func withSchemaHeader(content []byte, header string) []byte {
+	if header == "" || bytes.HasPrefix(content, []byte(header+"\n")) {
+		return content
+	}
+
+	lines := bytes.Split(content, []byte("\n"))
+	for i, line := range lines {
+		trimmed := bytes.TrimSpace(line)
+		if bytes.HasPrefix(trimmed, []byte(schemaHeaderPrefix)) {
+			lines = append(lines[:i], lines[i+1:]...)
+			break
+		}
+		if len(trimmed) > 0 && trimmed[0] != '#' {
+			break
+		}
+	}
+
+	return append([]byte(header+"\n"), bytes.Join(lines, []byte("\n"))...)
+}
+
+// hasSchemaHeader reports whether a YAML file starts with the given schema header
+func hasSchemaHeader(yamlPath string, header string) bool {
+	content, err := os.ReadFile(yamlPath)
+	if err != nil {
+		return false
+	}
+	return bytes.HasPrefix(content, []byte(header+"\n"))
+}
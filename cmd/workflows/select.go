@@ -0,0 +1,264 @@
+/*
+Copyright © 2025 Eden Reich
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package workflows
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/spf13/cobra"
+)
+
+// WorkflowSelector narrows the workflows a command operates on. A workflow is selected when it matches
+// every criterion that is set, and one of the values given for it.
+type WorkflowSelector struct {
+	// Names are glob patterns matched against the workflow name
+	Names []string
+	// Tags are tag names, one of which the workflow must carry
+	Tags []string
+	// IDs are workflow IDs
+	IDs []string
+	// Files are the absolute paths of the selected workflow files, nil when not selecting by file
+	Files map[string]bool
+}
+
+// addSelectorFlags adds the flags selecting workflows by name, tag, ID or files changed in git
+func addSelectorFlags(command *cobra.Command) {
+	command.Flags().StringSlice("name", nil, "Only process workflows whose name matches one of these glob patterns")
+	command.Flags().StringSlice("tag", nil, "Only process workflows carrying one of these tags")
+	command.Flags().StringSlice("id", nil, "Only process workflows with one of these IDs")
+	command.Flags().String("changed-since", "", "Only process workflow files changed in git since this ref (commit, branch or tag)")
+}
+
+// NewWorkflowSelector builds the selector from the selector flags and the workflow files given as arguments.
+// Files are resolved relative to the working directory, or to the workflow directory when they don't exist there.
+func NewWorkflowSelector(cmd *cobra.Command, directory string) (*WorkflowSelector, error) {
+	names, _ := cmd.Flags().GetStringSlice("name")
+	tags, _ := cmd.Flags().GetStringSlice("tag")
+	ids, _ := cmd.Flags().GetStringSlice("id")
+	changedSince, _ := cmd.Flags().GetString("changed-since")
+
+	for _, pattern := range names {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid --name pattern '%s': %w", pattern, err)
+		}
+	}
+
+	selector := &WorkflowSelector{Names: names, Tags: tags, IDs: ids}
+
+	files := cmd.Flags().Args()
+	if len(files) > 0 {
+		selector.Files = make(map[string]bool)
+		for _, file := range files {
+			if _, err := os.Stat(file); os.IsNotExist(err) && !filepath.IsAbs(file) {
+				if _, err := os.Stat(filepath.Join(directory, file)); err == nil {
+					file = filepath.Join(directory, file)
+				}
+			}
+			if err := selector.addFile(file); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if changedSince != "" {
+		changed, err := changedWorkflowFiles(directory, changedSince)
+		if err != nil {
+			return nil, err
+		}
+
+		// Combined with file arguments, only the given files that also changed are selected
+		given := selector.Files
+		selector.Files = make(map[string]bool)
+		for _, file := range changed {
+			absPath, err := filepath.Abs(file)
+			if err != nil {
+				return nil, fmt.Errorf("error resolving path %s: %w", file, err)
+			}
+			if given == nil || given[absPath] {
+				selector.Files[absPath] = true
+			}
+		}
+	}
+
+	return selector, nil
+}
+
+// addFile adds a workflow file to the selected files
+func (s *WorkflowSelector) addFile(file string) error {
+	absPath, err := filepath.Abs(file)
+	if err != nil {
+		return fmt.Errorf("error resolving path %s: %w", file, err)
+	}
+	if _, err := os.Stat(absPath); err != nil {
+		return fmt.Errorf("error accessing workflow file %s: %w", file, err)
+	}
+
+	s.Files[absPath] = true
+	return nil
+}
+
+// IsEmpty reports whether no criterion is set, in which case every workflow is selected
+func (s *WorkflowSelector) IsEmpty() bool {
+	return s == nil || (len(s.Names) == 0 && len(s.Tags) == 0 && len(s.IDs) == 0 && s.Files == nil)
+}
+
+// MatchesLocation reports whether a workflow with the given ID and file can be selected, without
+// looking at its content. It lets commands skip fetching workflows that can't match.
+func (s *WorkflowSelector) MatchesLocation(workflowID string, filePath string) bool {
+	if s.IsEmpty() {
+		return true
+	}
+
+	if len(s.IDs) > 0 && !slices.Contains(s.IDs, workflowID) {
+		return false
+	}
+
+	if s.Files != nil {
+		if filePath == "" {
+			return false
+		}
+		absPath, err := filepath.Abs(filePath)
+		if err != nil || !s.Files[absPath] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Matches reports whether a workflow, read from the given file if it has one, is selected
+func (s *WorkflowSelector) Matches(workflow n8n.Workflow, filePath string) bool {
+	if s.IsEmpty() {
+		return true
+	}
+
+	workflowID := ""
+	if workflow.Id != nil {
+		workflowID = *workflow.Id
+	}
+	if !s.MatchesLocation(workflowID, filePath) {
+		return false
+	}
+
+	if len(s.Names) > 0 && !slices.ContainsFunc(s.Names, func(pattern string) bool {
+		matched, _ := path.Match(pattern, workflow.Name)
+		return matched
+	}) {
+		return false
+	}
+
+	if len(s.Tags) > 0 && !hasAnyTag(workflow, s.Tags) {
+		return false
+	}
+
+	return true
+}
+
+// SelectWorkflows returns the local workflows matched by the selector
+func (s *WorkflowSelector) SelectWorkflows(localWorkflows []LocalWorkflow) []LocalWorkflow {
+	if s.IsEmpty() {
+		return localWorkflows
+	}
+
+	var selected []LocalWorkflow
+	for _, local := range localWorkflows {
+		if s.Matches(local.Workflow, local.FilePath) {
+			selected = append(selected, local)
+		}
+	}
+
+	return selected
+}
+
+// changedWorkflowFiles returns the workflow files in a directory that were added or modified since a git ref,
+// including changes that are not committed yet and new files that are not tracked yet
+func changedWorkflowFiles(directory string, ref string) ([]string, error) {
+	changed, err := gitFileList(directory, ref, "diff", "--name-only", "--relative", "--diff-filter=d", ref, "--", ".")
+	if err != nil {
+		return nil, err
+	}
+
+	untracked, err := gitFileList(directory, ref, "ls-files", "--others", "--exclude-standard", "--", ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	seen := make(map[string]bool)
+	for _, line := range append(changed, untracked...) {
+		file := filepath.Join(directory, filepath.FromSlash(line))
+
+		// Workflow files are only read from the top level of the directory, a changed code file
+		// selects the workflow file it belongs to, a changed file of a workflow directory the directory
+		// and a changed overlay file the workflow it patches
+		workflowDir, inWorkflowDir := workflowDirectoryForFile(file)
+		switch {
+		case line == "":
+			continue
+		case inWorkflowDir:
+			file = workflowDir
+		case strings.HasPrefix(line, overlaysDirectory+"/") && strings.Count(line, "/") == 2:
+			workflowFile, ok := workflowFileForOverlay(file)
+			if !ok {
+				continue
+			}
+			file = workflowFile
+		case strings.Count(line, "/") == 1:
+			workflowFile, ok := workflowFileForCode(file)
+			if !ok {
+				continue
+			}
+			file = workflowFile
+		case strings.Contains(line, "/") || !isWorkflowFile(line):
+			continue
+		}
+
+		if !seen[file] {
+			seen[file] = true
+			files = append(files, file)
+		}
+	}
+
+	return files, nil
+}
+
+// gitFileList runs a git command listing files in a directory, relative to it, one per line
+func gitFileList(directory string, ref string, args ...string) ([]string, error) {
+	output, err := exec.Command("git", append([]string{"-C", directory}, args...)...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("error listing files changed since %s: %s", ref, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("error listing files changed since %s: %w", ref, err)
+	}
+
+	return strings.Split(strings.TrimSpace(string(output)), "\n"), nil
+}
@@ -0,0 +1,252 @@
+/*
+Copyright © 2025 Eden Reich
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package workflows
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/edenreich/n8n-cli/logger"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/spf13/cobra"
+)
+
+// stateDirectory is the directory next to the workflow files where the CLI keeps its metadata.
+// Being a directory, it is never mistaken for a workflow file.
+const stateDirectory = ".n8n"
+
+// stateFileName is the name of the file recording the remote state of the workflows
+const stateFileName = "state.json"
+
+// WorkflowStateEntry records the remote version of a workflow the local file was last synced with
+type WorkflowStateEntry struct {
+	Name      string        `json:"name"`
+	UpdatedAt *time.Time    `json:"updatedAt,omitempty"`
+	Hash      string        `json:"hash"`
+	Base      *n8n.Workflow `json:"base,omitempty"`
+}
+
+// WorkflowState records the remote version of every workflow at the time it was last refreshed or synced.
+// It is used to detect changes made in the n8n UI that a sync would otherwise overwrite.
+type WorkflowState struct {
+	Workflows map[string]WorkflowStateEntry `json:"workflows"`
+
+	path  string
+	dirty bool
+}
+
+// LoadWorkflowState reads the state file of a workflows directory, returning an empty state when there is none yet
+func LoadWorkflowState(directory string) (*WorkflowState, error) {
+	state := &WorkflowState{
+		Workflows: make(map[string]WorkflowStateEntry),
+		path:      filepath.Join(directory, stateDirectory, stateFileName),
+	}
+
+	content, err := os.ReadFile(state.path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading state file: %w", err)
+	}
+
+	if err := json.Unmarshal(content, state); err != nil {
+		return nil, fmt.Errorf("error parsing state file %s: %w", state.path, err)
+	}
+	if state.Workflows == nil {
+		state.Workflows = make(map[string]WorkflowStateEntry)
+	}
+
+	return state, nil
+}
+
+// Record stores the given remote version of a workflow as the version the local file is based on
+func (s *WorkflowState) Record(workflow n8n.Workflow) error {
+	if workflow.Id == nil || *workflow.Id == "" {
+		return nil
+	}
+
+	hash, err := n8n.WorkflowContentHash(workflow)
+	if err != nil {
+		return err
+	}
+
+	base := n8n.CleanWorkflow(workflow)
+	base.StaticData = nil
+
+	s.Workflows[*workflow.Id] = WorkflowStateEntry{
+		Name:      workflow.Name,
+		UpdatedAt: workflow.UpdatedAt,
+		Hash:      hash,
+		Base:      &base,
+	}
+	s.dirty = true
+
+	return nil
+}
+
+// RemoteChanged reports whether the remote workflow changed since it was recorded.
+// Workflows without a recorded version are never reported as changed.
+func (s *WorkflowState) RemoteChanged(remote n8n.Workflow) (WorkflowStateEntry, bool, error) {
+	if remote.Id == nil {
+		return WorkflowStateEntry{}, false, nil
+	}
+
+	entry, ok := s.Workflows[*remote.Id]
+	if !ok {
+		return WorkflowStateEntry{}, false, nil
+	}
+
+	hash, err := n8n.WorkflowContentHash(remote)
+	if err != nil {
+		return entry, false, err
+	}
+
+	return entry, hash != entry.Hash, nil
+}
+
+// Save writes the state file if anything was recorded since it was loaded
+func (s *WorkflowState) Save() error {
+	if !s.dirty {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("error creating state directory: %w", err)
+	}
+
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding state file: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("error writing state file: %w", err)
+	}
+
+	s.dirty = false
+	return nil
+}
+
+// snapshotStateFile keeps the current content of the state file of a directory in memory and returns a
+// function that writes it back, used to forget the versions recorded by a sync run that was reverted
+func snapshotStateFile(directory string) (func() error, error) {
+	path := filepath.Join(directory, stateDirectory, stateFileName)
+
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error reading state file: %w", err)
+	}
+	existed := err == nil
+
+	return func() error {
+		if !existed {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("error restoring state file: %w", err)
+			}
+			return nil
+		}
+
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			return fmt.Errorf("error restoring state file: %w", err)
+		}
+		return nil
+	}, nil
+}
+
+// checkRemoteConflict decides whether a local workflow may be uploaded over a remote workflow that was
+// changed in n8n since it was last refreshed. It returns false when the remote version should be kept.
+// Without --force, --theirs or --merge a conflict stops the sync with a report of what changed where.
+func checkRemoteConflict(cmd *cobra.Command, state *WorkflowState, workflow *n8n.Workflow, remote *n8n.Workflow) (bool, error) {
+	entry, changed, err := state.RemoteChanged(*remote)
+	if err != nil {
+		return false, err
+	}
+	if !changed {
+		if entry.Hash == "" {
+			logger.Debug("No recorded remote version for workflow '%s', skipping conflict detection", workflow.Name)
+		}
+		return true, nil
+	}
+
+	force, _ := cmd.Flags().GetBool("force")
+	theirs, _ := cmd.Flags().GetBool("theirs")
+	merge, _ := cmd.Flags().GetBool("merge")
+
+	switch {
+	case force:
+		cmd.Printf("Overwriting changes made in n8n to workflow '%s' (ID: %s)\n", workflow.Name, *remote.Id)
+		return true, nil
+	case theirs:
+		cmd.Printf("Keeping changes made in n8n to workflow '%s' (ID: %s), local changes are not uploaded\n", workflow.Name, *remote.Id)
+		return false, nil
+	}
+
+	var conflicts []string
+	if merge && entry.Base != nil {
+		merged, mergeConflicts, err := n8n.MergeWorkflows(*entry.Base, *workflow, *remote)
+		if err != nil {
+			return false, fmt.Errorf("error merging workflow '%s': %w", workflow.Name, err)
+		}
+
+		if len(mergeConflicts) == 0 {
+			*workflow = merged
+			cmd.Printf("Merged changes made in n8n into workflow '%s' (ID: %s)\n", workflow.Name, *remote.Id)
+			return true, nil
+		}
+		conflicts = mergeConflicts
+	}
+
+	return false, conflictReport(workflow, remote, entry, conflicts)
+}
+
+// conflictReport builds the error describing a workflow that was changed both locally and in n8n
+func conflictReport(workflow *n8n.Workflow, remote *n8n.Workflow, entry WorkflowStateEntry, conflicts []string) error {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "conflict: workflow '%s' (ID: %s) was changed in n8n since it was last refreshed", workflow.Name, *remote.Id)
+	if remote.UpdatedAt != nil {
+		fmt.Fprintf(&builder, " (updated at %s", remote.UpdatedAt.Format(time.RFC3339))
+		if entry.UpdatedAt != nil {
+			fmt.Fprintf(&builder, ", last refreshed version from %s", entry.UpdatedAt.Format(time.RFC3339))
+		}
+		builder.WriteString(")")
+	}
+
+	if entry.Base != nil {
+		if changed, err := n8n.ChangedParts(*entry.Base, *workflow); err == nil && len(changed) > 0 {
+			fmt.Fprintf(&builder, "\n  changed locally: %s", strings.Join(changed, ", "))
+		}
+		if changed, err := n8n.ChangedParts(*entry.Base, *remote); err == nil && len(changed) > 0 {
+			fmt.Fprintf(&builder, "\n  changed in n8n: %s", strings.Join(changed, ", "))
+		}
+	}
+	if len(conflicts) > 0 {
+		fmt.Fprintf(&builder, "\n  changed on both sides: %s", strings.Join(conflicts, ", "))
+	}
+
+	builder.WriteString("\nRun with --theirs to keep the version in n8n, --merge to merge changes that don't overlap or --force to overwrite it")
+	return fmt.Errorf("%s", builder.String())
+}
@@ -22,24 +22,25 @@ THE SOFTWARE.
 package workflows
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"reflect"
 	"strings"
+	"syscall"
 
 	"github.com/edenreich/n8n-cli/cmd"
 	"github.com/edenreich/n8n-cli/logger"
 	"github.com/edenreich/n8n-cli/n8n"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
 )
 
 // syncCmd represents the sync command
 var SyncCmd = &cobra.Command{
-	Use:   "sync",
+	Use:   "sync [FILE...]",
 	Short: "Synchronize workflows between local files and n8n instance",
 	Long: `Synchronizes workflow files from a local directory to an n8n instance.
 
@@ -57,6 +58,13 @@ Examples:
   # Sync without refreshing local files afterward
   n8n workflows sync --directory workflows/ --refresh=false
 
+  # Keep syncing files when they change while developing
+  n8n workflows sync --directory workflows/ --watch
+
+  # Only sync the given files, or the workflows changed in git since a ref
+  n8n workflows sync --directory workflows/ workflows/Orders.yaml workflows/Invoices.yaml
+  n8n workflows sync --directory workflows/ --changed-since origin/main
+
 This command processes JSON and YAML workflow files and ensures they exist on your n8n instance:
 
 1. Each workflow file is processed intelligently:
@@ -95,8 +103,36 @@ This command processes JSON and YAML workflow files and ensures they exist on yo
    - Use --dry-run to preview changes without applying them
    - Use --prune to remove remote workflows that don't exist locally
    - Use --refresh=false to prevent refreshing local files with remote state after sync
-   - Use --output to specify the format (json or yaml) for refreshed workflow files
-   - Use --all to refresh all workflows from n8n instance, not just those in the directory`,
+   - Use --output to specify the format (json, yaml or dir) for refreshed workflow files
+   - Use --all to refresh all workflows from n8n instance, not just those in the directory
+   - Use --overlay to apply per-environment patches from the overlays/<name>/ directory
+   - Use --substitute to replace ${VAR} placeholders with values from the environment or .env file
+   - Use --resolve-credentials to look up node credentials by type and name on the target instance
+   - Use --credentials-map to provide credential IDs for the target instance from a YAML or JSON file
+   - Use --name, --tag, --id, file arguments or --changed-since to only sync some of the workflows
+   - Use --match-by name or --match-by key to update existing workflows from files without an ID
+
+Workflows referenced by Execute Workflow nodes or the error workflow setting are synced before the
+workflows referencing them, and the references are rewritten to the IDs the workflows got on the
+n8n instance.
+
+The remote version of every synced or refreshed workflow is recorded in .n8n/state.json in the
+directory. When a workflow was changed in n8n since then, sync stops with a conflict report instead
+of overwriting those changes:
+   - Use --force to overwrite the changes made in n8n
+   - Use --theirs to keep the changes made in n8n and skip the local version
+   - Use --merge to merge local and remote changes to different nodes and connections
+
+Pruning removes remote workflows that are not in the directory. It can be scoped with --prune-tag,
+--prune-project and --prune-pattern, and never touches the workflows listed under prune.protected
+in the config file (IDs or name patterns). When run in a terminal, the workflows are listed and
+confirmation is asked first, unless --yes is used. --max-deletions aborts pruning when more
+workflows would be removed, and --archive deactivates and tags workflows instead of deleting them.
+
+Before a workflow is changed or deleted, its current remote version is saved to a timestamped backup
+in .n8n/backups/ (disable with --backup=false). Restore a backup with 'n8n workflows rollback'.
+With --atomic, a failing run is reverted automatically: previous content, activation and tags are
+restored and workflows and tags created during the run are deleted.`,
 	RunE: SyncWorkflows,
 }
 
@@ -106,9 +142,36 @@ func init() {
 	SyncCmd.Flags().StringP("directory", "d", "", "Directory containing workflow files (JSON/YAML) (required)")
 	SyncCmd.Flags().Bool("dry-run", false, "Show what would be uploaded without making changes")
 	SyncCmd.Flags().Bool("prune", false, "Remove workflows that are not present in the directory")
+	SyncCmd.Flags().StringSlice("prune-tag", nil, "Only prune workflows carrying one of these tags")
+	SyncCmd.Flags().String("prune-project", "", "Only prune workflows in this project (ID or name)")
+	SyncCmd.Flags().String("prune-pattern", "", "Only prune workflows whose name matches this glob pattern")
+	SyncCmd.Flags().Int("max-deletions", 0, "Abort pruning when more workflows would be removed (0 for no limit)")
+	SyncCmd.Flags().Bool("archive", false, "Deactivate and tag pruned workflows instead of deleting them")
+	SyncCmd.Flags().String("archive-tag", "archived", "Tag added to archived workflows")
+	SyncCmd.Flags().BoolP("yes", "y", false, "Don't ask for confirmation before pruning")
+	SyncCmd.Flags().Bool("mark-managed", true, "Tag synced workflows as managed by the CLI")
+	SyncCmd.Flags().Bool("include-unmanaged", false, "Also prune and refresh workflows that are not managed by the CLI")
 	SyncCmd.Flags().Bool("refresh", true, "Refresh the local state with the remote state")
-	SyncCmd.Flags().StringP("output", "o", "", "Output format for refreshed workflow files (json or yaml). If not specified, uses the existing file extension in the directory")
+	SyncCmd.Flags().StringP("output", "o", "", "Output format for refreshed workflow files (json, yaml or dir). If not specified, uses the existing file extension in the directory")
 	SyncCmd.Flags().Bool("all", false, "Refresh all workflows from n8n instance when refreshing, not just those in the directory")
+	SyncCmd.Flags().String("overlay", "", "Name of the overlay to apply from the overlays/<name>/ directory before uploading")
+	SyncCmd.Flags().Bool("substitute", false, "Replace ${VAR} placeholders with values from the environment or .env file before uploading")
+	SyncCmd.Flags().Bool("resolve-credentials", false, "Resolve node credentials by type and name on the target instance before uploading")
+	SyncCmd.Flags().String("credentials-map", "", "File mapping credential types and names to IDs on the target instance (implies --resolve-credentials)")
+	SyncCmd.Flags().Bool("force", false, "Overwrite workflows that were changed in n8n since they were last refreshed")
+	SyncCmd.Flags().Bool("theirs", false, "Keep workflows that were changed in n8n since they were last refreshed")
+	SyncCmd.Flags().Bool("merge", false, "Merge changes made in n8n to other nodes and connections than the local changes")
+	SyncCmd.Flags().String("match-by", MatchByID, "How files without a known workflow ID are matched to workflows in n8n (id, name or key)")
+
+	SyncCmd.Flags().Bool("backup", true, "Save the current remote version of every workflow before changing or deleting it")
+	SyncCmd.Flags().String("backup-dir", "", "Directory to store backups in (default <directory>/.n8n/backups)")
+	SyncCmd.Flags().Bool("atomic", false, "Revert every change made during the run if any step fails")
+	SyncCmd.Flags().Bool("watch", false, "Keep running and sync workflow files when they change")
+	SyncCmd.Flags().Duration("debounce", defaultWatchDebounce, "Time to wait for further changes before syncing in watch mode")
+
+	addSelectorFlags(SyncCmd)
+
+	SyncCmd.MarkFlagsMutuallyExclusive("force", "theirs", "merge")
 
 	// nolint:errcheck
 	SyncCmd.MarkFlagRequired("directory")
@@ -120,56 +183,186 @@ func SyncWorkflows(cmd *cobra.Command, args []string) error {
 	directory, _ := cmd.Flags().GetString("directory")
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
 	prune, _ := cmd.Flags().GetBool("prune")
-	refresh, _ := cmd.Flags().GetBool("refresh")
-	all, _ := cmd.Flags().GetBool("all")
+	backupEnabled, _ := cmd.Flags().GetBool("backup")
+	backupDir, _ := cmd.Flags().GetString("backup-dir")
+	atomic, _ := cmd.Flags().GetBool("atomic")
+	watch, _ := cmd.Flags().GetBool("watch")
 
 	if directory == "" {
 		return fmt.Errorf("directory is required")
 	}
 
-	files, err := os.ReadDir(directory)
-	if err != nil {
-		return fmt.Errorf("error reading directory: %w", err)
+	if watch && atomic {
+		return fmt.Errorf("--atomic can't be used with --watch")
 	}
 
-	apiKey := viper.Get("api_key").(string)
-	instanceURL := viper.Get("instance_url").(string)
+	apiClient := newClient(cmd)
+	client := apiClient
 
-	client := n8n.NewClient(instanceURL, apiKey)
+	var backup *Backup
+	if (backupEnabled || atomic) && !dryRun {
+		backupPath := newBackupPath(directory)
+		if backupDir != "" {
+			backupPath = filepath.Join(backupDir, filepath.Base(backupPath))
+		}
 
-	localWorkflowIDs := make(map[string]bool)
-	updatedWorkflows := make(map[string]bool)
+		if !backupEnabled {
+			tempDir, err := os.MkdirTemp("", "n8n-sync-backup-")
+			if err != nil {
+				return fmt.Errorf("error creating backup directory: %w", err)
+			}
+			defer func() {
+				if err := os.RemoveAll(tempDir); err != nil {
+					logger.Warn("Error removing temporary backup directory: %v", err)
+				}
+			}()
+			backupPath = filepath.Join(tempDir, filepath.Base(backupPath))
+		}
 
-	for _, file := range files {
-		if file.IsDir() {
-			continue
+		backup = NewBackup(backupPath)
+		client = &BackupClient{ClientInterface: client, Backup: backup}
+	}
+
+	restoreState := func() error { return nil }
+	if atomic && !dryRun {
+		var err error
+		if restoreState, err = snapshotStateFile(directory); err != nil {
+			return err
 		}
+	}
 
-		ext := strings.ToLower(filepath.Ext(file.Name()))
-		if ext == ".json" || ext == ".yaml" || ext == ".yml" {
-			filePath := filepath.Join(directory, file.Name())
+	_, err := SyncWorkflowsWithClient(cmd, client, directory, dryRun, prune)
 
-			if workflowID, err := ExtractWorkflowIDFromFile(filePath); err == nil && workflowID != "" {
-				localWorkflowIDs[workflowID] = true
-			}
+	if err != nil && atomic && backup != nil && !backup.Empty() {
+		return revertSync(cmd, apiClient, backup, restoreState, err)
+	}
 
-			result, err := ProcessWorkflowFile(client, cmd, filePath, dryRun, prune)
-			if err != nil {
-				return fmt.Errorf("error processing workflow file %s: %w", filePath, err)
-			}
+	if watch {
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		err = WatchWorkflows(ctx, cmd, client, directory, dryRun)
+	}
+
+	if backupEnabled && backup != nil && backup.Len() > 0 {
+		cmd.Printf("Saved the previous state of %d workflow(s) to %s, restore it with: n8n workflows rollback %s\n", backup.Len(), backup.Path, backup.Path)
+	}
+
+	return err
+}
+
+// revertSync restores the instance to its state before a failed sync run, using the backup taken during the run
+func revertSync(cmd *cobra.Command, client n8n.ClientInterface, backup *Backup, restoreState func() error, syncErr error) error {
+	cmd.Printf("Sync failed: %v\n", syncErr)
+	cmd.Printf("Reverting %d workflow(s) changed during this run...\n", backup.Len())
+
+	if err := RestoreBackup(client, cmd, backup, false, false); err != nil {
+		return fmt.Errorf("sync failed and could not be fully reverted, restore the remaining workflows with 'n8n workflows rollback %s': %w", backup.Path, err)
+	}
+
+	if err := restoreState(); err != nil {
+		return err
+	}
+
+	return fmt.Errorf("sync failed, all changes made during this run were reverted: %w", syncErr)
+}
+
+// SyncWorkflowsWithClient is the testable version of SyncWorkflows that accepts a client interface
+func SyncWorkflowsWithClient(cmd *cobra.Command, client n8n.ClientInterface, directory string, dryRun bool, prune bool) ([]WorkflowResult, error) {
+	refresh, _ := cmd.Flags().GetBool("refresh")
+	all, _ := cmd.Flags().GetBool("all")
+
+	localWorkflows, err := LoadWorkflowFiles(cmd, directory)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every workflow in the directory is kept when pruning, not only the selected ones
+	localWorkflowIDs := make(map[string]bool)
+	for _, local := range localWorkflows {
+		if local.Workflow.Id != nil && *local.Workflow.Id != "" {
+			localWorkflowIDs[*local.Workflow.Id] = true
+		}
+	}
+
+	selector, err := NewWorkflowSelector(cmd, directory)
+	if err != nil {
+		return nil, err
+	}
+	if !selector.IsEmpty() {
+		total := len(localWorkflows)
+		localWorkflows = selector.SelectWorkflows(localWorkflows)
+		cmd.Printf("Selected %d of %d workflow file(s)\n", len(localWorkflows), total)
+	}
+
+	if err := resolveWorkflowCredentials(client, cmd, localWorkflows); err != nil {
+		return nil, err
+	}
+
+	localWorkflows, err = SortWorkflowsByDependencies(client, localWorkflows)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := MatchRemoteWorkflows(client, cmd, localWorkflows)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := LoadWorkflowState(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedWorkflows := make(map[string]bool)
+	targetIDs := make(map[string]string)
+	var results []WorkflowResult
+
+	for i := range localWorkflows {
+		local := &localWorkflows[i]
+		RewriteWorkflowReferences(&local.Workflow, targetIDs)
 
+		sourceID := ""
+		if local.Workflow.Id != nil {
+			sourceID = *local.Workflow.Id
+		}
+		if remoteID, matched := matches[local.FilePath]; matched {
+			local.Workflow.Id = &remoteID
+		}
+
+		result, err := syncLocalWorkflow(client, cmd, local, dryRun, state)
+		if err != nil {
 			if result.WorkflowID != "" {
-				updatedWorkflows[result.WorkflowID] = true
+				results = append(results, result)
+			}
+			return results, err
+		}
+		results = append(results, result)
+
+		if result.WorkflowID != "" {
+			updatedWorkflows[result.WorkflowID] = true
+			localWorkflowIDs[result.WorkflowID] = true
+			if sourceID != "" && sourceID != result.WorkflowID {
+				targetIDs[sourceID] = result.WorkflowID
 			}
 		}
 	}
 
 	if prune {
 		if err := PruneWorkflows(client, cmd, localWorkflowIDs); err != nil {
-			return fmt.Errorf("error pruning workflows: %w", err)
+			return results, fmt.Errorf("error pruning workflows: %w", err)
 		}
 	}
 
+	if refresh && usesRendering(cmd) {
+		cmd.Println("Skipping refresh of local files, overlays and substitution would write environment specific values into them")
+		refresh = false
+	}
+
 	if refresh && !dryRun && len(updatedWorkflows) > 0 {
 		cmd.Println("Refreshing local workflow files with remote state...")
 
@@ -183,13 +376,40 @@ func SyncWorkflows(cmd *cobra.Command, args []string) error {
 		}
 
 		if err := RefreshWorkflowsWithClient(cmd, client, directory, false, overwrite, output, minimal, all); err != nil {
-			return fmt.Errorf("error refreshing workflows after sync: %w", err)
+			return results, fmt.Errorf("error refreshing workflows after sync: %w", err)
 		}
 
 		cmd.Println("Local workflow files updated successfully with remote state")
 	}
 
-	return nil
+	return results, nil
+}
+
+// syncLocalWorkflow uploads a local workflow, records its remote version in the state and marks it as
+// managed when --mark-managed is set
+func syncLocalWorkflow(client n8n.ClientInterface, cmd *cobra.Command, local *LocalWorkflow, dryRun bool, state *WorkflowState) (WorkflowResult, error) {
+	markAsManaged, _ := cmd.Flags().GetBool("mark-managed")
+
+	declaresTags := local.Workflow.Tags != nil && len(*local.Workflow.Tags) > 0
+	if markAsManaged {
+		markManaged(&local.Workflow)
+	}
+
+	result, err := ProcessWorkflow(client, cmd, &local.Workflow, local.FilePath, dryRun, state)
+	if saveErr := state.Save(); saveErr != nil {
+		return result, saveErr
+	}
+	if err != nil {
+		return result, fmt.Errorf("error processing workflow file %s: %w", local.FilePath, err)
+	}
+
+	if markAsManaged && !declaresTags && !dryRun && result.WorkflowID != "" {
+		if err := ensureManagedTag(client, cmd, result.WorkflowID, result.Name); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
 }
 
 // WorkflowResult contains the result of processing a workflow file
@@ -199,77 +419,114 @@ type WorkflowResult struct {
 	FilePath   string
 	Created    bool
 	Updated    bool
+	// Remote is the workflow as returned by n8n after it was created or updated
+	Remote *n8n.Workflow
 }
 
-// ProcessWorkflowFile processes a workflow file and uploads it to n8n
-func ProcessWorkflowFile(client n8n.ClientInterface, cmd *cobra.Command, filePath string, dryRun bool, prune bool) (WorkflowResult, error) {
-	var workflow n8n.Workflow
-	var err error
-	result := WorkflowResult{
-		FilePath: filePath,
-	}
-
-	logger.Debug("Processing file: %s", filePath)
+// LocalWorkflow is a workflow decoded from a file in the workflows directory
+type LocalWorkflow struct {
+	FilePath string
+	Workflow n8n.Workflow
+}
 
-	content, err := os.ReadFile(filePath)
+// workflowFilePaths returns the paths of the JSON and YAML workflow files and the workflow directories in a directory
+func workflowFilePaths(directory string) ([]string, error) {
+	files, err := os.ReadDir(directory)
 	if err != nil {
-		return result, fmt.Errorf("error reading file: %w", err)
+		return nil, fmt.Errorf("error reading directory: %w", err)
 	}
 
-	logger.Debug("File size: %d bytes", len(content))
-	if len(content) > 0 {
-		preview := string(content)
-		if len(preview) > 100 {
-			preview = preview[:100] + "..."
+	var paths []string
+	for _, file := range files {
+		filePath := filepath.Join(directory, file.Name())
+		if file.IsDir() {
+			if isWorkflowDirectory(filePath) {
+				paths = append(paths, filePath)
+			}
+			continue
+		}
+
+		if isWorkflowFile(file.Name()) {
+			paths = append(paths, filePath)
 		}
-		logger.Debug("Content preview: %s", preview)
 	}
 
-	ext := strings.ToLower(filepath.Ext(filePath))
-	filename := filepath.Base(filePath)
+	return paths, nil
+}
 
-	switch ext {
-	case ".json":
-		logger.Debug("Parsing as JSON: %s", filename)
-		if err = json.Unmarshal(content, &workflow); err != nil {
-			logger.Debug("JSON parsing error: %v", err)
-			return result, fmt.Errorf("error parsing JSON workflow: %w", err)
-		}
-	case ".yaml", ".yml":
-		logger.Debug("Parsing as YAML: %s", filename)
+// LoadWorkflowFiles decodes every workflow file in a directory, so problems are reported before anything is uploaded
+func LoadWorkflowFiles(cmd *cobra.Command, directory string) ([]LocalWorkflow, error) {
+	paths, err := workflowFilePaths(directory)
+	if err != nil {
+		return nil, err
+	}
 
-		decoder := n8n.NewWorkflowDecoder()
-		workflow, err = decoder.DecodeFromYAML(content)
+	localWorkflows := make([]LocalWorkflow, 0, len(paths))
+	for _, filePath := range paths {
+		logger.Debug("Processing file: %s", filePath)
+
+		workflow, err := decodeWorkflowFile(cmd, filePath)
 		if err != nil {
-			logger.Debug("YAML parsing error: %v", err)
-			return result, fmt.Errorf("error parsing YAML workflow: %w", err)
+			return nil, workflowFileError("error processing workflow file", filePath, err)
 		}
-	default:
-		return result, fmt.Errorf("unsupported file format: %s", ext)
+
+		localWorkflows = append(localWorkflows, LocalWorkflow{FilePath: filePath, Workflow: workflow})
 	}
 
-	result.Name = workflow.Name
+	return localWorkflows, nil
+}
+
+// ProcessWorkflowFile processes a workflow file and uploads it to n8n
+func ProcessWorkflowFile(client n8n.ClientInterface, cmd *cobra.Command, filePath string, dryRun bool, prune bool) (WorkflowResult, error) {
+	logger.Debug("Processing file: %s", filePath)
+
+	workflow, err := decodeWorkflowFile(cmd, filePath)
+	if err != nil {
+		logger.Debug("Workflow parsing error: %v", err)
+		return WorkflowResult{FilePath: filePath}, err
+	}
+
+	return ProcessWorkflow(client, cmd, &workflow, filePath, dryRun, nil)
+}
+
+// ProcessWorkflow uploads a decoded workflow to n8n, creating or updating it as needed.
+// When a state is given, workflows changed in n8n since they were last refreshed are not overwritten
+// and the uploaded versions are recorded in it.
+func ProcessWorkflow(client n8n.ClientInterface, cmd *cobra.Command, workflow *n8n.Workflow, filePath string, dryRun bool, state *WorkflowState) (WorkflowResult, error) {
+	var err error
+	result := WorkflowResult{
+		FilePath: filePath,
+		Name:     workflow.Name,
+	}
+
+	filename := filepath.Base(filePath)
 
 	var remoteWorkflow *n8n.Workflow
 
 	if workflow.Id == nil || *workflow.Id == "" {
-		result, err = CreateWorkflow(client, cmd, &workflow, filename, dryRun, result)
+		result, err = CreateWorkflow(client, cmd, workflow, filename, dryRun, result)
 		if err != nil {
 			return result, err
 		}
-		return processActivationAndTags(client, cmd, &workflow, result, dryRun)
+		if err := recordWorkflowState(state, result.Remote); err != nil {
+			return result, err
+		}
+		return processActivationAndTags(client, cmd, workflow, result, dryRun)
 	}
 
 	remoteWorkflow, err = client.GetWorkflow(*workflow.Id)
 	if err != nil {
-		result, err = CreateWorkflowWithID(client, cmd, &workflow, filename, dryRun, result)
+		result, err = CreateWorkflowWithID(client, cmd, workflow, filename, dryRun, result)
 		if err != nil {
 			return result, err
 		}
-		return processActivationAndTags(client, cmd, &workflow, result, dryRun)
+		if err := recordWorkflowState(state, result.Remote); err != nil {
+			return result, err
+		}
+		return processActivationAndTags(client, cmd, workflow, result, dryRun)
 	}
 
-	workflowChanges := DetectWorkflowChanges(&workflow, remoteWorkflow)
+	workflowChanges := DetectWorkflowChanges(workflow, remoteWorkflow)
 	if !workflowChanges.NeedsUpdate {
 		result.WorkflowID = *remoteWorkflow.Id
 		status := "No content changes for"
@@ -277,19 +534,50 @@ func ProcessWorkflowFile(client n8n.ClientInterface, cmd *cobra.Command, filePat
 			status = "No changes needed for"
 		}
 		cmd.Printf("%s workflow '%s' (ID: %s) from %s\n", status, workflow.Name, *workflow.Id, filename)
-		return processActivationAndTags(client, cmd, &workflow, result, dryRun)
+		if !dryRun {
+			if err := recordWorkflowState(state, remoteWorkflow); err != nil {
+				return result, err
+			}
+		}
+		return processActivationAndTags(client, cmd, workflow, result, dryRun)
+	}
+
+	if state != nil {
+		upload, err := checkRemoteConflict(cmd, state, workflow, remoteWorkflow)
+		if err != nil {
+			return result, err
+		}
+		if !upload {
+			result.WorkflowID = *remoteWorkflow.Id
+			return result, nil
+		}
 	}
 
-	result, err = UpdateWorkflow(client, cmd, &workflow, filename, dryRun, result)
+	result, err = UpdateWorkflow(client, cmd, workflow, filename, dryRun, result)
 	if err != nil {
 		return result, err
 	}
+	if err := recordWorkflowState(state, result.Remote); err != nil {
+		return result, err
+	}
+
+	return processActivationAndTags(client, cmd, workflow, result, dryRun)
+}
 
-	return processActivationAndTags(client, cmd, &workflow, result, dryRun)
+// recordWorkflowState records the remote version of a workflow in the state, if there is a state and a workflow
+func recordWorkflowState(state *WorkflowState, remote *n8n.Workflow) error {
+	if state == nil || remote == nil {
+		return nil
+	}
+	return state.Record(*remote)
 }
 
-// ExtractWorkflowIDFromFile reads a workflow file and extracts the workflow ID if present
+// ExtractWorkflowIDFromFile reads a workflow file or directory and extracts the workflow ID if present
 func ExtractWorkflowIDFromFile(filePath string) (string, error) {
+	if isWorkflowDirectory(filePath) {
+		filePath = filepath.Join(filePath, n8n.DirectoryWorkflowFile)
+	}
+
 	content, err := os.ReadFile(filePath)
 	if err != nil {
 		return "", fmt.Errorf("error reading file: %w", err)
@@ -299,8 +587,11 @@ func ExtractWorkflowIDFromFile(filePath string) (string, error) {
 
 	switch ext {
 	case ".json":
-		var workflow n8n.Workflow
-		if err = json.Unmarshal(content, &workflow); err != nil {
+		workflow, err := n8n.NewWorkflowDecoder().DecodeFromJSON(content)
+		if err != nil {
+			if inWorkflowFile(err, filePath) {
+				return "", err
+			}
 			return "", fmt.Errorf("error parsing JSON workflow: %w", err)
 		}
 
@@ -310,6 +601,10 @@ func ExtractWorkflowIDFromFile(filePath string) (string, error) {
 	case ".yaml", ".yml":
 		var workflowMap map[string]interface{}
 		if err = yaml.Unmarshal(content, &workflowMap); err != nil {
+			err = n8n.LocateYAMLError(content, err)
+			if inWorkflowFile(err, filePath) {
+				return "", err
+			}
 			return "", fmt.Errorf("error parsing YAML workflow: %w", err)
 		}
 
@@ -323,50 +618,6 @@ func ExtractWorkflowIDFromFile(filePath string) (string, error) {
 	return "", nil
 }
 
-// PruneWorkflows removes workflows from n8n that are not in the local workflow files
-func PruneWorkflows(client n8n.ClientInterface, cmd *cobra.Command, localWorkflowIDs map[string]bool) error {
-	limit := n8n.MaxLimit
-	workflowList, err := client.GetWorkflows(&limit)
-	if err != nil {
-		return fmt.Errorf("error getting workflows from n8n: %w", err)
-	}
-
-	if workflowList == nil || workflowList.Data == nil {
-		return fmt.Errorf("no workflows found in n8n instance")
-	}
-
-	dryRun := false
-	if cmd.Flags().Changed("dry-run") {
-		dryRun, _ = cmd.Flags().GetBool("dry-run")
-	}
-
-	for _, workflow := range *workflowList.Data {
-		if workflow.Id == nil || *workflow.Id == "" {
-			continue
-		}
-
-		if !localWorkflowIDs[*workflow.Id] {
-			workflowID := *workflow.Id
-			workflowName := workflow.Name
-
-			dryRunMsg := fmt.Sprintf("Would delete workflow '%s' (ID: %s) that was not in local files", workflowName, workflowID)
-
-			err := ExecuteOrDryRun(cmd, dryRun, dryRunMsg, func() (string, error) {
-				if err := client.DeleteWorkflow(workflowID); err != nil {
-					return "", fmt.Errorf("error deleting workflow %s (%s): %w", workflowName, workflowID, err)
-				}
-				return fmt.Sprintf("Deleted workflow '%s' (ID: %s) that was not in local files", workflowName, workflowID), nil
-			})
-
-			if err != nil {
-				return err
-			}
-		}
-	}
-
-	return nil
-}
-
 // ExecuteOrDryRun is a helper function that either performs an action or shows what would happen
 // based on whether dry run mode is enabled
 func ExecuteOrDryRun(cmd *cobra.Command, dryRun bool, dryRunMsg string, fn func() (string, error)) error {
@@ -409,12 +660,15 @@ func DetectWorkflowChanges(local *n8n.Workflow, remote *n8n.Workflow) WorkflowCh
 		return changes
 	}
 
-	localCopy := *local
+	// Fields that are not sent to n8n can't be synced, so they don't count as changes
+	policy := fieldPolicy()
+
+	localCopy := policy.Apply(*local)
 	localCopy.Id = nil
 	localCopy.Active = nil
 	localCopy.Tags = nil
 
-	remoteCopy := *remote
+	remoteCopy := policy.Apply(*remote)
 	remoteCopy.Id = nil
 	remoteCopy.Active = nil
 	remoteCopy.Tags = nil
@@ -530,6 +784,7 @@ func CreateWorkflow(client n8n.ClientInterface, cmd *cobra.Command, workflow *n8
 		}
 		result.Created = true
 		result.WorkflowID = *w.Id
+		result.Remote = w
 		return fmt.Sprintf("Created workflow '%s' (ID: %s) from %s", w.Name, *w.Id, filename), nil
 	})
 
@@ -547,6 +802,7 @@ func CreateWorkflowWithID(client n8n.ClientInterface, cmd *cobra.Command, workfl
 		}
 		result.Created = true
 		result.WorkflowID = *w.Id
+		result.Remote = w
 		return fmt.Sprintf("Created workflow '%s' (ID: %s) from %s", w.Name, *w.Id, filename), nil
 	})
 
@@ -564,6 +820,7 @@ func UpdateWorkflow(client n8n.ClientInterface, cmd *cobra.Command, workflow *n8
 		}
 		result.Updated = true
 		result.WorkflowID = *w.Id
+		result.Remote = w
 		return fmt.Sprintf("Updated workflow '%s' (ID: %s) from %s", w.Name, *w.Id, filename), nil
 	})
 
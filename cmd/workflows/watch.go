@@ -0,0 +1,437 @@
+/*
+Copyright © 2025 Eden Reich
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cobra"
+)
+
+// defaultWatchDebounce is how long watch mode waits for further changes before syncing
+const defaultWatchDebounce = 500 * time.Millisecond
+
+// WatchWorkflows watches a directory for changed workflow files and syncs them until the context is cancelled.
+// Changes are debounced, files whose content didn't change are skipped, and errors are printed without stopping.
+func WatchWorkflows(ctx context.Context, cmd *cobra.Command, client n8n.ClientInterface, directory string, dryRun bool) error {
+	debounce, _ := cmd.Flags().GetDuration("debounce")
+	if debounce <= 0 {
+		debounce = defaultWatchDebounce
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("error creating file watcher: %w", err)
+	}
+	defer func() {
+		_ = watcher.Close()
+	}()
+
+	if err := watcher.Add(directory); err != nil {
+		return fmt.Errorf("error watching directory %s: %w", directory, err)
+	}
+
+	filePaths, err := workflowFilePaths(directory)
+	if err != nil {
+		return err
+	}
+
+	hashes := make(map[string][32]byte)
+	for _, filePath := range filePaths {
+		if hash, ok := workflowFileHash(filePath); ok {
+			hashes[filePath] = hash
+		}
+
+		// Workflow directories and code extracted from workflow files live in subdirectories
+		dirs := []string{filepath.Join(directory, codeDir(filePath))}
+		if isDirectoryFormat(filePath) {
+			dirs = []string{filePath, filepath.Join(filePath, n8n.DirectoryNodesDir)}
+		}
+		for _, dir := range dirs {
+			if info, err := os.Stat(dir); err == nil && info.IsDir() {
+				if err := watcher.Add(dir); err != nil {
+					return fmt.Errorf("error watching directory %s: %w", dir, err)
+				}
+			}
+		}
+	}
+
+	cmd.Printf("Watching %s for changes, press Ctrl+C to stop\n", directory)
+
+	pending := make(map[string]bool)
+	var debounced <-chan time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+				continue
+			}
+			filePath := filepath.Clean(event.Name)
+
+			// Directories created while watching hold new workflow directories, their nodes or extracted code
+			if info, err := os.Stat(filePath); err == nil && info.IsDir() {
+				if !event.Has(fsnotify.Create) || !watchNewDirectory(watcher, directory, filePath) {
+					continue
+				}
+				workflowDir := filePath
+				if filepath.Base(filePath) == n8n.DirectoryNodesDir {
+					workflowDir = filepath.Dir(filePath)
+				}
+				if isWorkflowDirectory(workflowDir) {
+					pending[workflowDir] = true
+					debounced = time.After(debounce)
+				}
+				continue
+			}
+
+			if workflowDir, ok := workflowDirectoryForFile(filePath); ok {
+				filePath = workflowDir
+			} else if workflowFile, ok := workflowFileForCode(filePath); ok {
+				filePath = workflowFile
+			} else if !isWorkflowFile(filePath) || filepath.Dir(filePath) != filepath.Clean(directory) {
+				continue
+			}
+			pending[filePath] = true
+			debounced = time.After(debounce)
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			cmd.Printf("Error watching %s: %v\n", directory, err)
+
+		case <-debounced:
+			debounced = nil
+
+			var changed []string
+			for filePath := range pending {
+				hash, ok := workflowFileHash(filePath)
+				if !ok || hash == hashes[filePath] {
+					continue
+				}
+				changed = append(changed, filePath)
+			}
+			pending = make(map[string]bool)
+
+			if len(changed) == 0 {
+				continue
+			}
+
+			SyncWorkflowFiles(cmd, client, directory, changed, dryRun)
+
+			// Record the content after syncing, so IDs written back into new files don't trigger another sync
+			for _, filePath := range changed {
+				if hash, ok := workflowFileHash(filePath); ok {
+					hashes[filePath] = hash
+				}
+			}
+		}
+	}
+}
+
+// watchNewDirectory watches a directory created at the top level of the workflows directory and its nodes
+// directory, or a nodes directory created in one of them. Hidden directories, such as .n8n, are not
+// watched. It reports whether the directory is watched.
+func watchNewDirectory(watcher *fsnotify.Watcher, directory string, dir string) bool {
+	directory = filepath.Clean(directory)
+	parent := filepath.Dir(dir)
+	if strings.HasPrefix(filepath.Base(dir), ".") {
+		return false
+	}
+	if parent != directory && (filepath.Base(dir) != n8n.DirectoryNodesDir || filepath.Dir(parent) != directory) {
+		return false
+	}
+
+	if err := watcher.Add(dir); err != nil {
+		return false
+	}
+
+	nodesDir := filepath.Join(dir, n8n.DirectoryNodesDir)
+	if info, err := os.Stat(nodesDir); err == nil && info.IsDir() {
+		if err := watcher.Add(nodesDir); err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// SyncWorkflowFiles syncs the given workflow files of a directory, printing the errors of every file
+// instead of stopping at the first one. It returns the number of files that failed.
+// The IDs of created workflows are written back into their files, unless --refresh=false is set or
+// the files are rendered with overlays or substitution.
+func SyncWorkflowFiles(cmd *cobra.Command, client n8n.ClientInterface, directory string, filePaths []string, dryRun bool) int {
+	sort.Strings(filePaths)
+
+	state, err := LoadWorkflowState(directory)
+	if err != nil {
+		cmd.Printf("Error: %v\n", err)
+		return len(filePaths)
+	}
+
+	refresh := true
+	if cmd.Flags().Lookup("refresh") != nil {
+		refresh, _ = cmd.Flags().GetBool("refresh")
+	}
+	writeBack := refresh && !dryRun && !usesRendering(cmd)
+
+	selector, err := NewWorkflowSelector(cmd, directory)
+	if err != nil {
+		cmd.Printf("Error: %v\n", err)
+		return len(filePaths)
+	}
+
+	failed := 0
+	for _, filePath := range filePaths {
+		workflow, err := decodeWorkflowFile(cmd, filePath)
+		if err != nil {
+			cmd.Printf("Error in %s: %v\n", filepath.Base(filePath), err)
+			failed++
+			continue
+		}
+		if !selector.Matches(workflow, filePath) {
+			continue
+		}
+
+		local := []LocalWorkflow{{FilePath: filePath, Workflow: workflow}}
+		if err := resolveWorkflowCredentials(client, cmd, local); err != nil {
+			cmd.Printf("Error in %s: %v\n", filepath.Base(filePath), err)
+			failed++
+			continue
+		}
+
+		matches, err := MatchRemoteWorkflows(client, cmd, local)
+		if err != nil {
+			cmd.Printf("Error in %s: %v\n", filepath.Base(filePath), err)
+			failed++
+			continue
+		}
+		if remoteID, matched := matches[filePath]; matched {
+			local[0].Workflow.Id = &remoteID
+		}
+
+		result, err := syncLocalWorkflow(client, cmd, &local[0], dryRun, state)
+		if err != nil {
+			cmd.Printf("Error in %s: %v\n", filepath.Base(filePath), err)
+			failed++
+			continue
+		}
+
+		if result.Created && writeBack && result.Remote != nil {
+			if err := writeWorkflowID(filePath, *result.Remote, canonicalOutput(cmd)); err != nil {
+				cmd.Printf("Error in %s: %v\n", filepath.Base(filePath), err)
+				failed++
+			}
+		}
+	}
+
+	return failed
+}
+
+// writeWorkflowID rewrites a workflow file with the workflow as it was created in n8n, so it carries its ID.
+// Comments and formatting of YAML files are kept, unless the file is written in canonical form.
+func writeWorkflowID(filePath string, remote n8n.Workflow, canonical bool) error {
+	if canonical {
+		remote = n8n.CanonicalWorkflow(remote)
+	}
+
+	if isDirectoryFormat(filePath) {
+		files, err := serializeWorkflowDirectory(remote, true)
+		if err != nil {
+			return err
+		}
+		if !canonical {
+			files = preserveYAMLDirectoryFormatting(filePath, files)
+		}
+		if err := writeWorkflowDirectory(filePath, files); err != nil {
+			return fmt.Errorf("error writing workflow ID to file: %w", err)
+		}
+		return nil
+	}
+
+	extractCode := fileHasCodeReferences(filePath)
+	content, codeFiles, err := serializeWorkflow(remote, filePath, true, extractCode)
+	if err != nil {
+		return err
+	}
+	if !canonical && workflowFormat(filePath) == "yaml" {
+		content = preserveYAMLFormatting(filePath, content)
+	}
+
+	if err := writeWorkflowFile(filePath, content, codeFiles, extractCode); err != nil {
+		return fmt.Errorf("error writing workflow ID to file: %w", err)
+	}
+
+	return nil
+}
+
+// isWorkflowFile reports whether a path looks like a workflow file
+func isWorkflowFile(filePath string) bool {
+	ext := strings.ToLower(filepath.Ext(filePath))
+	return ext == ".json" || ext == ".yaml" || ext == ".yml"
+}
+
+// defaultPollInterval is how often refresh watch mode checks n8n for changed workflows
+const defaultPollInterval = 5 * time.Second
+
+// WatchRemoteWorkflows polls n8n for workflows changed since they were last refreshed or synced and rewrites
+// their files until the context is cancelled. Files with local changes that weren't synced yet are not
+// overwritten, refreshing them is paused until the changes are synced or reverted.
+func WatchRemoteWorkflows(ctx context.Context, cmd *cobra.Command, client n8n.ClientInterface, directory string, dryRun bool, output string, minimal bool, all bool) error {
+	interval, _ := cmd.Flags().GetDuration("poll-interval")
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+
+	cmd.Printf("Watching n8n for workflow changes every %s, press Ctrl+C to stop\n", interval)
+
+	seen := make(map[string]time.Time)
+	paused := make(map[string]bool)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := refreshChangedWorkflows(cmd, client, directory, dryRun, output, minimal, all, seen, paused); err != nil {
+				cmd.Printf("Error: %v\n", err)
+			}
+		}
+	}
+}
+
+// refreshChangedWorkflows rewrites the files of the workflows whose updatedAt changed since they were last
+// recorded in the state or seen by the watch
+func refreshChangedWorkflows(cmd *cobra.Command, client n8n.ClientInterface, directory string, dryRun bool, output string,
+	minimal bool, all bool, seen map[string]time.Time, paused map[string]bool) error {
+
+	workflowList, err := client.GetAllWorkflows()
+	if err != nil {
+		return fmt.Errorf("error fetching workflows: %w", err)
+	}
+	if workflowList == nil || workflowList.Data == nil {
+		return nil
+	}
+
+	localFiles, err := extractLocalWorkflows(directory)
+	if err != nil {
+		return err
+	}
+
+	// Reloaded on every poll, a sync running next to the watch records the versions it uploaded
+	state, err := LoadWorkflowState(directory)
+	if err != nil {
+		return err
+	}
+
+	selector, err := NewWorkflowSelector(cmd, directory)
+	if err != nil {
+		return err
+	}
+
+	unmanaged := includeUnmanaged(cmd)
+	for _, remote := range *workflowList.Data {
+		if remote.Id == nil || *remote.Id == "" || remote.UpdatedAt == nil {
+			continue
+		}
+		workflowID := *remote.Id
+
+		filePath, isLocal := localFiles[workflowID]
+		if !isLocal && !(all && (unmanaged || IsManaged(remote))) {
+			continue
+		}
+		if !selector.Matches(remote, filePath) {
+			continue
+		}
+
+		entry, recorded := state.Workflows[workflowID]
+		if recorded && entry.UpdatedAt != nil && entry.UpdatedAt.Equal(*remote.UpdatedAt) {
+			continue
+		}
+		if seenAt, ok := seen[workflowID]; ok && seenAt.Equal(*remote.UpdatedAt) {
+			continue
+		}
+
+		if isLocal && recorded && entry.Base != nil {
+			modified, err := hasLocalChanges(cmd, filePath, *entry.Base)
+			if err != nil {
+				return err
+			}
+			if modified {
+				if !paused[workflowID] {
+					cmd.Printf("Paused refreshing workflow '%s' (ID: %s) changed in n8n, %s has local changes: sync or revert them to resume\n",
+						remote.Name, workflowID, filepath.Base(filePath))
+					paused[workflowID] = true
+				}
+				continue
+			}
+		}
+		delete(paused, workflowID)
+
+		if err := processWorkflow(cmd, remote, localFiles, directory, dryRun, false, output, minimal); err != nil {
+			return err
+		}
+
+		seen[workflowID] = *remote.UpdatedAt
+		if !dryRun {
+			if err := state.Record(remote); err != nil {
+				return err
+			}
+		}
+	}
+
+	return state.Save()
+}
+
+// hasLocalChanges reports whether a workflow file differs from the version it was last refreshed or synced with
+func hasLocalChanges(cmd *cobra.Command, filePath string, base n8n.Workflow) (bool, error) {
+	local, err := decodeWorkflowFile(cmd, filePath)
+	if err != nil {
+		// A file that can't be decoded is being edited
+		return true, nil
+	}
+
+	parts, err := n8n.ChangedParts(base, local)
+	if err != nil {
+		return false, err
+	}
+
+	return len(parts) > 0, nil
+}
@@ -27,6 +27,19 @@ func (r *OSFileReader) Open(name string) (io.ReadCloser, error) {
 // DefaultFileReader is the default file reader
 var DefaultFileReader FileReader = &OSFileReader{}
 
+// envFileValues holds every variable read from the .env file, not only the N8N_ prefixed ones
+var envFileValues = make(map[string]string)
+
+// LookupEnv returns the value of a variable from the environment, falling back to the .env file
+func LookupEnv(key string) (string, bool) {
+	if value, ok := os.LookupEnv(key); ok {
+		return value, true
+	}
+
+	value, ok := envFileValues[key]
+	return value, ok
+}
+
 // LoadEnvFile loads environment variables from a .env file if it exists
 func LoadEnvFile() {
 	LoadEnvFileWithReader(DefaultFileReader, viper.GetViper())
@@ -34,6 +47,8 @@ func LoadEnvFile() {
 
 // LoadEnvFileWithReader loads environment variables from a .env file using the provided reader
 func LoadEnvFileWithReader(reader FileReader, v *viper.Viper) {
+	envFileValues = make(map[string]string)
+
 	envFile, err := reader.Open(".env")
 	if err != nil {
 		cwd, _ := os.Getwd()
@@ -65,6 +80,7 @@ func LoadEnvFileWithReader(reader FileReader, v *viper.Viper) {
 		value := strings.TrimSpace(parts[1])
 
 		value = strings.Trim(value, `"'`)
+		envFileValues[key] = value
 
 		if strings.HasPrefix(key, "N8N_") {
 			viperKey := strings.ToLower(strings.TrimPrefix(key, "N8N_"))
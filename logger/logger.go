@@ -71,6 +71,27 @@ func Error(format string, args ...interface{}) {
 	}
 }
 
+// Infow logs an info message with structured key-value pairs
+func Infow(msg string, keysAndValues ...interface{}) {
+	if logger != nil {
+		logger.Infow(msg, keysAndValues...)
+	}
+}
+
+// Warnw logs a warning message with structured key-value pairs
+func Warnw(msg string, keysAndValues ...interface{}) {
+	if logger != nil {
+		logger.Warnw(msg, keysAndValues...)
+	}
+}
+
+// Errorw logs an error message with structured key-value pairs
+func Errorw(msg string, keysAndValues ...interface{}) {
+	if logger != nil {
+		logger.Errorw(msg, keysAndValues...)
+	}
+}
+
 // Fatal logs a fatal message and exits
 func Fatal(format string, args ...interface{}) {
 	if logger != nil {
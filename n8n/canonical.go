@@ -0,0 +1,84 @@
+package n8n
+
+import (
+	"math"
+	"sort"
+	"strconv"
+)
+
+// CanonicalWorkflow returns a copy of a workflow in canonical form, so encoding the same workflow always
+// gives the same output no matter the order the API returned it in. The workflow is cleaned, volatile fields
+// like static data and node timestamps are removed, nodes are sorted by name and float32 artifacts such as
+// 1.100000023841858 are turned back into the number they stand for. Map keys are sorted by the encoders.
+func CanonicalWorkflow(workflow Workflow) Workflow {
+	canonical := CleanWorkflow(workflow)
+	canonical.StaticData = nil
+
+	nodes := make([]Node, len(workflow.Nodes))
+	for i, node := range workflow.Nodes {
+		node.CreatedAt = nil
+		node.UpdatedAt = nil
+		if node.Parameters != nil {
+			parameters := normalizeNumbers(*node.Parameters).(map[string]interface{})
+			node.Parameters = &parameters
+		}
+		if node.Credentials != nil {
+			credentials := normalizeNumbers(*node.Credentials).(map[string]interface{})
+			node.Credentials = &credentials
+		}
+		nodes[i] = node
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodeSortKey(nodes[i]) < nodeSortKey(nodes[j])
+	})
+	canonical.Nodes = nodes
+
+	canonical.Connections = normalizeNumbers(canonical.Connections).(map[string]interface{})
+
+	return canonical
+}
+
+// nodeSortKey returns the key nodes are sorted by, node names are unique within a workflow
+func nodeSortKey(node Node) string {
+	if node.Name == nil {
+		return ""
+	}
+	return *node.Name
+}
+
+// normalizeNumbers returns a copy of a decoded value with float32 artifacts replaced by the shortest number
+// that has the same float32 value. Numbers that aren't float32 values are kept as they are.
+func normalizeNumbers(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		normalized := make(map[string]interface{}, len(typed))
+		for key, item := range typed {
+			normalized[key] = normalizeNumbers(item)
+		}
+		return normalized
+	case []interface{}:
+		normalized := make([]interface{}, len(typed))
+		for i, item := range typed {
+			normalized[i] = normalizeNumbers(item)
+		}
+		return normalized
+	case float64:
+		return normalizeFloat(typed)
+	}
+	return value
+}
+
+// normalizeFloat turns a float64 that was widened from a float32, like 1.100000023841858, into the number
+// the float32 stands for, like 1.1. Integers are kept, they have no float32 artifacts, and so is any number
+// whose shortest float32 form stands for another float32 value.
+func normalizeFloat(value float64) float64 {
+	if value == math.Trunc(value) || float64(float32(value)) != value {
+		return value
+	}
+
+	normalized, err := strconv.ParseFloat(strconv.FormatFloat(value, 'g', -1, 32), 64)
+	if err != nil || float32(normalized) != float32(value) {
+		return value
+	}
+	return normalized
+}
@@ -3,6 +3,7 @@ package n8n
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -17,12 +18,16 @@ import (
 // MaxLimit is the maximum number of workflows that can be fetched per request (per n8n docs)
 const MaxLimit = 250
 
+// ErrWorkflowNotFound is wrapped by the error of GetWorkflow when n8n has no workflow with the ID
+var ErrWorkflowNotFound = errors.New("workflow not found")
+
 // Client is a simple client for interacting with n8n API
 type Client struct {
 	baseURL  string
 	apiToken string
 	client   *http.Client
 	logger   *zap.SugaredLogger
+	fields   FieldPolicy
 }
 
 // NewClient creates a new n8n client
@@ -50,9 +55,17 @@ func NewClient(baseURL, apiToken string) *Client {
 		apiToken: apiToken,
 		client:   &http.Client{},
 		logger:   logger,
+		fields:   DefaultFieldPolicy(),
 	}
 }
 
+// WithFieldPolicy sets the policy that decides which fields outside the API schema are sent to n8n
+// when workflows are created or updated
+func (c *Client) WithFieldPolicy(policy FieldPolicy) *Client {
+	c.fields = policy
+	return c
+}
+
 // logDebug logs a debug message
 func (c *Client) logDebug(format string, args ...interface{}) {
 	c.logger.Debugf(format, args...)
@@ -62,18 +75,46 @@ func (c *Client) logDebug(format string, args ...interface{}) {
 // If limit is nil, uses the API's default (100)
 // If limit is provided, returns up to that many workflows (max MaxLimit)
 func (c *Client) GetWorkflows(limit *int) (*WorkflowList, error) {
+	return c.getWorkflowsPage(limit, "")
+}
+
+// GetAllWorkflows fetches every workflow of the instance, following the cursor from page to page
+func (c *Client) GetAllWorkflows() (*WorkflowList, error) {
+	limit := MaxLimit
+	workflows := []Workflow{}
+	cursor := ""
+	for {
+		page, err := c.getWorkflowsPage(&limit, cursor)
+		if err != nil {
+			return nil, err
+		}
+		if page.Data != nil {
+			workflows = append(workflows, *page.Data...)
+		}
+		if page.NextCursor == nil || *page.NextCursor == "" || *page.NextCursor == cursor {
+			return &WorkflowList{Data: &workflows}, nil
+		}
+		cursor = *page.NextCursor
+	}
+}
+
+// getWorkflowsPage fetches a page of workflows, the first one when cursor is empty
+func (c *Client) getWorkflowsPage(limit *int, cursor string) (*WorkflowList, error) {
 	url := fmt.Sprintf("%s/workflows", c.baseURL)
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
 
+	q := req.URL.Query()
 	if limit != nil {
 		requestLimit := min(*limit, MaxLimit)
-		q := req.URL.Query()
 		q.Add("limit", strconv.Itoa(requestLimit))
-		req.URL.RawQuery = q.Encode()
 	}
+	if cursor != "" {
+		q.Add("cursor", cursor)
+	}
+	req.URL.RawQuery = q.Encode()
 
 	req.Header.Set("X-N8N-API-KEY", c.apiToken)
 	req.Header.Set("Content-Type", "application/json")
@@ -175,7 +216,7 @@ func (c *Client) DeactivateWorkflow(id string) (*Workflow, error) {
 func (c *Client) CreateWorkflow(workflow *Workflow) (*Workflow, error) {
 	url := fmt.Sprintf("%s/workflows", c.baseURL)
 
-	workflowCopy := *workflow
+	workflowCopy := c.fields.Apply(*workflow)
 	workflowCopy.Id = nil
 	workflowCopy.Active = nil
 	workflowCopy.CreatedAt = nil
@@ -237,7 +278,7 @@ func (c *Client) CreateWorkflow(workflow *Workflow) (*Workflow, error) {
 func (c *Client) UpdateWorkflow(id string, workflow *Workflow) (*Workflow, error) {
 	url := fmt.Sprintf("%s/workflows/%s", c.baseURL, id)
 
-	workflowCopy := *workflow
+	workflowCopy := c.fields.Apply(*workflow)
 	workflowCopy.Id = nil
 	workflowCopy.Active = nil
 	workflowCopy.CreatedAt = nil
@@ -309,6 +350,10 @@ func (c *Client) GetWorkflow(id string) (*Workflow, error) {
 		}
 	}()
 
+	if resp.StatusCode == http.StatusNotFound {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%w: API returned error %d: %s", ErrWorkflowNotFound, resp.StatusCode, body)
+	}
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("API returned error %d: %s", resp.StatusCode, body)
@@ -586,6 +631,35 @@ func (c *Client) CreateTag(tagName string) (*Tag, error) {
 	return &tag, nil
 }
 
+// DeleteTag deletes a tag by ID
+func (c *Client) DeleteTag(id string) error {
+	url := fmt.Sprintf("%s/tags/%s", c.baseURL, id)
+
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("X-N8N-API-KEY", c.apiToken)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			c.logger.Warnf("Error closing response body: %v", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("API returned error %d: %s", resp.StatusCode, body)
+	}
+
+	return nil
+}
+
 // GetTags fetches all tags from n8n
 func (c *Client) GetTags() (*TagList, error) {
 	url := fmt.Sprintf("%s/tags", c.baseURL)
@@ -620,3 +694,80 @@ func (c *Client) GetTags() (*TagList, error) {
 
 	return &result, nil
 }
+
+// GetVariables fetches all variables from n8n
+func (c *Client) GetVariables() (*VariableList, error) {
+	url := fmt.Sprintf("%s/variables", c.baseURL)
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	q := req.URL.Query()
+	q.Add("limit", strconv.Itoa(MaxLimit))
+	req.URL.RawQuery = q.Encode()
+
+	req.Header.Set("X-N8N-API-KEY", c.apiToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			c.logger.Warnf("Error closing response body: %v", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API returned error %d: %s", resp.StatusCode, body)
+	}
+
+	var result VariableList
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// CreateVariable creates a new variable in n8n
+func (c *Client) CreateVariable(variable Variable) error {
+	url := fmt.Sprintf("%s/variables", c.baseURL)
+
+	variable.Id = nil
+	jsonBody, err := json.Marshal(variable)
+	if err != nil {
+		return err
+	}
+
+	c.logDebug("CREATE VARIABLE REQUEST: %s", variable.Key)
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("X-N8N-API-KEY", c.apiToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			c.logger.Warnf("Error closing response body: %v", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("API returned error %d: %s", resp.StatusCode, body)
+	}
+
+	return nil
+}
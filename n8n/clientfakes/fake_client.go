@@ -34,6 +34,17 @@ type FakeClientInterface struct {
 		result1 *n8n.Tag
 		result2 error
 	}
+	CreateVariableStub        func(n8n.Variable) error
+	createVariableMutex       sync.RWMutex
+	createVariableArgsForCall []struct {
+		arg1 n8n.Variable
+	}
+	createVariableReturns struct {
+		result1 error
+	}
+	createVariableReturnsOnCall map[int]struct {
+		result1 error
+	}
 	CreateWorkflowStub        func(*n8n.Workflow) (*n8n.Workflow, error)
 	createWorkflowMutex       sync.RWMutex
 	createWorkflowArgsForCall []struct {
@@ -60,6 +71,17 @@ type FakeClientInterface struct {
 		result1 *n8n.Workflow
 		result2 error
 	}
+	DeleteTagStub        func(string) error
+	deleteTagMutex       sync.RWMutex
+	deleteTagArgsForCall []struct {
+		arg1 string
+	}
+	deleteTagReturns struct {
+		result1 error
+	}
+	deleteTagReturnsOnCall map[int]struct {
+		result1 error
+	}
 	DeleteWorkflowStub        func(string) error
 	deleteWorkflowMutex       sync.RWMutex
 	deleteWorkflowArgsForCall []struct {
@@ -71,6 +93,18 @@ type FakeClientInterface struct {
 	deleteWorkflowReturnsOnCall map[int]struct {
 		result1 error
 	}
+	GetAllWorkflowsStub        func() (*n8n.WorkflowList, error)
+	getAllWorkflowsMutex       sync.RWMutex
+	getAllWorkflowsArgsForCall []struct {
+	}
+	getAllWorkflowsReturns struct {
+		result1 *n8n.WorkflowList
+		result2 error
+	}
+	getAllWorkflowsReturnsOnCall map[int]struct {
+		result1 *n8n.WorkflowList
+		result2 error
+	}
 	GetExecutionByIdStub        func(string, bool) (*n8n.Execution, error)
 	getExecutionByIdMutex       sync.RWMutex
 	getExecutionByIdArgsForCall []struct {
@@ -114,6 +148,18 @@ type FakeClientInterface struct {
 		result1 *n8n.TagList
 		result2 error
 	}
+	GetVariablesStub        func() (*n8n.VariableList, error)
+	getVariablesMutex       sync.RWMutex
+	getVariablesArgsForCall []struct {
+	}
+	getVariablesReturns struct {
+		result1 *n8n.VariableList
+		result2 error
+	}
+	getVariablesReturnsOnCall map[int]struct {
+		result1 *n8n.VariableList
+		result2 error
+	}
 	GetWorkflowStub        func(string) (*n8n.Workflow, error)
 	getWorkflowMutex       sync.RWMutex
 	getWorkflowArgsForCall []struct {
@@ -313,6 +359,67 @@ func (fake *FakeClientInterface) CreateTagReturnsOnCall(i int, result1 *n8n.Tag,
 	}{result1, result2}
 }
 
+func (fake *FakeClientInterface) CreateVariable(arg1 n8n.Variable) error {
+	fake.createVariableMutex.Lock()
+	ret, specificReturn := fake.createVariableReturnsOnCall[len(fake.createVariableArgsForCall)]
+	fake.createVariableArgsForCall = append(fake.createVariableArgsForCall, struct {
+		arg1 n8n.Variable
+	}{arg1})
+	stub := fake.CreateVariableStub
+	fakeReturns := fake.createVariableReturns
+	fake.recordInvocation("CreateVariable", []interface{}{arg1})
+	fake.createVariableMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeClientInterface) CreateVariableCallCount() int {
+	fake.createVariableMutex.RLock()
+	defer fake.createVariableMutex.RUnlock()
+	return len(fake.createVariableArgsForCall)
+}
+
+func (fake *FakeClientInterface) CreateVariableCalls(stub func(n8n.Variable) error) {
+	fake.createVariableMutex.Lock()
+	defer fake.createVariableMutex.Unlock()
+	fake.CreateVariableStub = stub
+}
+
+func (fake *FakeClientInterface) CreateVariableArgsForCall(i int) n8n.Variable {
+	fake.createVariableMutex.RLock()
+	defer fake.createVariableMutex.RUnlock()
+	argsForCall := fake.createVariableArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeClientInterface) CreateVariableReturns(result1 error) {
+	fake.createVariableMutex.Lock()
+	defer fake.createVariableMutex.Unlock()
+	fake.CreateVariableStub = nil
+	fake.createVariableReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClientInterface) CreateVariableReturnsOnCall(i int, result1 error) {
+	fake.createVariableMutex.Lock()
+	defer fake.createVariableMutex.Unlock()
+	fake.CreateVariableStub = nil
+	if fake.createVariableReturnsOnCall == nil {
+		fake.createVariableReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.createVariableReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeClientInterface) CreateWorkflow(arg1 *n8n.Workflow) (*n8n.Workflow, error) {
 	fake.createWorkflowMutex.Lock()
 	ret, specificReturn := fake.createWorkflowReturnsOnCall[len(fake.createWorkflowArgsForCall)]
@@ -441,6 +548,67 @@ func (fake *FakeClientInterface) DeactivateWorkflowReturnsOnCall(i int, result1
 	}{result1, result2}
 }
 
+func (fake *FakeClientInterface) DeleteTag(arg1 string) error {
+	fake.deleteTagMutex.Lock()
+	ret, specificReturn := fake.deleteTagReturnsOnCall[len(fake.deleteTagArgsForCall)]
+	fake.deleteTagArgsForCall = append(fake.deleteTagArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.DeleteTagStub
+	fakeReturns := fake.deleteTagReturns
+	fake.recordInvocation("DeleteTag", []interface{}{arg1})
+	fake.deleteTagMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeClientInterface) DeleteTagCallCount() int {
+	fake.deleteTagMutex.RLock()
+	defer fake.deleteTagMutex.RUnlock()
+	return len(fake.deleteTagArgsForCall)
+}
+
+func (fake *FakeClientInterface) DeleteTagCalls(stub func(string) error) {
+	fake.deleteTagMutex.Lock()
+	defer fake.deleteTagMutex.Unlock()
+	fake.DeleteTagStub = stub
+}
+
+func (fake *FakeClientInterface) DeleteTagArgsForCall(i int) string {
+	fake.deleteTagMutex.RLock()
+	defer fake.deleteTagMutex.RUnlock()
+	argsForCall := fake.deleteTagArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeClientInterface) DeleteTagReturns(result1 error) {
+	fake.deleteTagMutex.Lock()
+	defer fake.deleteTagMutex.Unlock()
+	fake.DeleteTagStub = nil
+	fake.deleteTagReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClientInterface) DeleteTagReturnsOnCall(i int, result1 error) {
+	fake.deleteTagMutex.Lock()
+	defer fake.deleteTagMutex.Unlock()
+	fake.DeleteTagStub = nil
+	if fake.deleteTagReturnsOnCall == nil {
+		fake.deleteTagReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteTagReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeClientInterface) DeleteWorkflow(arg1 string) error {
 	fake.deleteWorkflowMutex.Lock()
 	ret, specificReturn := fake.deleteWorkflowReturnsOnCall[len(fake.deleteWorkflowArgsForCall)]
@@ -502,6 +670,62 @@ func (fake *FakeClientInterface) DeleteWorkflowReturnsOnCall(i int, result1 erro
 	}{result1}
 }
 
+func (fake *FakeClientInterface) GetAllWorkflows() (*n8n.WorkflowList, error) {
+	fake.getAllWorkflowsMutex.Lock()
+	ret, specificReturn := fake.getAllWorkflowsReturnsOnCall[len(fake.getAllWorkflowsArgsForCall)]
+	fake.getAllWorkflowsArgsForCall = append(fake.getAllWorkflowsArgsForCall, struct {
+	}{})
+	stub := fake.GetAllWorkflowsStub
+	fakeReturns := fake.getAllWorkflowsReturns
+	fake.recordInvocation("GetAllWorkflows", []interface{}{})
+	fake.getAllWorkflowsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClientInterface) GetAllWorkflowsCallCount() int {
+	fake.getAllWorkflowsMutex.RLock()
+	defer fake.getAllWorkflowsMutex.RUnlock()
+	return len(fake.getAllWorkflowsArgsForCall)
+}
+
+func (fake *FakeClientInterface) GetAllWorkflowsCalls(stub func() (*n8n.WorkflowList, error)) {
+	fake.getAllWorkflowsMutex.Lock()
+	defer fake.getAllWorkflowsMutex.Unlock()
+	fake.GetAllWorkflowsStub = stub
+}
+
+func (fake *FakeClientInterface) GetAllWorkflowsReturns(result1 *n8n.WorkflowList, result2 error) {
+	fake.getAllWorkflowsMutex.Lock()
+	defer fake.getAllWorkflowsMutex.Unlock()
+	fake.GetAllWorkflowsStub = nil
+	fake.getAllWorkflowsReturns = struct {
+		result1 *n8n.WorkflowList
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClientInterface) GetAllWorkflowsReturnsOnCall(i int, result1 *n8n.WorkflowList, result2 error) {
+	fake.getAllWorkflowsMutex.Lock()
+	defer fake.getAllWorkflowsMutex.Unlock()
+	fake.GetAllWorkflowsStub = nil
+	if fake.getAllWorkflowsReturnsOnCall == nil {
+		fake.getAllWorkflowsReturnsOnCall = make(map[int]struct {
+			result1 *n8n.WorkflowList
+			result2 error
+		})
+	}
+	fake.getAllWorkflowsReturnsOnCall[i] = struct {
+		result1 *n8n.WorkflowList
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeClientInterface) GetExecutionById(arg1 string, arg2 bool) (*n8n.Execution, error) {
 	fake.getExecutionByIdMutex.Lock()
 	ret, specificReturn := fake.getExecutionByIdReturnsOnCall[len(fake.getExecutionByIdArgsForCall)]
@@ -691,6 +915,62 @@ func (fake *FakeClientInterface) GetTagsReturnsOnCall(i int, result1 *n8n.TagLis
 	}{result1, result2}
 }
 
+func (fake *FakeClientInterface) GetVariables() (*n8n.VariableList, error) {
+	fake.getVariablesMutex.Lock()
+	ret, specificReturn := fake.getVariablesReturnsOnCall[len(fake.getVariablesArgsForCall)]
+	fake.getVariablesArgsForCall = append(fake.getVariablesArgsForCall, struct {
+	}{})
+	stub := fake.GetVariablesStub
+	fakeReturns := fake.getVariablesReturns
+	fake.recordInvocation("GetVariables", []interface{}{})
+	fake.getVariablesMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClientInterface) GetVariablesCallCount() int {
+	fake.getVariablesMutex.RLock()
+	defer fake.getVariablesMutex.RUnlock()
+	return len(fake.getVariablesArgsForCall)
+}
+
+func (fake *FakeClientInterface) GetVariablesCalls(stub func() (*n8n.VariableList, error)) {
+	fake.getVariablesMutex.Lock()
+	defer fake.getVariablesMutex.Unlock()
+	fake.GetVariablesStub = stub
+}
+
+func (fake *FakeClientInterface) GetVariablesReturns(result1 *n8n.VariableList, result2 error) {
+	fake.getVariablesMutex.Lock()
+	defer fake.getVariablesMutex.Unlock()
+	fake.GetVariablesStub = nil
+	fake.getVariablesReturns = struct {
+		result1 *n8n.VariableList
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClientInterface) GetVariablesReturnsOnCall(i int, result1 *n8n.VariableList, result2 error) {
+	fake.getVariablesMutex.Lock()
+	defer fake.getVariablesMutex.Unlock()
+	fake.GetVariablesStub = nil
+	if fake.getVariablesReturnsOnCall == nil {
+		fake.getVariablesReturnsOnCall = make(map[int]struct {
+			result1 *n8n.VariableList
+			result2 error
+		})
+	}
+	fake.getVariablesReturnsOnCall[i] = struct {
+		result1 *n8n.VariableList
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeClientInterface) GetWorkflow(arg1 string) (*n8n.Workflow, error) {
 	fake.getWorkflowMutex.Lock()
 	ret, specificReturn := fake.getWorkflowReturnsOnCall[len(fake.getWorkflowArgsForCall)]
@@ -1020,18 +1300,26 @@ func (fake *FakeClientInterface) Invocations() map[string][][]interface{} {
 	defer fake.activateWorkflowMutex.RUnlock()
 	fake.createTagMutex.RLock()
 	defer fake.createTagMutex.RUnlock()
+	fake.createVariableMutex.RLock()
+	defer fake.createVariableMutex.RUnlock()
 	fake.createWorkflowMutex.RLock()
 	defer fake.createWorkflowMutex.RUnlock()
 	fake.deactivateWorkflowMutex.RLock()
 	defer fake.deactivateWorkflowMutex.RUnlock()
+	fake.deleteTagMutex.RLock()
+	defer fake.deleteTagMutex.RUnlock()
 	fake.deleteWorkflowMutex.RLock()
 	defer fake.deleteWorkflowMutex.RUnlock()
+	fake.getAllWorkflowsMutex.RLock()
+	defer fake.getAllWorkflowsMutex.RUnlock()
 	fake.getExecutionByIdMutex.RLock()
 	defer fake.getExecutionByIdMutex.RUnlock()
 	fake.getExecutionsMutex.RLock()
 	defer fake.getExecutionsMutex.RUnlock()
 	fake.getTagsMutex.RLock()
 	defer fake.getTagsMutex.RUnlock()
+	fake.getVariablesMutex.RLock()
+	defer fake.getVariablesMutex.RUnlock()
 	fake.getWorkflowMutex.RLock()
 	defer fake.getWorkflowMutex.RUnlock()
 	fake.getWorkflowTagsMutex.RLock()
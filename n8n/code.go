@@ -0,0 +1,273 @@
+package n8n
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// CodeFileKey is the key of the object that takes the place of a code parameter stored in its own file
+const CodeFileKey = "$file"
+
+// codeParameters are the node parameters holding source code, with the extension of their files.
+// Parameters with node types are only extracted from nodes of these types.
+var codeParameters = []struct {
+	key       string
+	ext       string
+	nodeTypes []string
+}{
+	{key: "functionCode", ext: ".js"},
+	{key: "jsCode", ext: ".js"},
+	{key: "pythonCode", ext: ".py"},
+	{key: "query", ext: ".sql", nodeTypes: []string{
+		"n8n-nodes-base.postgres",
+		"n8n-nodes-base.mySql",
+		"n8n-nodes-base.microsoftSql",
+		"n8n-nodes-base.snowflake",
+		"n8n-nodes-base.questDb",
+		"n8n-nodes-base.timescaleDb",
+		"n8n-nodes-base.crateDb",
+	}},
+	{key: "html", ext: ".html", nodeTypes: []string{
+		"n8n-nodes-base.html",
+		"n8n-nodes-base.emailSend",
+	}},
+}
+
+// unsafeFileNameChars matches the characters that are replaced in the names of code files
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// CodeFile is the source code of a node parameter stored in its own file
+type CodeFile struct {
+	// Path is slash separated and relative to the directory of the workflow file
+	Path    string
+	Content []byte
+}
+
+// IsCodeFile reports whether a file name has the extension of code files extracted from workflows
+func IsCodeFile(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".js", ".py", ".sql", ".html":
+		return true
+	}
+	return false
+}
+
+// WithCodeFiles makes ExtractCode move code parameters into files in the given directory,
+// relative to the directory of the workflow file
+func (e *WorkflowEncoder) WithCodeFiles(dir string) *WorkflowEncoder {
+	e.CodeDir = dir
+	return e
+}
+
+// ExtractCode replaces the code, SQL and HTML parameters of nodes with references to files in CodeDir and
+// returns the files. The workflow is returned unchanged when no CodeDir is set. Expressions are kept inline.
+func (e *WorkflowEncoder) ExtractCode(workflow Workflow) (Workflow, []CodeFile) {
+	if e.CodeDir == "" || len(workflow.Nodes) == 0 {
+		return workflow, nil
+	}
+
+	var files []CodeFile
+	names := nodeFileNames(workflow.Nodes)
+
+	nodes := make([]Node, len(workflow.Nodes))
+	for i, node := range workflow.Nodes {
+		var nodeFiles []CodeFile
+		nodes[i], nodeFiles = extractNodeCode(node, path.Join(e.CodeDir, names[i]))
+		files = append(files, nodeFiles...)
+	}
+
+	workflow.Nodes = nodes
+	return workflow, files
+}
+
+// extractNodeCode replaces the code parameters of a node with references to files named after base,
+// a slash separated path without extension
+func extractNodeCode(node Node, base string) (Node, []CodeFile) {
+	if node.Parameters == nil {
+		return node, nil
+	}
+
+	var files []CodeFile
+	var parameters map[string]interface{}
+	for _, parameter := range codeParameters {
+		if !isNodeType(node, parameter.nodeTypes) {
+			continue
+		}
+
+		code, ok := (*node.Parameters)[parameter.key].(string)
+		if !ok || code == "" || strings.HasPrefix(code, "=") {
+			continue
+		}
+
+		if parameters == nil {
+			parameters = make(map[string]interface{}, len(*node.Parameters))
+			for name, value := range *node.Parameters {
+				parameters[name] = value
+			}
+		}
+
+		// Editors end files with a newline, which is removed again when the code is inlined
+		filePath := base + parameter.ext
+		files = append(files, CodeFile{Path: filePath, Content: []byte(code + "\n")})
+		parameters[parameter.key] = map[string]interface{}{CodeFileKey: filePath}
+	}
+
+	if parameters != nil {
+		node.Parameters = &parameters
+	}
+	return node, files
+}
+
+// isNodeType reports whether a node has one of the given types, any node matches when no types are given
+func isNodeType(node Node, nodeTypes []string) bool {
+	if len(nodeTypes) == 0 {
+		return true
+	}
+	if node.Type == nil {
+		return false
+	}
+	for _, nodeType := range nodeTypes {
+		if *node.Type == nodeType {
+			return true
+		}
+	}
+	return false
+}
+
+// isCodeParameter reports whether a parameter of a node of the given type holds source code
+func isCodeParameter(nodeType string, key string) bool {
+	for _, parameter := range codeParameters {
+		if parameter.key == key && isNodeType(Node{Type: &nodeType}, parameter.nodeTypes) {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeFileNames returns a unique file name without extension for every node, derived from the node name
+func nodeFileNames(nodes []Node) []string {
+	names := make([]string, len(nodes))
+	used := make(map[string]bool)
+
+	for i, node := range nodes {
+		name := "node"
+		if node.Name != nil {
+			name = strings.Trim(unsafeFileNameChars.ReplaceAllString(*node.Name, "_"), "_")
+			if name == "" {
+				name = "node"
+			}
+		}
+
+		unique := name
+		for n := 2; used[strings.ToLower(unique)]; n++ {
+			unique = fmt.Sprintf("%s_%d", name, n)
+		}
+		used[strings.ToLower(unique)] = true
+		names[i] = unique
+	}
+
+	return names
+}
+
+// WithCodeFiles makes the decoder inline code files referenced by code parameters.
+// readFile is called with the slash separated path relative to the directory of the workflow file.
+func (d *WorkflowDecoder) WithCodeFiles(readFile func(path string) ([]byte, error)) *WorkflowDecoder {
+	d.ReadCodeFile = readFile
+	return d
+}
+
+// inlineCode replaces code file references with the content of the files, when the decoder reads code files
+func (d *WorkflowDecoder) inlineCode(workflow Workflow) (Workflow, error) {
+	if d.ReadCodeFile == nil {
+		return workflow, nil
+	}
+	return InlineCode(workflow, d.ReadCodeFile)
+}
+
+// InlineCode replaces the references to code files in node parameters with the content of the files
+func InlineCode(workflow Workflow, readFile func(path string) ([]byte, error)) (Workflow, error) {
+	if !HasCodeFileReferences(workflow) {
+		return workflow, nil
+	}
+
+	nodes := make([]Node, len(workflow.Nodes))
+	for i, node := range workflow.Nodes {
+		nodes[i] = node
+		if node.Parameters == nil {
+			continue
+		}
+
+		var parameters map[string]interface{}
+		for key, value := range *node.Parameters {
+			filePath, ok := codeFileReference(value)
+			if !ok {
+				continue
+			}
+
+			if strings.HasPrefix(filePath, "/") || !isLocalPath(filePath) {
+				return Workflow{}, fmt.Errorf("code file %s of node '%s' must be relative to the workflow file", filePath, codeNodeName(node))
+			}
+
+			content, err := readFile(filePath)
+			if err != nil {
+				return Workflow{}, fmt.Errorf("error reading code file %s of node '%s': %w", filePath, codeNodeName(node), err)
+			}
+
+			if parameters == nil {
+				parameters = make(map[string]interface{}, len(*node.Parameters))
+				for name, value := range *node.Parameters {
+					parameters[name] = value
+				}
+			}
+			parameters[key] = strings.TrimSuffix(string(content), "\n")
+		}
+
+		if parameters != nil {
+			nodes[i].Parameters = &parameters
+		}
+	}
+
+	workflow.Nodes = nodes
+	return workflow, nil
+}
+
+// HasCodeFileReferences reports whether any node parameter references a code file
+func HasCodeFileReferences(workflow Workflow) bool {
+	for _, node := range workflow.Nodes {
+		if node.Parameters == nil {
+			continue
+		}
+		for _, value := range *node.Parameters {
+			if _, ok := codeFileReference(value); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// codeFileReference returns the path of a code file when a parameter value references one
+func codeFileReference(value interface{}) (string, bool) {
+	reference, ok := value.(map[string]interface{})
+	if !ok || len(reference) != 1 {
+		return "", false
+	}
+	filePath, ok := reference[CodeFileKey].(string)
+	return filePath, ok && filePath != ""
+}
+
+// isLocalPath reports whether a slash separated path stays within the directory it is relative to
+func isLocalPath(filePath string) bool {
+	cleaned := path.Clean(filePath)
+	return cleaned != ".." && !strings.HasPrefix(cleaned, "../")
+}
+
+// codeNodeName returns the name of a node for error messages
+func codeNodeName(node Node) string {
+	if node.Name == nil {
+		return ""
+	}
+	return *node.Name
+}
@@ -0,0 +1,453 @@
+package n8n
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DecodeError is an error decoding a workflow file, with the location of the problem in the file
+type DecodeError struct {
+	// File is the path of the file holding the problem. The decoder only knows the files of workflow
+	// directories, the callers reading a file set it.
+	File string
+	// Field is the path of the offending value in the workflow, like nodes[1].typeVersion
+	Field string
+	// Line and Column start at 1 and are 0 when unknown
+	Line   int
+	Column int
+	// Snippet is the line of the file holding the problem
+	Snippet string
+	Err     error
+
+	path []interface{}
+}
+
+func (e *DecodeError) Error() string {
+	var b strings.Builder
+
+	location := e.File
+	if e.Line > 0 {
+		if location != "" {
+			location += ":"
+		}
+		location += strconv.Itoa(e.Line)
+		if e.Column > 0 {
+			location += ":" + strconv.Itoa(e.Column)
+		}
+	}
+	if location != "" {
+		b.WriteString(location + ": ")
+	}
+	if e.Field != "" {
+		b.WriteString(e.Field + ": ")
+	}
+	b.WriteString(e.Err.Error())
+
+	if e.Snippet != "" {
+		gutter := strconv.Itoa(e.Line)
+		fmt.Fprintf(&b, "\n  %s | %s", gutter, e.Snippet)
+		if e.Column > 0 {
+			// Tabs are kept so the caret lines up with the snippet
+			prefix := []rune(e.Snippet)
+			if e.Column-1 < len(prefix) {
+				prefix = prefix[:e.Column-1]
+			}
+			padding := strings.Map(func(r rune) rune {
+				if r == '\t' {
+					return r
+				}
+				return ' '
+			}, string(prefix))
+			fmt.Fprintf(&b, "\n  %s | %s^", strings.Repeat(" ", len(gutter)), padding)
+		}
+	}
+
+	return b.String()
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// yamlLinePattern matches the line number in the errors of the YAML parser
+var yamlLinePattern = regexp.MustCompile(`line (\d+): (.*)`)
+
+// LocateYAMLError returns the error of the YAML parser as a DecodeError located at the line it reports, or
+// unchanged when it reports no line
+func LocateYAMLError(data []byte, err error) error {
+	match := yamlLinePattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return err
+	}
+
+	line, _ := strconv.Atoi(match[1])
+	e := &DecodeError{Line: line, Err: errors.New(match[2])}
+	e.Snippet = lineAt(data, line)
+	return e
+}
+
+// newJSONDecodeError returns the error of the JSON decoder located at the offset it reports in the data it
+// decoded, or nil when the error has no offset
+func newJSONDecodeError(data []byte, err error) *DecodeError {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		// The offset of syntax errors is after the offending character
+		e := &DecodeError{Err: errors.New(syntaxErr.Error())}
+		e.locateOffset(data, max(syntaxErr.Offset-1, 0))
+		return e
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		e := &DecodeError{Err: fmt.Errorf("expected %s, got %s", describeKind(typeErr.Type), describeJSONValue(typeErr.Value))}
+		if typeErr.Field != "" {
+			for _, segment := range strings.Split(typeErr.Field, ".") {
+				if index, err := strconv.Atoi(segment); err == nil {
+					e.path = append(e.path, index)
+				} else {
+					e.path = append(e.path, segment)
+				}
+			}
+			e.Field = formatFieldPath(e.path)
+			e.locateJSON(data)
+		} else {
+			// The offset of type errors is the end of the offending value
+			e.locateOffset(data, typeErr.Offset)
+		}
+		return e
+	}
+
+	return nil
+}
+
+// newTypeError finds the value of a decoded workflow document that doesn't fit the Workflow type, which the
+// errors of the JSON decoder don't locate
+func newTypeError(document interface{}) *DecodeError {
+	e := findTypeMismatch(document, reflect.TypeOf(Workflow{}), nil)
+	if e != nil {
+		e.Field = formatFieldPath(e.path)
+	}
+	return e
+}
+
+// findTypeMismatch returns an error for the first value that can't be decoded into the given type
+func findTypeMismatch(value interface{}, t reflect.Type, path []interface{}) *DecodeError {
+	if value == nil {
+		return nil
+	}
+
+	mismatch := func(expected string) *DecodeError {
+		return &DecodeError{
+			Err:  fmt.Errorf("expected %s, got %s", expected, describeValue(value)),
+			path: append([]interface{}(nil), path...),
+		}
+	}
+
+	switch t {
+	case reflect.TypeOf(time.Time{}):
+		s, ok := value.(string)
+		if !ok {
+			return mismatch("a date-time string")
+		}
+		if _, err := time.Parse(time.RFC3339, s); err != nil {
+			return &DecodeError{Err: fmt.Errorf("expected an RFC 3339 date-time, got %q", s), path: append([]interface{}(nil), path...)}
+		}
+		return nil
+	case reflect.TypeOf(Workflow_StaticData{}):
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return findTypeMismatch(value, t.Elem(), path)
+	case reflect.Interface:
+		return nil
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int32, reflect.Int64, reflect.Float32, reflect.Float64:
+		if !fitsKind(value, t.Kind()) {
+			return mismatch(describeKind(t))
+		}
+	case reflect.Slice:
+		items, ok := value.([]interface{})
+		if !ok {
+			return mismatch(describeKind(t))
+		}
+		for i, item := range items {
+			if e := findTypeMismatch(item, t.Elem(), append(path, i)); e != nil {
+				return e
+			}
+		}
+	case reflect.Map:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return mismatch(describeKind(t))
+		}
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if e := findTypeMismatch(object[key], t.Elem(), append(path, key)); e != nil {
+				return e
+			}
+		}
+	case reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return mismatch(describeKind(t))
+		}
+		for i := 0; i < t.NumField(); i++ {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+			if e := findTypeMismatch(object[name], t.Field(i).Type, append(path, name)); e != nil {
+				return e
+			}
+		}
+	}
+	return nil
+}
+
+// fitsKind reports whether a decoded JSON value can be decoded into a basic kind
+func fitsKind(value interface{}, kind reflect.Kind) bool {
+	switch kind {
+	case reflect.String:
+		_, ok := value.(string)
+		return ok
+	case reflect.Bool:
+		_, ok := value.(bool)
+		return ok
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		n, ok := value.(float64)
+		return ok && n == float64(int64(n))
+	default:
+		_, ok := value.(float64)
+		return ok
+	}
+}
+
+// describeKind names the JSON value a Go type is decoded from
+func describeKind(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "a list"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	}
+	return t.String()
+}
+
+// describeValue names the kind of a decoded JSON value
+func describeValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return fmt.Sprintf("the string %q", v)
+	case bool:
+		return fmt.Sprintf("the boolean %t", v)
+	case float64:
+		return "the number " + strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}:
+		return "a list"
+	case map[string]interface{}:
+		return "an object"
+	}
+	return "null"
+}
+
+// describeJSONValue names the kind of value in the errors of the JSON decoder
+func describeJSONValue(value string) string {
+	switch value {
+	case "array":
+		return "a list"
+	case "object":
+		return "an object"
+	case "string", "number", "bool":
+		return "a " + strings.Replace(value, "bool", "boolean", 1)
+	}
+	return value
+}
+
+// formatFieldPath formats the path of a value in a workflow like nodes[1].typeVersion
+func formatFieldPath(path []interface{}) string {
+	var b strings.Builder
+	for _, segment := range path {
+		switch s := segment.(type) {
+		case int:
+			fmt.Fprintf(&b, "[%d]", s)
+		case string:
+			if b.Len() > 0 {
+				b.WriteString(".")
+			}
+			b.WriteString(s)
+		}
+	}
+	return b.String()
+}
+
+// locateOffset sets the location of the error to a byte offset in the data
+func (e *DecodeError) locateOffset(data []byte, offset int64) {
+	if offset < 0 || offset > int64(len(data)) {
+		return
+	}
+	before := data[:offset]
+	e.Line = bytes.Count(before, []byte("\n")) + 1
+	e.Column = utf8.RuneCount(before[bytes.LastIndexByte(before, '\n')+1:]) + 1
+	e.Snippet = lineAt(data, e.Line)
+}
+
+// locateJSON sets the location of the error to its value in JSON data. The location is cleared when the
+// data doesn't hold the value, like values added by an overlay.
+func (e *DecodeError) locateJSON(data []byte) {
+	e.Line, e.Column, e.Snippet = 0, 0, ""
+	if offset, ok := jsonValueOffset(data, e.path); ok {
+		e.locateOffset(data, offset)
+	}
+}
+
+// locateYAML sets the location of the error to its value in YAML data. The location is cleared when the
+// data doesn't hold the value.
+func (e *DecodeError) locateYAML(data []byte) {
+	e.Line, e.Column, e.Snippet = 0, 0, ""
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return
+	}
+	node := yamlNodeAt(&root, e.path)
+	if node == nil {
+		return
+	}
+	e.Line, e.Column = node.Line, node.Column
+	e.Snippet = lineAt(data, e.Line)
+}
+
+// yamlNodeAt returns the node of the value at a path in a YAML document
+func yamlNodeAt(node *yaml.Node, path []interface{}) *yaml.Node {
+	for node.Kind == yaml.DocumentNode || node.Kind == yaml.AliasNode {
+		if node.Kind == yaml.AliasNode {
+			node = node.Alias
+		} else if len(node.Content) > 0 {
+			node = node.Content[0]
+		} else {
+			return nil
+		}
+	}
+	if len(path) == 0 {
+		return node
+	}
+
+	switch segment := path[0].(type) {
+	case string:
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == segment {
+				return yamlNodeAt(node.Content[i+1], path[1:])
+			}
+		}
+	case int:
+		if node.Kind == yaml.SequenceNode && segment < len(node.Content) {
+			return yamlNodeAt(node.Content[segment], path[1:])
+		}
+	}
+	return nil
+}
+
+// jsonValueOffset returns the offset of the value at a path in JSON data
+func jsonValueOffset(data []byte, path []interface{}) (int64, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for _, segment := range path {
+		token, err := decoder.Token()
+		if err != nil {
+			return 0, false
+		}
+
+		found := false
+		switch token {
+		case json.Delim('{'):
+			key, ok := segment.(string)
+			if !ok {
+				return 0, false
+			}
+			for decoder.More() {
+				name, err := decoder.Token()
+				if err != nil {
+					return 0, false
+				}
+				if name == key {
+					found = true
+					break
+				}
+				if err := skipJSONValue(decoder); err != nil {
+					return 0, false
+				}
+			}
+		case json.Delim('['):
+			index, ok := segment.(int)
+			if !ok {
+				return 0, false
+			}
+			for i := 0; decoder.More(); i++ {
+				if i == index {
+					found = true
+					break
+				}
+				if err := skipJSONValue(decoder); err != nil {
+					return 0, false
+				}
+			}
+		}
+		if !found {
+			return 0, false
+		}
+	}
+	return valueStart(data, decoder.InputOffset()), true
+}
+
+// skipJSONValue reads the next value from a decoder
+func skipJSONValue(decoder *json.Decoder) error {
+	var value json.RawMessage
+	return decoder.Decode(&value)
+}
+
+// valueStart returns the offset of the value following an offset in JSON data, after the separators
+// between a key and its value
+func valueStart(data []byte, offset int64) int64 {
+	for offset < int64(len(data)) && strings.IndexByte(" \t\r\n:,", data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+// lineAt returns a line of the data without its line break, or an empty string when the data is shorter
+func lineAt(data []byte, line int) string {
+	lines := bytes.Split(data, []byte("\n"))
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	return strings.TrimRight(string(lines[line-1]), "\r")
+}
@@ -0,0 +1,173 @@
+package n8n
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DirectoryWorkflowFile is the file of a workflow directory holding the metadata, settings and connections
+	DirectoryWorkflowFile = "workflow.yaml"
+	// DirectoryNodesDir is the directory of a workflow directory holding one file per node
+	DirectoryNodesDir = "nodes"
+)
+
+// EncodeToDirectory encodes a workflow to the files of a workflow directory, keyed by their slash separated
+// path relative to the directory. workflow.yaml references the node files in the order of the nodes, and the
+// code, SQL and HTML of a node are stored in files next to its node file.
+func (e *WorkflowEncoder) EncodeToDirectory(workflow Workflow) (map[string][]byte, error) {
+	if e.Clean {
+		workflow = CleanWorkflow(workflow)
+	}
+
+	files := make(map[string][]byte)
+	names := nodeFileNames(workflow.Nodes)
+
+	references := make([]interface{}, 0, len(workflow.Nodes))
+	for i, node := range workflow.Nodes {
+		base := path.Join(DirectoryNodesDir, names[i])
+
+		node, codeFiles := extractNodeCode(node, base)
+		for _, file := range codeFiles {
+			files[file.Path] = file.Content
+		}
+
+		document, err := toDocument(node)
+		if err != nil {
+			return nil, err
+		}
+		content, err := encodeYAMLDocument(document)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode node '%s': %w", codeNodeName(node), err)
+		}
+
+		nodePath := base + ".yaml"
+		files[nodePath] = content
+		references = append(references, map[string]interface{}{CodeFileKey: nodePath})
+	}
+
+	document, err := toDocument(workflow)
+	if err != nil {
+		return nil, err
+	}
+	document["nodes"] = references
+
+	content, err := encodeYAMLDocument(document)
+	if err != nil {
+		return nil, err
+	}
+	files[DirectoryWorkflowFile] = content
+
+	return files, nil
+}
+
+// DecodeFromDirectory decodes a workflow from the files of a workflow directory, keyed by their slash
+// separated path relative to the directory. Overlays and variable substitution are applied to the
+// assembled workflow.
+func (d *WorkflowDecoder) DecodeFromDirectory(files map[string][]byte) (Workflow, error) {
+	content, ok := files[DirectoryWorkflowFile]
+	if !ok {
+		return Workflow{}, fmt.Errorf("workflow directory has no %s", DirectoryWorkflowFile)
+	}
+
+	var document map[string]interface{}
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return Workflow{}, directoryFileError(DirectoryWorkflowFile, content, err)
+	}
+	if document == nil {
+		document = make(map[string]interface{})
+	}
+
+	references, _ := document["nodes"].([]interface{})
+	nodes := make([]interface{}, 0, len(references))
+	nodeFiles := make([]string, len(references))
+	for i, reference := range references {
+		nodePath, ok := codeFileReference(reference)
+		if !ok {
+			// Nodes can also be written inline
+			nodes = append(nodes, reference)
+			continue
+		}
+		if !isLocalPath(nodePath) || path.IsAbs(nodePath) {
+			return Workflow{}, fmt.Errorf("node file %s must be inside the workflow directory", nodePath)
+		}
+
+		nodeContent, ok := files[path.Clean(nodePath)]
+		if !ok {
+			return Workflow{}, fmt.Errorf("node file %s referenced by node %d not found", nodePath, i+1)
+		}
+
+		var node map[string]interface{}
+		if err := yaml.Unmarshal(nodeContent, &node); err != nil {
+			return Workflow{}, directoryFileError(path.Clean(nodePath), nodeContent, err)
+		}
+		nodes = append(nodes, node)
+		nodeFiles[i] = path.Clean(nodePath)
+	}
+	document["nodes"] = nodes
+
+	data, err := json.Marshal(document)
+	if err != nil {
+		return Workflow{}, fmt.Errorf("failed to assemble workflow directory: %w", err)
+	}
+
+	// Code files are read from the directory, relative to it
+	decoder := *d
+	decoder.ReadCodeFile = func(filePath string) ([]byte, error) {
+		content, ok := files[path.Clean(filePath)]
+		if !ok {
+			return nil, fmt.Errorf("file not found in workflow directory")
+		}
+		return content, nil
+	}
+
+	workflow, err := decoder.DecodeFromJSON(data)
+	if err != nil {
+		// The error is located in the assembled workflow, locate it in the file holding the value instead
+		var located *DecodeError
+		if errors.As(err, &located) && len(located.path) > 0 {
+			located.File = DirectoryWorkflowFile
+			if index, ok := nodeIndex(located.path); ok && index < len(nodeFiles) && nodeFiles[index] != "" {
+				located.File = nodeFiles[index]
+				located.path = located.path[2:]
+			}
+			located.locateYAML(files[located.File])
+		}
+		return Workflow{}, err
+	}
+	return workflow, nil
+}
+
+// directoryFileError returns the error decoding a file of a workflow directory, located in the file
+func directoryFileError(filePath string, content []byte, err error) error {
+	located := LocateYAMLError(content, err)
+	if decodeErr, ok := located.(*DecodeError); ok {
+		decodeErr.File = filePath
+		return decodeErr
+	}
+	return fmt.Errorf("failed to decode %s: %w", filePath, err)
+}
+
+// nodeIndex returns the index of the node holding the value at a path in a workflow
+func nodeIndex(valuePath []interface{}) (int, bool) {
+	if len(valuePath) < 2 || valuePath[0] != "nodes" {
+		return 0, false
+	}
+	index, ok := valuePath[1].(int)
+	return index, ok
+}
+
+// DirectoryFilePaths returns the paths of the files of an encoded workflow directory in a stable order
+func DirectoryFilePaths(files map[string][]byte) []string {
+	paths := make([]string, 0, len(files))
+	for filePath := range files {
+		paths = append(paths, filePath)
+	}
+	sort.Strings(paths)
+	return paths
+}
@@ -4,6 +4,7 @@ package n8n
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -12,13 +13,17 @@ import (
 )
 
 // CleanWorkflow creates a clean copy of a workflow with null and empty fields removed.
-// It preserves essential fields like typeVersion for proper node compatibility.
+// It preserves essential fields like typeVersion for proper node compatibility, and fields outside
+// the API schema except the ReadOnlyFields n8n changes on every save.
 func CleanWorkflow(workflow Workflow) Workflow {
 	cleanedWorkflow := workflow
 
 	cleanedWorkflow.CreatedAt = nil
 	cleanedWorkflow.UpdatedAt = nil
 	cleanedWorkflow.Shared = nil
+	cleanedWorkflow.AdditionalProperties = keepFields(workflow.AdditionalProperties, func(field string) bool {
+		return !matchesField(ReadOnlyFields, field)
+	})
 
 	if cleanedWorkflow.Tags != nil && len(*cleanedWorkflow.Tags) > 0 {
 		cleanTags := make([]Tag, len(*cleanedWorkflow.Tags))
@@ -41,6 +46,8 @@ func CleanWorkflow(workflow Workflow) Workflow {
 // WorkflowEncoder handles various encoding formats for n8n workflows
 type WorkflowEncoder struct {
 	Clean bool
+	// CodeDir is the directory, relative to the workflow file, that ExtractCode moves code parameters to
+	CodeDir string
 }
 
 // NewWorkflowEncoder creates a new encoder with the specified options
@@ -73,21 +80,44 @@ func (e *WorkflowEncoder) EncodeToYAML(workflow Workflow) ([]byte, error) {
 		workflowToEncode = workflow
 	}
 
-	jsonData, err := json.Marshal(workflowToEncode)
+	workflowMap, err := toDocument(workflowToEncode)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeYAMLDocument(workflowMap)
+}
+
+// toDocument converts a value to the generic map of its JSON representation
+func toDocument(value interface{}) (map[string]interface{}, error) {
+	jsonData, err := json.Marshal(value)
 	if err != nil {
 		return nil, fmt.Errorf("failed to encode workflow to JSON before YAML conversion: %w", err)
 	}
 
-	var workflowMap map[string]interface{}
-	if err := json.Unmarshal(jsonData, &workflowMap); err != nil {
+	var document map[string]interface{}
+	if err := json.Unmarshal(jsonData, &document); err != nil {
 		return nil, fmt.Errorf("failed to convert JSON to map for YAML encoding: %w", err)
 	}
 
+	return document, nil
+}
+
+// encodeYAMLDocument encodes a document to YAML with two space indentation and a document start marker.
+// Multi-line strings are written as literal blocks and long lines are never folded, so expressions, code,
+// SQL and HTML stay readable and decode to the same bytes.
+func encodeYAMLDocument(document map[string]interface{}) ([]byte, error) {
+	var node yaml.Node
+	if err := node.Encode(document); err != nil {
+		return nil, fmt.Errorf("failed to encode workflow to YAML: %w", err)
+	}
+	useLiteralBlocks(&node)
+
 	var buf bytes.Buffer
 	encoder := yaml.NewEncoder(&buf)
 	encoder.SetIndent(2)
 
-	if err := encoder.Encode(workflowMap); err != nil {
+	if err := encoder.Encode(&node); err != nil {
 		return nil, fmt.Errorf("failed to encode workflow to YAML: %w", err)
 	}
 
@@ -98,20 +128,137 @@ func (e *WorkflowEncoder) EncodeToYAML(workflow Workflow) ([]byte, error) {
 	return append([]byte("---\n"), buf.Bytes()...), nil
 }
 
-type WorkflowDecoder struct{}
+// useLiteralBlocks marks the multi-line strings of a YAML node tree as literal blocks. Strings that can't be
+// written as literal blocks, like lines with trailing spaces, are quoted by the encoder instead.
+func useLiteralBlocks(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode {
+		if node.ShortTag() == "!!str" && strings.Contains(node.Value, "\n") {
+			node.Style = yaml.LiteralStyle
+		}
+		return
+	}
+
+	for _, child := range node.Content {
+		useLiteralBlocks(child)
+	}
+}
+
+// WorkflowDecoder handles decoding of n8n workflows from various formats
+type WorkflowDecoder struct {
+	// Overlays are JSON or YAML documents merged over the workflow before it is decoded
+	Overlays [][]byte
+	// Lookup resolves ${VAR} placeholders, substitution is disabled when nil
+	Lookup func(string) (string, bool)
+	// ReadCodeFile reads the code files referenced by code parameters, references are kept when nil
+	ReadCodeFile func(path string) ([]byte, error)
+}
 
 // NewWorkflowDecoder creates a new decoder
 func NewWorkflowDecoder() *WorkflowDecoder {
 	return &WorkflowDecoder{}
 }
 
-// DecodeFromJSON decodes a workflow from a JSON byte array
+// WithOverlay adds an overlay document to be merged over decoded workflows
+func (d *WorkflowDecoder) WithOverlay(overlay []byte) *WorkflowDecoder {
+	d.Overlays = append(d.Overlays, overlay)
+	return d
+}
+
+// WithVariables enables ${VAR} substitution using the given lookup function
+func (d *WorkflowDecoder) WithVariables(lookup func(string) (string, bool)) *WorkflowDecoder {
+	d.Lookup = lookup
+	return d
+}
+
+// render applies overlays and variable substitution to a decoded document and returns it as JSON
+func (d *WorkflowDecoder) render(document map[string]interface{}) ([]byte, error) {
+	for i, overlay := range d.Overlays {
+		var overlayMap map[string]interface{}
+		if err := yaml.Unmarshal(overlay, &overlayMap); err != nil {
+			return nil, fmt.Errorf("failed to decode overlay %d: %w", i+1, err)
+		}
+		document = ApplyOverlay(document, overlayMap)
+	}
+
+	var rendered interface{} = document
+	if d.Lookup != nil {
+		expanded, err := ExpandVariables(document, d.Lookup)
+		if err != nil {
+			return nil, err
+		}
+		rendered = expanded
+	}
+
+	return json.Marshal(rendered)
+}
+
+// needsRender reports whether overlays or variable substitution are configured
+func (d *WorkflowDecoder) needsRender() bool {
+	return len(d.Overlays) > 0 || d.Lookup != nil
+}
+
+// DecodeFromJSON decodes a workflow from a JSON byte array. An array holding a single workflow, as exported
+// by n8n, is accepted too.
 func (d *WorkflowDecoder) DecodeFromJSON(data []byte) (Workflow, error) {
+	if isJSONArray(data) {
+		workflows, err := d.DecodeExport(data)
+		if err != nil {
+			return Workflow{}, err
+		}
+		if len(workflows) != 1 {
+			return Workflow{}, fmt.Errorf("file contains %d workflows, use 'n8n workflows import' to split them into separate files", len(workflows))
+		}
+		return workflows[0], nil
+	}
+
+	return d.decodeJSONObject(data)
+}
+
+// decodeJSONObject decodes a workflow from a JSON object
+func (d *WorkflowDecoder) decodeJSONObject(data []byte) (Workflow, error) {
+	original := data
+	if d.needsRender() {
+		var document map[string]interface{}
+		if err := json.Unmarshal(data, &document); err != nil {
+			if located := newJSONDecodeError(data, err); located != nil {
+				return Workflow{}, located
+			}
+			return Workflow{}, fmt.Errorf("failed to decode workflow from JSON: %w", err)
+		}
+
+		rendered, err := d.render(document)
+		if err != nil {
+			return Workflow{}, fmt.Errorf("failed to render workflow: %w", err)
+		}
+		data = rendered
+	}
+
 	var workflow Workflow
 	if err := json.Unmarshal(data, &workflow); err != nil {
+		if located := locateJSONError(original, data, err); located != nil {
+			return Workflow{}, located
+		}
 		return Workflow{}, fmt.Errorf("failed to decode workflow from JSON: %w", err)
 	}
-	return workflow, nil
+	return d.inlineCode(workflow)
+}
+
+// locateJSONError locates an error decoding a workflow in the original JSON data, before it was rendered
+func locateJSONError(original []byte, data []byte, err error) *DecodeError {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return newJSONDecodeError(data, err)
+	}
+
+	var document interface{}
+	if json.Unmarshal(data, &document) != nil {
+		return nil
+	}
+	located := newTypeError(document)
+	if located != nil {
+		located.locateJSON(original)
+	}
+	return located
 }
 
 // DecodeFromYAML decodes a workflow from a YAML byte array
@@ -120,7 +267,7 @@ func (d *WorkflowDecoder) DecodeFromYAML(data []byte) (Workflow, error) {
 
 	var workflowMap map[string]interface{}
 	if err := yaml.Unmarshal(data, &workflowMap); err != nil {
-		return Workflow{}, fmt.Errorf("failed to decode workflow from YAML: %w", err)
+		return Workflow{}, LocateYAMLError(data, err)
 	}
 
 	jsonBytes, err := json.MarshalIndent(workflowMap, "", "  ")
@@ -128,9 +275,17 @@ func (d *WorkflowDecoder) DecodeFromYAML(data []byte) (Workflow, error) {
 		logger.Debug("YAML TO JSON INTERMEDIATE MAP:\n%s", string(jsonBytes))
 	}
 
-	jsonData, err := json.Marshal(workflowMap)
-	if err != nil {
-		return Workflow{}, fmt.Errorf("failed to convert YAML map to JSON: %w", err)
+	var jsonData []byte
+	if d.needsRender() {
+		jsonData, err = d.render(workflowMap)
+		if err != nil {
+			return Workflow{}, fmt.Errorf("failed to render workflow: %w", err)
+		}
+	} else {
+		jsonData, err = json.Marshal(workflowMap)
+		if err != nil {
+			return Workflow{}, fmt.Errorf("failed to convert YAML map to JSON: %w", err)
+		}
 	}
 
 	var prettyJSON bytes.Buffer
@@ -150,11 +305,16 @@ func (d *WorkflowDecoder) DecodeFromYAML(data []byte) (Workflow, error) {
 		if mapErr := json.Unmarshal(jsonData, &anyMap); mapErr == nil {
 			prettyJSON, _ := json.MarshalIndent(anyMap, "", "  ")
 			logger.Debug("JSON STRUCTURE THAT FAILED TO UNMARSHAL:\n%s", string(prettyJSON))
+
+			if located := newTypeError(anyMap); located != nil {
+				located.locateYAML(data)
+				return Workflow{}, located
+			}
 		}
 		return Workflow{}, fmt.Errorf("failed to convert JSON to workflow: %w", err)
 	}
 
-	return workflow, nil
+	return d.inlineCode(workflow)
 }
 
 // DecodeFromBytes attempts to decode a workflow from bytes, with smart format detection
@@ -176,12 +336,12 @@ func (d *WorkflowDecoder) DecodeFromBytes(data []byte) (Workflow, error) {
 
 	var workflow Workflow
 	if err := json.Unmarshal(data, &workflow); err == nil {
-		return workflow, nil
+		return d.inlineCode(workflow)
 	}
 
 	if err := yaml.Unmarshal(data, &workflow); err != nil {
 		return Workflow{}, fmt.Errorf("failed to decode workflow from JSON or YAML: %w", err)
 	}
 
-	return workflow, nil
+	return d.inlineCode(workflow)
 }
@@ -0,0 +1,103 @@
+package n8n
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// DecodeExport decodes the workflows of a file exported from n8n: a single workflow downloaded from the editor,
+// a file written by `n8n export:workflow` with or without --separate, or an array of workflows. Fields outside
+// the API schema, like pinData and meta, are kept in AdditionalProperties, only the ReadOnlyFields are dropped
+// when the workflow is encoded, and the numeric IDs of older n8n versions are turned into strings.
+func (d *WorkflowDecoder) DecodeExport(data []byte) ([]Workflow, error) {
+	var documents []map[string]interface{}
+	if isJSONArray(data) {
+		if err := json.Unmarshal(data, &documents); err != nil {
+			if located := newJSONDecodeError(data, err); located != nil {
+				return nil, located
+			}
+			return nil, fmt.Errorf("failed to decode workflows from JSON: %w", err)
+		}
+	} else {
+		var document map[string]interface{}
+		if err := json.Unmarshal(data, &document); err != nil {
+			if located := newJSONDecodeError(data, err); located != nil {
+				return nil, located
+			}
+			return nil, fmt.Errorf("failed to decode workflow from JSON: %w", err)
+		}
+		documents = []map[string]interface{}{document}
+	}
+
+	workflows := make([]Workflow, 0, len(documents))
+	for i, document := range documents {
+		normalizeExportIDs(document)
+
+		normalized, err := json.Marshal(document)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode workflow %d: %w", i+1, err)
+		}
+
+		workflow, err := d.decodeJSONObject(normalized)
+		if err != nil {
+			// The error is located in the normalized workflow, locate it in the file instead
+			var located *DecodeError
+			if errors.As(err, &located) && len(located.path) > 0 {
+				if isJSONArray(data) {
+					located.path = append([]interface{}{i}, located.path...)
+				}
+				located.locateJSON(data)
+			}
+			if len(documents) > 1 {
+				return nil, fmt.Errorf("workflow %d: %w", i+1, err)
+			}
+			return nil, err
+		}
+		workflows = append(workflows, workflow)
+	}
+
+	return workflows, nil
+}
+
+// isJSONArray reports whether JSON data holds an array
+func isJSONArray(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte("["))
+}
+
+// normalizeExportIDs turns the numeric IDs of workflows, tags and credentials exported from older n8n
+// versions into strings
+func normalizeExportIDs(document map[string]interface{}) {
+	normalizeID(document)
+
+	if tags, ok := document["tags"].([]interface{}); ok {
+		for _, tag := range tags {
+			if tag, ok := tag.(map[string]interface{}); ok {
+				normalizeID(tag)
+			}
+		}
+	}
+
+	nodes, _ := document["nodes"].([]interface{})
+	for _, node := range nodes {
+		node, ok := node.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		credentials, _ := node["credentials"].(map[string]interface{})
+		for _, credential := range credentials {
+			if credential, ok := credential.(map[string]interface{}); ok {
+				normalizeID(credential)
+			}
+		}
+	}
+}
+
+// normalizeID turns a numeric id of an object into a string
+func normalizeID(object map[string]interface{}) {
+	if id, ok := object["id"].(float64); ok {
+		object["id"] = strconv.FormatFloat(id, 'f', -1, 64)
+	}
+}
@@ -0,0 +1,79 @@
+package n8n
+
+import "path"
+
+// NodeFieldPrefix prefixes the names of node fields in a FieldPolicy
+const NodeFieldPrefix = "nodes."
+
+// ReadOnlyFields are the fields outside the API schema that n8n manages itself and changes whenever a
+// workflow is saved or triggered. CleanWorkflow removes them and DefaultFieldPolicy never sends them.
+var ReadOnlyFields = []string{"versionId", "activeVersionId", "versionCounter", "triggerCount"}
+
+// FieldPolicy decides which fields outside the API schema, kept in AdditionalProperties, are sent to n8n
+// when a workflow is created or updated. Workflow fields are named by their key and node fields by their
+// key prefixed with "nodes.", patterns like "nodes.*" are matched with path.Match. A field is sent when it
+// matches Allow and doesn't match Deny.
+type FieldPolicy struct {
+	Allow []string
+	Deny  []string
+}
+
+// DefaultFieldPolicy returns the policy used by clients, which sends no unknown fields because the n8n API
+// rejects the fields it doesn't know
+func DefaultFieldPolicy() FieldPolicy {
+	return FieldPolicy{Deny: ReadOnlyFields}
+}
+
+// Sends reports whether the named field is sent to n8n
+func (p FieldPolicy) Sends(field string) bool {
+	return matchesField(p.Allow, field) && !matchesField(p.Deny, field)
+}
+
+// Apply returns a copy of the workflow without the unknown workflow and node fields the policy doesn't send
+func (p FieldPolicy) Apply(workflow Workflow) Workflow {
+	filtered := workflow
+	filtered.AdditionalProperties = p.filter(workflow.AdditionalProperties, "")
+
+	if workflow.Nodes != nil {
+		filtered.Nodes = make([]Node, len(workflow.Nodes))
+		for i, node := range workflow.Nodes {
+			node.AdditionalProperties = p.filter(node.AdditionalProperties, NodeFieldPrefix)
+			filtered.Nodes[i] = node
+		}
+	}
+
+	return filtered
+}
+
+// filter returns the properties the policy sends, or nil when there are none
+func (p FieldPolicy) filter(properties map[string]interface{}, prefix string) map[string]interface{} {
+	return keepFields(properties, func(field string) bool {
+		return p.Sends(prefix + field)
+	})
+}
+
+// keepFields returns a copy of the properties with the fields for which keep returns true, or nil when
+// there are none, so workflows without unknown fields compare equal however they were filtered
+func keepFields(properties map[string]interface{}, keep func(field string) bool) map[string]interface{} {
+	var kept map[string]interface{}
+	for field, value := range properties {
+		if !keep(field) {
+			continue
+		}
+		if kept == nil {
+			kept = make(map[string]interface{})
+		}
+		kept[field] = value
+	}
+	return kept
+}
+
+// matchesField reports whether a field name matches one of the patterns
+func matchesField(patterns []string, field string) bool {
+	for _, pattern := range patterns {
+		if matched, err := path.Match(pattern, field); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
@@ -164,25 +164,26 @@ type Node struct {
 
 	// ContinueOnFail use onError instead
 	// Deprecated: this property has been marked as deprecated upstream, but no `x-deprecated-reason` was set
-	ContinueOnFail   *bool                   `json:"continueOnFail,omitempty"`
-	CreatedAt        *time.Time              `json:"createdAt,omitempty"`
-	Credentials      *map[string]interface{} `json:"credentials,omitempty"`
-	Disabled         *bool                   `json:"disabled,omitempty"`
-	ExecuteOnce      *bool                   `json:"executeOnce,omitempty"`
-	Id               *string                 `json:"id,omitempty"`
-	MaxTries         *float32                `json:"maxTries,omitempty"`
-	Name             *string                 `json:"name,omitempty"`
-	Notes            *string                 `json:"notes,omitempty"`
-	NotesInFlow      *bool                   `json:"notesInFlow,omitempty"`
-	OnError          *string                 `json:"onError,omitempty"`
-	Parameters       *map[string]interface{} `json:"parameters,omitempty"`
-	Position         *[]float32              `json:"position,omitempty"`
-	RetryOnFail      *bool                   `json:"retryOnFail,omitempty"`
-	Type             *string                 `json:"type,omitempty"`
-	TypeVersion      *float32                `json:"typeVersion,omitempty"`
-	UpdatedAt        *time.Time              `json:"updatedAt,omitempty"`
-	WaitBetweenTries *float32                `json:"waitBetweenTries,omitempty"`
-	WebhookId        *string                 `json:"webhookId,omitempty"`
+	ContinueOnFail       *bool                   `json:"continueOnFail,omitempty"`
+	CreatedAt            *time.Time              `json:"createdAt,omitempty"`
+	Credentials          *map[string]interface{} `json:"credentials,omitempty"`
+	Disabled             *bool                   `json:"disabled,omitempty"`
+	ExecuteOnce          *bool                   `json:"executeOnce,omitempty"`
+	Id                   *string                 `json:"id,omitempty"`
+	MaxTries             *float32                `json:"maxTries,omitempty"`
+	Name                 *string                 `json:"name,omitempty"`
+	Notes                *string                 `json:"notes,omitempty"`
+	NotesInFlow          *bool                   `json:"notesInFlow,omitempty"`
+	OnError              *string                 `json:"onError,omitempty"`
+	Parameters           *map[string]interface{} `json:"parameters,omitempty"`
+	Position             *[]float32              `json:"position,omitempty"`
+	RetryOnFail          *bool                   `json:"retryOnFail,omitempty"`
+	Type                 *string                 `json:"type,omitempty"`
+	TypeVersion          *float32                `json:"typeVersion,omitempty"`
+	UpdatedAt            *time.Time              `json:"updatedAt,omitempty"`
+	WaitBetweenTries     *float32                `json:"waitBetweenTries,omitempty"`
+	WebhookId            *string                 `json:"webhookId,omitempty"`
+	AdditionalProperties map[string]interface{}  `json:"-"`
 }
 
 // Project defines model for project.
@@ -288,17 +289,18 @@ type VariableList struct {
 
 // Workflow defines model for workflow.
 type Workflow struct {
-	Active      *bool                  `json:"active,omitempty"`
-	Connections map[string]interface{} `json:"connections"`
-	CreatedAt   *time.Time             `json:"createdAt,omitempty"`
-	Id          *string                `json:"id,omitempty"`
-	Name        string                 `json:"name"`
-	Nodes       []Node                 `json:"nodes"`
-	Settings    WorkflowSettings       `json:"settings"`
-	Shared      *[]SharedWorkflow      `json:"shared,omitempty"`
-	StaticData  *Workflow_StaticData   `json:"staticData,omitempty"`
-	Tags        *[]Tag                 `json:"tags,omitempty"`
-	UpdatedAt   *time.Time             `json:"updatedAt,omitempty"`
+	Active               *bool                  `json:"active,omitempty"`
+	Connections          map[string]interface{} `json:"connections"`
+	CreatedAt            *time.Time             `json:"createdAt,omitempty"`
+	Id                   *string                `json:"id,omitempty"`
+	Name                 string                 `json:"name"`
+	Nodes                []Node                 `json:"nodes"`
+	Settings             WorkflowSettings       `json:"settings"`
+	Shared               *[]SharedWorkflow      `json:"shared,omitempty"`
+	StaticData           *Workflow_StaticData   `json:"staticData,omitempty"`
+	Tags                 *[]Tag                 `json:"tags,omitempty"`
+	UpdatedAt            *time.Time             `json:"updatedAt,omitempty"`
+	AdditionalProperties map[string]interface{} `json:"-"`
 }
 
 // WorkflowStaticData0 defines model for .
@@ -699,6 +701,569 @@ func (a ImportResult) MarshalJSON() ([]byte, error) {
 	return json.Marshal(object)
 }
 
+// Getter for additional properties for Node. Returns the specified
+// element and whether it was found
+func (a Node) Get(fieldName string) (value interface{}, found bool) {
+	if a.AdditionalProperties != nil {
+		value, found = a.AdditionalProperties[fieldName]
+	}
+	return
+}
+
+// Setter for additional properties for Node
+func (a *Node) Set(fieldName string, value interface{}) {
+	if a.AdditionalProperties == nil {
+		a.AdditionalProperties = make(map[string]interface{})
+	}
+	a.AdditionalProperties[fieldName] = value
+}
+
+// Override default JSON handling for Node to handle AdditionalProperties
+func (a *Node) UnmarshalJSON(b []byte) error {
+	object := make(map[string]json.RawMessage)
+	err := json.Unmarshal(b, &object)
+	if err != nil {
+		return err
+	}
+
+	if raw, found := object["alwaysOutputData"]; found {
+		err = json.Unmarshal(raw, &a.AlwaysOutputData)
+		if err != nil {
+			return fmt.Errorf("error reading 'alwaysOutputData': %w", err)
+		}
+		delete(object, "alwaysOutputData")
+	}
+
+	if raw, found := object["continueOnFail"]; found {
+		err = json.Unmarshal(raw, &a.ContinueOnFail)
+		if err != nil {
+			return fmt.Errorf("error reading 'continueOnFail': %w", err)
+		}
+		delete(object, "continueOnFail")
+	}
+
+	if raw, found := object["createdAt"]; found {
+		err = json.Unmarshal(raw, &a.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("error reading 'createdAt': %w", err)
+		}
+		delete(object, "createdAt")
+	}
+
+	if raw, found := object["credentials"]; found {
+		err = json.Unmarshal(raw, &a.Credentials)
+		if err != nil {
+			return fmt.Errorf("error reading 'credentials': %w", err)
+		}
+		delete(object, "credentials")
+	}
+
+	if raw, found := object["disabled"]; found {
+		err = json.Unmarshal(raw, &a.Disabled)
+		if err != nil {
+			return fmt.Errorf("error reading 'disabled': %w", err)
+		}
+		delete(object, "disabled")
+	}
+
+	if raw, found := object["executeOnce"]; found {
+		err = json.Unmarshal(raw, &a.ExecuteOnce)
+		if err != nil {
+			return fmt.Errorf("error reading 'executeOnce': %w", err)
+		}
+		delete(object, "executeOnce")
+	}
+
+	if raw, found := object["id"]; found {
+		err = json.Unmarshal(raw, &a.Id)
+		if err != nil {
+			return fmt.Errorf("error reading 'id': %w", err)
+		}
+		delete(object, "id")
+	}
+
+	if raw, found := object["maxTries"]; found {
+		err = json.Unmarshal(raw, &a.MaxTries)
+		if err != nil {
+			return fmt.Errorf("error reading 'maxTries': %w", err)
+		}
+		delete(object, "maxTries")
+	}
+
+	if raw, found := object["name"]; found {
+		err = json.Unmarshal(raw, &a.Name)
+		if err != nil {
+			return fmt.Errorf("error reading 'name': %w", err)
+		}
+		delete(object, "name")
+	}
+
+	if raw, found := object["notes"]; found {
+		err = json.Unmarshal(raw, &a.Notes)
+		if err != nil {
+			return fmt.Errorf("error reading 'notes': %w", err)
+		}
+		delete(object, "notes")
+	}
+
+	if raw, found := object["notesInFlow"]; found {
+		err = json.Unmarshal(raw, &a.NotesInFlow)
+		if err != nil {
+			return fmt.Errorf("error reading 'notesInFlow': %w", err)
+		}
+		delete(object, "notesInFlow")
+	}
+
+	if raw, found := object["onError"]; found {
+		err = json.Unmarshal(raw, &a.OnError)
+		if err != nil {
+			return fmt.Errorf("error reading 'onError': %w", err)
+		}
+		delete(object, "onError")
+	}
+
+	if raw, found := object["parameters"]; found {
+		err = json.Unmarshal(raw, &a.Parameters)
+		if err != nil {
+			return fmt.Errorf("error reading 'parameters': %w", err)
+		}
+		delete(object, "parameters")
+	}
+
+	if raw, found := object["position"]; found {
+		err = json.Unmarshal(raw, &a.Position)
+		if err != nil {
+			return fmt.Errorf("error reading 'position': %w", err)
+		}
+		delete(object, "position")
+	}
+
+	if raw, found := object["retryOnFail"]; found {
+		err = json.Unmarshal(raw, &a.RetryOnFail)
+		if err != nil {
+			return fmt.Errorf("error reading 'retryOnFail': %w", err)
+		}
+		delete(object, "retryOnFail")
+	}
+
+	if raw, found := object["type"]; found {
+		err = json.Unmarshal(raw, &a.Type)
+		if err != nil {
+			return fmt.Errorf("error reading 'type': %w", err)
+		}
+		delete(object, "type")
+	}
+
+	if raw, found := object["typeVersion"]; found {
+		err = json.Unmarshal(raw, &a.TypeVersion)
+		if err != nil {
+			return fmt.Errorf("error reading 'typeVersion': %w", err)
+		}
+		delete(object, "typeVersion")
+	}
+
+	if raw, found := object["updatedAt"]; found {
+		err = json.Unmarshal(raw, &a.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("error reading 'updatedAt': %w", err)
+		}
+		delete(object, "updatedAt")
+	}
+
+	if raw, found := object["waitBetweenTries"]; found {
+		err = json.Unmarshal(raw, &a.WaitBetweenTries)
+		if err != nil {
+			return fmt.Errorf("error reading 'waitBetweenTries': %w", err)
+		}
+		delete(object, "waitBetweenTries")
+	}
+
+	if raw, found := object["webhookId"]; found {
+		err = json.Unmarshal(raw, &a.WebhookId)
+		if err != nil {
+			return fmt.Errorf("error reading 'webhookId': %w", err)
+		}
+		delete(object, "webhookId")
+	}
+
+	if len(object) != 0 {
+		a.AdditionalProperties = make(map[string]interface{})
+		for fieldName, fieldBuf := range object {
+			var fieldVal interface{}
+			err := json.Unmarshal(fieldBuf, &fieldVal)
+			if err != nil {
+				return fmt.Errorf("error unmarshaling field %s: %w", fieldName, err)
+			}
+			a.AdditionalProperties[fieldName] = fieldVal
+		}
+	}
+	return nil
+}
+
+// Override default JSON handling for Node to handle AdditionalProperties
+func (a Node) MarshalJSON() ([]byte, error) {
+	var err error
+	object := make(map[string]json.RawMessage)
+
+	if a.AlwaysOutputData != nil {
+		object["alwaysOutputData"], err = json.Marshal(a.AlwaysOutputData)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'alwaysOutputData': %w", err)
+		}
+	}
+
+	if a.ContinueOnFail != nil {
+		object["continueOnFail"], err = json.Marshal(a.ContinueOnFail)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'continueOnFail': %w", err)
+		}
+	}
+
+	if a.CreatedAt != nil {
+		object["createdAt"], err = json.Marshal(a.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'createdAt': %w", err)
+		}
+	}
+
+	if a.Credentials != nil {
+		object["credentials"], err = json.Marshal(a.Credentials)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'credentials': %w", err)
+		}
+	}
+
+	if a.Disabled != nil {
+		object["disabled"], err = json.Marshal(a.Disabled)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'disabled': %w", err)
+		}
+	}
+
+	if a.ExecuteOnce != nil {
+		object["executeOnce"], err = json.Marshal(a.ExecuteOnce)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'executeOnce': %w", err)
+		}
+	}
+
+	if a.Id != nil {
+		object["id"], err = json.Marshal(a.Id)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'id': %w", err)
+		}
+	}
+
+	if a.MaxTries != nil {
+		object["maxTries"], err = json.Marshal(a.MaxTries)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'maxTries': %w", err)
+		}
+	}
+
+	if a.Name != nil {
+		object["name"], err = json.Marshal(a.Name)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'name': %w", err)
+		}
+	}
+
+	if a.Notes != nil {
+		object["notes"], err = json.Marshal(a.Notes)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'notes': %w", err)
+		}
+	}
+
+	if a.NotesInFlow != nil {
+		object["notesInFlow"], err = json.Marshal(a.NotesInFlow)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'notesInFlow': %w", err)
+		}
+	}
+
+	if a.OnError != nil {
+		object["onError"], err = json.Marshal(a.OnError)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'onError': %w", err)
+		}
+	}
+
+	if a.Parameters != nil {
+		object["parameters"], err = json.Marshal(a.Parameters)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'parameters': %w", err)
+		}
+	}
+
+	if a.Position != nil {
+		object["position"], err = json.Marshal(a.Position)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'position': %w", err)
+		}
+	}
+
+	if a.RetryOnFail != nil {
+		object["retryOnFail"], err = json.Marshal(a.RetryOnFail)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'retryOnFail': %w", err)
+		}
+	}
+
+	if a.Type != nil {
+		object["type"], err = json.Marshal(a.Type)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'type': %w", err)
+		}
+	}
+
+	if a.TypeVersion != nil {
+		object["typeVersion"], err = json.Marshal(a.TypeVersion)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'typeVersion': %w", err)
+		}
+	}
+
+	if a.UpdatedAt != nil {
+		object["updatedAt"], err = json.Marshal(a.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'updatedAt': %w", err)
+		}
+	}
+
+	if a.WaitBetweenTries != nil {
+		object["waitBetweenTries"], err = json.Marshal(a.WaitBetweenTries)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'waitBetweenTries': %w", err)
+		}
+	}
+
+	if a.WebhookId != nil {
+		object["webhookId"], err = json.Marshal(a.WebhookId)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'webhookId': %w", err)
+		}
+	}
+
+	for fieldName, field := range a.AdditionalProperties {
+		object[fieldName], err = json.Marshal(field)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling '%s': %w", fieldName, err)
+		}
+	}
+	return json.Marshal(object)
+}
+
+// Getter for additional properties for Workflow. Returns the specified
+// element and whether it was found
+func (a Workflow) Get(fieldName string) (value interface{}, found bool) {
+	if a.AdditionalProperties != nil {
+		value, found = a.AdditionalProperties[fieldName]
+	}
+	return
+}
+
+// Setter for additional properties for Workflow
+func (a *Workflow) Set(fieldName string, value interface{}) {
+	if a.AdditionalProperties == nil {
+		a.AdditionalProperties = make(map[string]interface{})
+	}
+	a.AdditionalProperties[fieldName] = value
+}
+
+// Override default JSON handling for Workflow to handle AdditionalProperties
+func (a *Workflow) UnmarshalJSON(b []byte) error {
+	object := make(map[string]json.RawMessage)
+	err := json.Unmarshal(b, &object)
+	if err != nil {
+		return err
+	}
+
+	if raw, found := object["active"]; found {
+		err = json.Unmarshal(raw, &a.Active)
+		if err != nil {
+			return fmt.Errorf("error reading 'active': %w", err)
+		}
+		delete(object, "active")
+	}
+
+	if raw, found := object["connections"]; found {
+		err = json.Unmarshal(raw, &a.Connections)
+		if err != nil {
+			return fmt.Errorf("error reading 'connections': %w", err)
+		}
+		delete(object, "connections")
+	}
+
+	if raw, found := object["createdAt"]; found {
+		err = json.Unmarshal(raw, &a.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("error reading 'createdAt': %w", err)
+		}
+		delete(object, "createdAt")
+	}
+
+	if raw, found := object["id"]; found {
+		err = json.Unmarshal(raw, &a.Id)
+		if err != nil {
+			return fmt.Errorf("error reading 'id': %w", err)
+		}
+		delete(object, "id")
+	}
+
+	if raw, found := object["name"]; found {
+		err = json.Unmarshal(raw, &a.Name)
+		if err != nil {
+			return fmt.Errorf("error reading 'name': %w", err)
+		}
+		delete(object, "name")
+	}
+
+	if raw, found := object["nodes"]; found {
+		err = json.Unmarshal(raw, &a.Nodes)
+		if err != nil {
+			return fmt.Errorf("error reading 'nodes': %w", err)
+		}
+		delete(object, "nodes")
+	}
+
+	if raw, found := object["settings"]; found {
+		err = json.Unmarshal(raw, &a.Settings)
+		if err != nil {
+			return fmt.Errorf("error reading 'settings': %w", err)
+		}
+		delete(object, "settings")
+	}
+
+	if raw, found := object["shared"]; found {
+		err = json.Unmarshal(raw, &a.Shared)
+		if err != nil {
+			return fmt.Errorf("error reading 'shared': %w", err)
+		}
+		delete(object, "shared")
+	}
+
+	if raw, found := object["staticData"]; found {
+		err = json.Unmarshal(raw, &a.StaticData)
+		if err != nil {
+			return fmt.Errorf("error reading 'staticData': %w", err)
+		}
+		delete(object, "staticData")
+	}
+
+	if raw, found := object["tags"]; found {
+		err = json.Unmarshal(raw, &a.Tags)
+		if err != nil {
+			return fmt.Errorf("error reading 'tags': %w", err)
+		}
+		delete(object, "tags")
+	}
+
+	if raw, found := object["updatedAt"]; found {
+		err = json.Unmarshal(raw, &a.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("error reading 'updatedAt': %w", err)
+		}
+		delete(object, "updatedAt")
+	}
+
+	if len(object) != 0 {
+		a.AdditionalProperties = make(map[string]interface{})
+		for fieldName, fieldBuf := range object {
+			var fieldVal interface{}
+			err := json.Unmarshal(fieldBuf, &fieldVal)
+			if err != nil {
+				return fmt.Errorf("error unmarshaling field %s: %w", fieldName, err)
+			}
+			a.AdditionalProperties[fieldName] = fieldVal
+		}
+	}
+	return nil
+}
+
+// Override default JSON handling for Workflow to handle AdditionalProperties
+func (a Workflow) MarshalJSON() ([]byte, error) {
+	var err error
+	object := make(map[string]json.RawMessage)
+
+	if a.Active != nil {
+		object["active"], err = json.Marshal(a.Active)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'active': %w", err)
+		}
+	}
+
+	object["connections"], err = json.Marshal(a.Connections)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling 'connections': %w", err)
+	}
+
+	if a.CreatedAt != nil {
+		object["createdAt"], err = json.Marshal(a.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'createdAt': %w", err)
+		}
+	}
+
+	if a.Id != nil {
+		object["id"], err = json.Marshal(a.Id)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'id': %w", err)
+		}
+	}
+
+	object["name"], err = json.Marshal(a.Name)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling 'name': %w", err)
+	}
+
+	object["nodes"], err = json.Marshal(a.Nodes)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling 'nodes': %w", err)
+	}
+
+	object["settings"], err = json.Marshal(a.Settings)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling 'settings': %w", err)
+	}
+
+	if a.Shared != nil {
+		object["shared"], err = json.Marshal(a.Shared)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'shared': %w", err)
+		}
+	}
+
+	if a.StaticData != nil {
+		object["staticData"], err = json.Marshal(a.StaticData)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'staticData': %w", err)
+		}
+	}
+
+	if a.Tags != nil {
+		object["tags"], err = json.Marshal(a.Tags)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'tags': %w", err)
+		}
+	}
+
+	if a.UpdatedAt != nil {
+		object["updatedAt"], err = json.Marshal(a.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'updatedAt': %w", err)
+		}
+	}
+
+	for fieldName, field := range a.AdditionalProperties {
+		object[fieldName], err = json.Marshal(field)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling '%s': %w", fieldName, err)
+		}
+	}
+	return json.Marshal(object)
+}
+
 // AsWorkflowStaticData0 returns the union data inside the Workflow_StaticData as a WorkflowStaticData0
 func (t Workflow_StaticData) AsWorkflowStaticData0() (WorkflowStaticData0, error) {
 	var body WorkflowStaticData0
@@ -9,6 +9,8 @@ type ClientInterface interface {
 	// If limit is nil, uses the API's default (100)
 	// If limit is provided, returns up to that many workflows (max 250)
 	GetWorkflows(limit *int) (*WorkflowList, error)
+	// GetAllWorkflows fetches every workflow, following the cursor through all pages
+	GetAllWorkflows() (*WorkflowList, error)
 	// GetWorkflow fetches a single workflow by its ID
 	GetWorkflow(id string) (*Workflow, error)
 	// ActivateWorkflow activates a workflow by its ID
@@ -31,8 +33,14 @@ type ClientInterface interface {
 	UpdateWorkflowTags(id string, tagIds TagIds) (WorkflowTags, error)
 	// CreateTag creates a new tag in n8n
 	CreateTag(tagName string) (*Tag, error)
+	// DeleteTag deletes a tag by ID
+	DeleteTag(id string) error
 	// GetTags fetches all tags from n8n
 	GetTags() (*TagList, error)
+	// GetVariables fetches all variables from n8n
+	GetVariables() (*VariableList, error)
+	// CreateVariable creates a new variable in n8n
+	CreateVariable(variable Variable) error
 }
 
 // Ensure Client implements ClientInterface
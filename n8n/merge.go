@@ -0,0 +1,234 @@
+package n8n
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Prefixes of the keys used to split a workflow document into parts that are compared independently
+const (
+	nodePartPrefix       = "node:"
+	connectionPartPrefix = "connection:"
+	fieldPartPrefix      = "field:"
+)
+
+// workflowContent returns the content of a workflow without the fields n8n manages itself,
+// like the ID, timestamps, activation, tags and the static data triggers update while running,
+// as a generic document
+func workflowContent(workflow Workflow) (map[string]interface{}, error) {
+	content := CleanWorkflow(workflow)
+	content.Id = nil
+	content.Active = nil
+	content.Tags = nil
+	content.StaticData = nil
+
+	data, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var document map[string]interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	return document, nil
+}
+
+// WorkflowContentHash returns a hash of the workflow content that changes whenever nodes,
+// connections or settings change, but not when the workflow is only activated or tagged
+func WorkflowContentHash(workflow Workflow) (string, error) {
+	document, err := workflowContent(workflow)
+	if err != nil {
+		return "", fmt.Errorf("error hashing workflow '%s': %w", workflow.Name, err)
+	}
+
+	data, err := json.Marshal(document)
+	if err != nil {
+		return "", fmt.Errorf("error hashing workflow '%s': %w", workflow.Name, err)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// workflowParts splits a workflow document into its nodes by name, its connections by source node and its other fields
+func workflowParts(document map[string]interface{}) (map[string]interface{}, []string) {
+	parts := make(map[string]interface{})
+	var nodeOrder []string
+
+	for key, value := range document {
+		switch key {
+		case "nodes":
+			nodes, _ := value.([]interface{})
+			for _, node := range nodes {
+				name, _ := nodeName(node)
+				parts[nodePartPrefix+name] = node
+				nodeOrder = append(nodeOrder, name)
+			}
+		case "connections":
+			connections, _ := value.(map[string]interface{})
+			for source, connection := range connections {
+				parts[connectionPartPrefix+source] = connection
+			}
+		default:
+			parts[fieldPartPrefix+key] = value
+		}
+	}
+
+	return parts, nodeOrder
+}
+
+// partLabel returns a human readable label for a workflow part key
+func partLabel(key string) string {
+	switch {
+	case strings.HasPrefix(key, nodePartPrefix):
+		return fmt.Sprintf("node '%s'", strings.TrimPrefix(key, nodePartPrefix))
+	case strings.HasPrefix(key, connectionPartPrefix):
+		return fmt.Sprintf("connections of '%s'", strings.TrimPrefix(key, connectionPartPrefix))
+	default:
+		return strings.TrimPrefix(key, fieldPartPrefix)
+	}
+}
+
+// samePart reports whether a part has the same presence and value in two workflows
+func samePart(a map[string]interface{}, b map[string]interface{}, key string) bool {
+	aValue, aOk := a[key]
+	bValue, bOk := b[key]
+	return aOk == bOk && reflect.DeepEqual(aValue, bValue)
+}
+
+// partKeys returns the sorted union of the part keys of the given workflows
+func partKeys(parts ...map[string]interface{}) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, p := range parts {
+		for key := range p {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ChangedParts lists the nodes, connections and fields that differ between a base workflow and another version of it
+func ChangedParts(base Workflow, other Workflow) ([]string, error) {
+	baseDocument, err := workflowContent(base)
+	if err != nil {
+		return nil, err
+	}
+	otherDocument, err := workflowContent(other)
+	if err != nil {
+		return nil, err
+	}
+
+	baseParts, _ := workflowParts(baseDocument)
+	otherParts, _ := workflowParts(otherDocument)
+
+	var changed []string
+	for _, key := range partKeys(baseParts, otherParts) {
+		if !samePart(baseParts, otherParts, key) {
+			changed = append(changed, partLabel(key))
+		}
+	}
+
+	return changed, nil
+}
+
+// MergeWorkflows performs a three-way merge of the local and remote versions of a workflow
+// against the version both were derived from. Nodes are merged by name, connections by their
+// source node and the remaining fields as a whole. A part changed on only one side takes that
+// change, a part changed differently on both sides is reported as a conflict.
+// The ID, activation and tags of the local version are kept.
+func MergeWorkflows(base Workflow, local Workflow, remote Workflow) (Workflow, []string, error) {
+	baseDocument, err := workflowContent(base)
+	if err != nil {
+		return Workflow{}, nil, err
+	}
+	localDocument, err := workflowContent(local)
+	if err != nil {
+		return Workflow{}, nil, err
+	}
+	remoteDocument, err := workflowContent(remote)
+	if err != nil {
+		return Workflow{}, nil, err
+	}
+
+	baseParts, _ := workflowParts(baseDocument)
+	localParts, localOrder := workflowParts(localDocument)
+	remoteParts, remoteOrder := workflowParts(remoteDocument)
+
+	merged := make(map[string]interface{})
+	var conflicts []string
+
+	for _, key := range partKeys(baseParts, localParts, remoteParts) {
+		source := localParts
+		switch {
+		case samePart(localParts, remoteParts, key), samePart(baseParts, remoteParts, key):
+			source = localParts
+		case samePart(baseParts, localParts, key):
+			source = remoteParts
+		default:
+			conflicts = append(conflicts, partLabel(key))
+			continue
+		}
+
+		if value, ok := source[key]; ok {
+			merged[key] = value
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return Workflow{}, conflicts, nil
+	}
+
+	document := make(map[string]interface{})
+	nodes := []interface{}{}
+	connections := make(map[string]interface{})
+	added := make(map[string]bool)
+
+	for _, name := range append(localOrder, remoteOrder...) {
+		node, ok := merged[nodePartPrefix+name]
+		if !ok || added[name] {
+			continue
+		}
+		added[name] = true
+		nodes = append(nodes, node)
+	}
+
+	for key, value := range merged {
+		switch {
+		case strings.HasPrefix(key, connectionPartPrefix):
+			connections[strings.TrimPrefix(key, connectionPartPrefix)] = value
+		case strings.HasPrefix(key, fieldPartPrefix):
+			document[strings.TrimPrefix(key, fieldPartPrefix)] = value
+		}
+	}
+	document["nodes"] = nodes
+	document["connections"] = connections
+
+	data, err := json.Marshal(document)
+	if err != nil {
+		return Workflow{}, nil, fmt.Errorf("error encoding merged workflow: %w", err)
+	}
+
+	var result Workflow
+	if err := json.Unmarshal(data, &result); err != nil {
+		return Workflow{}, nil, fmt.Errorf("error decoding merged workflow: %w", err)
+	}
+
+	result.Id = local.Id
+	result.Active = local.Active
+	result.Tags = local.Tags
+	result.StaticData = local.StaticData
+
+	return result, nil, nil
+}
@@ -0,0 +1,184 @@
+package n8n
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// variablePattern matches $${VAR} escapes, ${VAR} and ${VAR:-default} placeholders
+var variablePattern = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// ApplyOverlay merges an overlay document into a base workflow document.
+// Maps are merged recursively and a null value removes the key from the base.
+// The nodes list is merged by node name, so an overlay only needs to describe the nodes it changes.
+// Any other list in the overlay replaces the list in the base.
+func ApplyOverlay(base map[string]interface{}, overlay map[string]interface{}) map[string]interface{} {
+	merged := mergeMaps(base, overlay)
+
+	baseNodes, baseOk := base["nodes"].([]interface{})
+	overlayNodes, overlayOk := overlay["nodes"].([]interface{})
+	if baseOk && overlayOk {
+		merged["nodes"] = mergeNodes(baseNodes, overlayNodes)
+	}
+
+	return merged
+}
+
+// mergeMaps recursively merges the overlay map into a copy of the base map
+func mergeMaps(base map[string]interface{}, overlay map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base))
+	for key, value := range base {
+		merged[key] = value
+	}
+
+	for key, value := range overlay {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+
+		baseMap, baseIsMap := merged[key].(map[string]interface{})
+		overlayMap, overlayIsMap := value.(map[string]interface{})
+		if baseIsMap && overlayIsMap {
+			merged[key] = mergeMaps(baseMap, overlayMap)
+			continue
+		}
+
+		merged[key] = value
+	}
+
+	return merged
+}
+
+// mergeNodes merges overlay nodes into base nodes by name, appending nodes the base doesn't have
+func mergeNodes(base []interface{}, overlay []interface{}) []interface{} {
+	merged := make([]interface{}, len(base))
+	copy(merged, base)
+
+	index := make(map[string]int, len(base))
+	for i, node := range base {
+		if name, ok := nodeName(node); ok {
+			index[name] = i
+		}
+	}
+
+	for _, node := range overlay {
+		name, ok := nodeName(node)
+		if !ok {
+			merged = append(merged, node)
+			continue
+		}
+
+		i, exists := index[name]
+		if !exists {
+			index[name] = len(merged)
+			merged = append(merged, node)
+			continue
+		}
+
+		baseNode, _ := merged[i].(map[string]interface{})
+		merged[i] = mergeMaps(baseNode, node.(map[string]interface{}))
+	}
+
+	return merged
+}
+
+// nodeName returns the name of a node document if it has one
+func nodeName(node interface{}) (string, bool) {
+	nodeMap, ok := node.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+
+	name, ok := nodeMap["name"].(string)
+	return name, ok && name != ""
+}
+
+// ExpandVariables replaces ${VAR} and ${VAR:-default} placeholders in every string of a document.
+// As in the shell, the default is used when VAR is unset or empty. A placeholder can be kept literally
+// by escaping it as $${VAR}. Code parameters of nodes, such as jsCode, are left as they are, since
+// ${...} is part of the language there, for example in JavaScript template literals.
+// All variables that cannot be resolved and have no default are reported in a single error.
+func ExpandVariables(document interface{}, lookup func(string) (string, bool)) (interface{}, error) {
+	missing := make(map[string]bool)
+	expanded := expandValue(document, lookup, missing)
+
+	if len(missing) > 0 {
+		names := make([]string, 0, len(missing))
+		for name := range missing {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unresolved variables: %s", strings.Join(names, ", "))
+	}
+
+	return expanded, nil
+}
+
+// expandValue walks a decoded document and expands placeholders in strings and map keys
+func expandValue(value interface{}, lookup func(string) (string, bool), missing map[string]bool) interface{} {
+	switch v := value.(type) {
+	case string:
+		return expandString(v, lookup, missing)
+	case map[string]interface{}:
+		expanded := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if parameters, ok := item.(map[string]interface{}); ok && key == "parameters" {
+				if nodeType, ok := v["type"].(string); ok {
+					expanded[key] = expandParameters(nodeType, parameters, lookup, missing)
+					continue
+				}
+			}
+			expanded[expandString(key, lookup, missing)] = expandValue(item, lookup, missing)
+		}
+		return expanded
+	case []interface{}:
+		expanded := make([]interface{}, len(v))
+		for i, item := range v {
+			expanded[i] = expandValue(item, lookup, missing)
+		}
+		return expanded
+	default:
+		return value
+	}
+}
+
+// expandParameters expands the parameters of a node of the given type, except its code parameters
+func expandParameters(nodeType string, parameters map[string]interface{}, lookup func(string) (string, bool), missing map[string]bool) map[string]interface{} {
+	expanded := make(map[string]interface{}, len(parameters))
+	for key, item := range parameters {
+		if isCodeParameter(nodeType, key) {
+			expanded[key] = item
+			continue
+		}
+		expanded[expandString(key, lookup, missing)] = expandValue(item, lookup, missing)
+	}
+	return expanded
+}
+
+// expandString expands the placeholders of a single string
+func expandString(value string, lookup func(string) (string, bool), missing map[string]bool) string {
+	if !strings.Contains(value, "${") {
+		return value
+	}
+
+	return variablePattern.ReplaceAllStringFunc(value, func(match string) string {
+		if strings.HasPrefix(match, "$$") {
+			return match[1:]
+		}
+
+		groups := variablePattern.FindStringSubmatch(match)
+		if resolved, ok := lookup(groups[1]); ok && (resolved != "" || groups[2] == "") {
+			return resolved
+		}
+
+		if groups[2] != "" {
+			return groups[3]
+		}
+
+		missing[groups[1]] = true
+		return match
+	})
+}
@@ -0,0 +1,346 @@
+package n8n
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SchemaDraft is the JSON Schema dialect of the schemas describing the workflow file format
+const SchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// schemaEnums are the values of the string types with a fixed set of values
+var schemaEnums = map[reflect.Type][]string{
+	reflect.TypeOf(WorkflowSettingsSaveDataErrorExecutionAll): {
+		string(WorkflowSettingsSaveDataErrorExecutionAll),
+		string(WorkflowSettingsSaveDataErrorExecutionNone),
+	},
+	reflect.TypeOf(WorkflowSettingsSaveDataSuccessExecutionAll): {
+		string(WorkflowSettingsSaveDataSuccessExecutionAll),
+		string(WorkflowSettingsSaveDataSuccessExecutionNone),
+	},
+}
+
+// schemaDescriptions describe the fields of workflow files, keyed by type and JSON field name
+var schemaDescriptions = map[string]string{
+	"Workflow.id":                    "ID of the workflow in n8n, set by n8n when the workflow is created",
+	"Workflow.name":                  "Name of the workflow",
+	"Workflow.active":                "Whether the workflow is active and runs on its triggers",
+	"Workflow.nodes":                 "Nodes of the workflow",
+	"Workflow.connections":           "Connections between the nodes, keyed by the name of the source node",
+	"Workflow.settings":              "Settings of the workflow",
+	"Workflow.tags":                  "Tags of the workflow",
+	"Workflow.staticData":            "Static data of the workflow, managed by n8n",
+	"Node.name":                      "Name of the node, unique within the workflow",
+	"Node.type":                      "Type of the node, such as n8n-nodes-base.code",
+	"Node.typeVersion":               "Version of the node type",
+	"Node.parameters":                "Parameters of the node, which depend on its type",
+	"Node.credentials":               "Credentials of the node, keyed by credential type",
+	"Node.position":                  "Position of the node in the editor as [x, y]",
+	"Node.onError":                   "What the node does when it fails",
+	"WorkflowSettings.errorWorkflow": "The ID of the workflow that contains the error trigger node",
+	"WorkflowSettings.timezone":      "Timezone of the workflow, such as Europe/Berlin",
+}
+
+// schemaDefaultedFields are required by the API but filled in by the CLI when a workflow file doesn't set them
+var schemaDefaultedFields = map[string]bool{
+	"Workflow.connections": true,
+	"Workflow.settings":    true,
+}
+
+// schemaFieldOverrides replace the schemas derived from the types of fields that the API types don't describe
+var schemaFieldOverrides = map[string]func() map[string]interface{}{
+	"Workflow.connections": connectionsSchema,
+	"Node.onError": func() map[string]interface{} {
+		return map[string]interface{}{
+			"type": "string",
+			"enum": []string{"stopWorkflow", "continueRegularOutput", "continueErrorOutput"},
+		}
+	},
+}
+
+// nodeParameterSchemas are the schemas of the parameters of node types beyond their code parameters
+var nodeParameterSchemas = map[string]map[string]interface{}{
+	"n8n-nodes-base.code": {
+		"mode": map[string]interface{}{
+			"type": "string",
+			"enum": []string{"runOnceForAllItems", "runOnceForEachItem"},
+		},
+		"language": map[string]interface{}{
+			"type": "string",
+			"enum": []string{"javaScript", "python", "pythonNative"},
+		},
+	},
+	"n8n-nodes-base.executeWorkflow": {
+		"source": map[string]interface{}{
+			"type": "string",
+			"enum": []string{"database", "localFile", "parameter", "url"},
+		},
+		"workflowId": map[string]interface{}{
+			"description": "ID of the called workflow, or a resource locator holding it",
+			"anyOf": []interface{}{
+				map[string]interface{}{"type": "string"},
+				map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"__rl":  map[string]interface{}{"type": "boolean"},
+						"mode":  map[string]interface{}{"type": "string"},
+						"value": map[string]interface{}{"type": "string"},
+					},
+					"required": []string{"value"},
+				},
+			},
+		},
+	},
+	"n8n-nodes-base.webhook": {
+		"path": map[string]interface{}{"type": "string"},
+		"httpMethod": map[string]interface{}{
+			"type": "string",
+			"enum": []string{"DELETE", "GET", "HEAD", "PATCH", "POST", "PUT"},
+		},
+	},
+}
+
+// WorkflowSchema returns a JSON Schema of workflow files, derived from the Workflow, Node and WorkflowSettings
+// types with schemas of the parameters of known node types. Code parameters may be inline or reference the
+// file holding them, and fields unknown to the schema are allowed on workflows and nodes.
+func WorkflowSchema() map[string]interface{} {
+	definitions := make(map[string]interface{})
+	schema := structSchema(reflect.TypeOf(Workflow{}), definitions)
+	schema["$schema"] = SchemaDraft
+	schema["title"] = "n8n workflow"
+	schema["description"] = "A workflow file managed by the n8n CLI"
+
+	node := definitions["node"].(map[string]interface{})
+	node["allOf"] = nodeTypeSchemas()
+	properties := node["properties"].(map[string]interface{})
+	properties["parameters"] = mergeSchema(properties["parameters"], map[string]interface{}{
+		"properties": codeParameterSchemas(""),
+	})
+
+	schema["definitions"] = definitions
+	return schema
+}
+
+// MarshalWorkflowSchema returns the indented JSON of the workflow file schema
+func MarshalWorkflowSchema() ([]byte, error) {
+	data, err := json.MarshalIndent(WorkflowSchema(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// structSchema returns the object schema of a struct type, adding the schemas of named struct types it uses
+// to the definitions
+func structSchema(t reflect.Type, definitions map[string]interface{}) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var required []string
+	additional := false
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Name == "AdditionalProperties" {
+			additional = true
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+
+		key := t.Name() + "." + name
+		var property map[string]interface{}
+		if override, ok := schemaFieldOverrides[key]; ok {
+			property = override()
+		} else {
+			property = typeSchema(field.Type, definitions)
+		}
+		if description, ok := schemaDescriptions[key]; ok {
+			property["description"] = description
+		}
+		properties[name] = property
+
+		if field.Type.Kind() != reflect.Ptr && options != "omitempty" && !schemaDefaultedFields[key] {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": additional,
+	}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema
+}
+
+// typeSchema returns the schema of a Go type as encoded to JSON
+func typeSchema(t reflect.Type, definitions map[string]interface{}) map[string]interface{} {
+	switch t {
+	case reflect.TypeOf(time.Time{}):
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case reflect.TypeOf(Workflow_StaticData{}):
+		return map[string]interface{}{"type": []string{"string", "object", "null"}}
+	}
+	if values, ok := schemaEnums[t]; ok {
+		return map[string]interface{}{"type": "string", "enum": values}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return typeSchema(t.Elem(), definitions)
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem(), definitions)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem(), definitions)}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return structSchema(t, definitions)
+		}
+		name := strings.ToLower(t.Name()[:1]) + t.Name()[1:]
+		if _, ok := definitions[name]; !ok {
+			definitions[name] = nil
+			definitions[name] = structSchema(t, definitions)
+		}
+		return map[string]interface{}{"$ref": "#/definitions/" + name}
+	}
+	return map[string]interface{}{}
+}
+
+// connectionsSchema returns the schema of workflow connections, which map the outputs of source nodes to the
+// inputs of destination nodes
+func connectionsSchema() map[string]interface{} {
+	connection := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"node":  map[string]interface{}{"type": "string", "description": "Name of the destination node"},
+			"type":  map[string]interface{}{"type": "string"},
+			"index": map[string]interface{}{"type": "integer"},
+		},
+		"required": []string{"node"},
+	}
+
+	return map[string]interface{}{
+		"type": "object",
+		"additionalProperties": map[string]interface{}{
+			"type": "object",
+			"additionalProperties": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type":  []string{"array", "null"},
+					"items": connection,
+				},
+			},
+		},
+	}
+}
+
+// codeParameterSchemas returns the schemas of the code parameters of a node type, or of the code parameters
+// of any node type when it is empty
+func codeParameterSchemas(nodeType string) map[string]interface{} {
+	properties := make(map[string]interface{})
+	for _, param := range codeParameters {
+		if (nodeType == "" && len(param.nodeTypes) == 0) || (nodeType != "" && containsString(param.nodeTypes, nodeType)) {
+			properties[param.key] = map[string]interface{}{
+				"description": "Inline source, or a reference to the " + param.ext + " file holding it",
+				"anyOf": []interface{}{
+					map[string]interface{}{"type": "string"},
+					map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							CodeFileKey: map[string]interface{}{"type": "string"},
+						},
+						"required":             []string{CodeFileKey},
+						"additionalProperties": false,
+					},
+				},
+			}
+		}
+	}
+	return properties
+}
+
+// nodeTypeSchemas returns the conditional schemas applying the parameter schemas of known node types
+func nodeTypeSchemas() []interface{} {
+	parameters := make(map[string]map[string]interface{})
+	for nodeType, properties := range nodeParameterSchemas {
+		parameters[nodeType] = make(map[string]interface{})
+		for key, property := range properties {
+			parameters[nodeType][key] = property
+		}
+	}
+	for _, param := range codeParameters {
+		for _, nodeType := range param.nodeTypes {
+			if parameters[nodeType] == nil {
+				parameters[nodeType] = make(map[string]interface{})
+			}
+			for key, property := range codeParameterSchemas(nodeType) {
+				parameters[nodeType][key] = property
+			}
+		}
+	}
+
+	nodeTypes := make([]string, 0, len(parameters))
+	for nodeType := range parameters {
+		nodeTypes = append(nodeTypes, nodeType)
+	}
+	sort.Strings(nodeTypes)
+
+	schemas := make([]interface{}, 0, len(nodeTypes))
+	for _, nodeType := range nodeTypes {
+		schemas = append(schemas, map[string]interface{}{
+			"if": map[string]interface{}{
+				"properties": map[string]interface{}{
+					"type": map[string]interface{}{"const": nodeType},
+				},
+				"required": []string{"type"},
+			},
+			"then": map[string]interface{}{
+				"properties": map[string]interface{}{
+					"parameters": map[string]interface{}{"properties": parameters[nodeType]},
+				},
+			},
+		})
+	}
+	return schemas
+}
+
+// mergeSchema returns a copy of a schema with the given keywords added
+func mergeSchema(schema interface{}, keywords map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{})
+	if base, ok := schema.(map[string]interface{}); ok {
+		for key, value := range base {
+			merged[key] = value
+		}
+	}
+	for key, value := range keywords {
+		merged[key] = value
+	}
+	return merged
+}
+
+// containsString reports whether a slice contains a string
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,286 @@
+package n8n
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PatchYAML applies the values of the updated YAML document to the existing one and returns the result.
+// Comments, key order and the style of strings in the existing document are kept, and only values that
+// changed are replaced. Keys missing from the updated document are removed and new keys are appended.
+// Lists of named items, like nodes, are matched by name, other lists by position.
+func PatchYAML(existing []byte, updated []byte) ([]byte, error) {
+	var existingDoc yaml.Node
+	if err := yaml.Unmarshal(existing, &existingDoc); err != nil {
+		return nil, fmt.Errorf("failed to decode existing YAML: %w", err)
+	}
+
+	var updatedDoc yaml.Node
+	if err := yaml.Unmarshal(updated, &updatedDoc); err != nil {
+		return nil, fmt.Errorf("failed to decode updated YAML: %w", err)
+	}
+
+	// Documents written in flow style, like JSON in a YAML file, are replaced
+	if existingDoc.Kind == 0 || len(existingDoc.Content) == 0 || existingDoc.Content[0].Style&yaml.FlowStyle != 0 {
+		return updated, nil
+	}
+
+	// The decoder attaches comments above the document start marker to the first key, they are written
+	// back above the marker instead
+	preamble, comments := yamlPreamble(existing)
+	if mapping := existingDoc.Content[0]; len(comments) > 0 && mapping.Kind == yaml.MappingNode && len(mapping.Content) > 0 {
+		lines := strings.Split(mapping.Content[0].HeadComment, "\n")
+		if len(lines) >= len(comments) && strings.Join(lines[:len(comments)], "\n") == strings.Join(comments, "\n") {
+			mapping.Content[0].HeadComment = strings.TrimLeft(strings.Join(lines[len(comments):], "\n"), "\n")
+		}
+	}
+
+	patchNode(&existingDoc, &updatedDoc)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(&existingDoc); err != nil {
+		return nil, fmt.Errorf("failed to encode patched YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finalize patched YAML: %w", err)
+	}
+
+	if preamble != nil {
+		return append(preamble, buf.Bytes()...), nil
+	}
+	return buf.Bytes(), nil
+}
+
+// yamlPreamble returns the comments and blank lines up to and including the document start marker, and the
+// comment lines among them. The preamble is nil when the document has no start marker.
+func yamlPreamble(data []byte) ([]byte, []string) {
+	var comments []string
+	offset := 0
+	for offset < len(data) {
+		end := bytes.IndexByte(data[offset:], '\n')
+		if end < 0 {
+			end = len(data) - offset
+		} else {
+			end++
+		}
+
+		line := strings.TrimSpace(string(data[offset : offset+end]))
+		switch {
+		case line == "---":
+			preamble := make([]byte, offset+end)
+			copy(preamble, data[:offset+end])
+			if !bytes.HasSuffix(preamble, []byte("\n")) {
+				preamble = append(preamble, '\n')
+			}
+			return preamble, comments
+		case strings.HasPrefix(line, "#"):
+			comments = append(comments, line)
+		case line != "":
+			return nil, nil
+		}
+		offset += end
+	}
+
+	return nil, nil
+}
+
+// patchNode applies an updated node to an existing node in place
+func patchNode(existing *yaml.Node, updated *yaml.Node) {
+	if existing.Kind != updated.Kind {
+		replaceNode(existing, updated)
+		return
+	}
+
+	// Empty flow collections like {} and [] take the style of the updated collection
+	if existing.Style&yaml.FlowStyle != 0 && len(existing.Content) == 0 {
+		replaceNode(existing, updated)
+		return
+	}
+
+	switch existing.Kind {
+	case yaml.DocumentNode:
+		if len(existing.Content) == 0 || len(updated.Content) == 0 {
+			replaceNode(existing, updated)
+			return
+		}
+		patchNode(existing.Content[0], updated.Content[0])
+	case yaml.MappingNode:
+		patchMapping(existing, updated)
+	case yaml.SequenceNode:
+		if names, ok := itemNames(existing); ok {
+			if updatedNames, ok := itemNames(updated); ok {
+				patchNamedSequence(existing, updated, names, updatedNames)
+				return
+			}
+		}
+		patchSequence(existing, updated)
+	case yaml.ScalarNode:
+		patchScalar(existing, updated)
+	default:
+		replaceNode(existing, updated)
+	}
+}
+
+// replaceNode replaces an existing node with an updated one, keeping the comments of the existing node
+func replaceNode(existing *yaml.Node, updated *yaml.Node) {
+	headComment, lineComment, footComment := existing.HeadComment, existing.LineComment, existing.FootComment
+	*existing = *updated
+	existing.HeadComment, existing.LineComment, existing.FootComment = headComment, lineComment, footComment
+}
+
+// patchScalar replaces the value of a scalar when it changed. The style of the existing scalar is kept
+// when the type stays the same, so block and quoted strings stay block and quoted strings, unless a
+// quoted string becomes multi-line.
+func patchScalar(existing *yaml.Node, updated *yaml.Node) {
+	if existing.ShortTag() == updated.ShortTag() && existing.Value == updated.Value {
+		return
+	}
+
+	if existing.ShortTag() != updated.ShortTag() || existing.Style == 0 {
+		replaceNode(existing, updated)
+		return
+	}
+
+	// Quoted strings that become multi-line are written as blocks
+	multiLine := strings.Contains(updated.Value, "\n")
+	if multiLine && existing.Style&(yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+		existing.Style = updated.Style
+	}
+
+	existing.Value = updated.Value
+}
+
+// patchMapping patches the values of the keys in both mappings, removes the keys missing from the updated
+// mapping and appends new keys
+func patchMapping(existing *yaml.Node, updated *yaml.Node) {
+	updatedValues := make(map[string]*yaml.Node, len(updated.Content)/2)
+	for i := 0; i+1 < len(updated.Content); i += 2 {
+		updatedValues[updated.Content[i].Value] = updated.Content[i+1]
+	}
+
+	seen := make(map[string]bool, len(existing.Content)/2)
+	content := make([]*yaml.Node, 0, len(existing.Content))
+	for i := 0; i+1 < len(existing.Content); i += 2 {
+		key, value := existing.Content[i], existing.Content[i+1]
+		updatedValue, ok := updatedValues[key.Value]
+		if !ok {
+			continue
+		}
+
+		seen[key.Value] = true
+		patchNode(value, updatedValue)
+		content = append(content, key, value)
+	}
+
+	for i := 0; i+1 < len(updated.Content); i += 2 {
+		if !seen[updated.Content[i].Value] {
+			content = append(content, updated.Content[i], updated.Content[i+1])
+		}
+	}
+
+	existing.Content = content
+}
+
+// patchSequence patches the items of two sequences by position
+func patchSequence(existing *yaml.Node, updated *yaml.Node) {
+	content := make([]*yaml.Node, 0, len(updated.Content))
+	for i, item := range updated.Content {
+		if i < len(existing.Content) {
+			patchNode(existing.Content[i], item)
+			content = append(content, existing.Content[i])
+			continue
+		}
+		content = append(content, item)
+	}
+	existing.Content = content
+}
+
+// patchNamedSequence patches the items of two sequences of named mappings by name. Items keep their order in
+// the existing sequence, new items are inserted after the item they follow in the updated sequence.
+func patchNamedSequence(existing *yaml.Node, updated *yaml.Node, names []string, updatedNames []string) {
+	updatedItems := make(map[string]*yaml.Node, len(updated.Content))
+	for i, name := range updatedNames {
+		updatedItems[name] = updated.Content[i]
+	}
+
+	var content []*yaml.Node
+	var order []string
+	for i, name := range names {
+		updatedItem, ok := updatedItems[name]
+		if !ok {
+			continue
+		}
+		patchNode(existing.Content[i], updatedItem)
+		content = append(content, existing.Content[i])
+		order = append(order, name)
+	}
+
+	for i, name := range updatedNames {
+		if containsName(order, name) {
+			continue
+		}
+
+		position := 0
+		for j := i - 1; j >= 0; j-- {
+			if index := indexOfName(order, updatedNames[j]); index >= 0 {
+				position = index + 1
+				break
+			}
+		}
+
+		content = append(content[:position], append([]*yaml.Node{updated.Content[i]}, content[position:]...)...)
+		order = append(order[:position], append([]string{name}, order[position:]...)...)
+	}
+
+	existing.Content = content
+}
+
+// itemNames returns the names of the items of a sequence, or false when not every item is a mapping with a
+// unique name
+func itemNames(sequence *yaml.Node) ([]string, bool) {
+	if len(sequence.Content) == 0 {
+		return nil, false
+	}
+
+	names := make([]string, 0, len(sequence.Content))
+	for _, item := range sequence.Content {
+		if item.Kind != yaml.MappingNode {
+			return nil, false
+		}
+
+		name := ""
+		for i := 0; i+1 < len(item.Content); i += 2 {
+			if item.Content[i].Value == "name" && item.Content[i+1].Kind == yaml.ScalarNode {
+				name = item.Content[i+1].Value
+				break
+			}
+		}
+		if name == "" || containsName(names, name) {
+			return nil, false
+		}
+		names = append(names, name)
+	}
+
+	return names, true
+}
+
+// containsName reports whether a name is in a list of names
+func containsName(names []string, name string) bool {
+	return indexOfName(names, name) >= 0
+}
+
+// indexOfName returns the index of a name in a list of names, or -1
+func indexOfName(names []string, name string) int {
+	for i, candidate := range names {
+		if candidate == name {
+			return i
+		}
+	}
+	return -1
+}
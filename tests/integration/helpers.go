@@ -3,10 +3,14 @@ package integration
 
 import (
 	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
 	"testing"
 	"time"
 
 	rootcmd "github.com/edenreich/n8n-cli/cmd"
+	"github.com/edenreich/n8n-cli/n8n"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -23,7 +27,7 @@ func executeCommand(t *testing.T, cmd *cobra.Command, args ...string) (string, s
 		current := cmd
 
 		for current != nil && current != rootCmd {
-			cmdPath = append([]string{current.Use}, cmdPath...)
+			cmdPath = append([]string{current.Name()}, cmdPath...)
 			current = current.Parent()
 		}
 
@@ -87,3 +91,36 @@ func runCommand(t *testing.T, args ...string) (string, error) {
 
 	return output, err
 }
+
+// handleTagRequests serves the tag endpoints sync uses to mark workflows as managed.
+// It returns false for requests to other endpoints, so mock servers can handle them.
+func handleTagRequests(w http.ResponseWriter, r *http.Request) bool {
+	isWorkflowTags := strings.HasPrefix(r.URL.Path, "/api/v1/workflows/") && strings.HasSuffix(r.URL.Path, "/tags")
+
+	w.Header().Set("Content-Type", "application/json")
+
+	switch {
+	case r.URL.Path == "/api/v1/tags" && r.Method == http.MethodGet:
+		_ = json.NewEncoder(w).Encode(n8n.TagList{Data: &[]n8n.Tag{}})
+	case r.URL.Path == "/api/v1/tags" && r.Method == http.MethodPost:
+		var tag n8n.Tag
+		_ = json.NewDecoder(r.Body).Decode(&tag)
+		tag.Id = stringPtr("tag-" + tag.Name)
+		_ = json.NewEncoder(w).Encode(tag)
+	case isWorkflowTags && r.Method == http.MethodGet:
+		_ = json.NewEncoder(w).Encode(n8n.WorkflowTags{})
+	case isWorkflowTags && r.Method == http.MethodPut:
+		var tagIDs n8n.TagIds
+		_ = json.NewDecoder(r.Body).Decode(&tagIDs)
+		tags := n8n.WorkflowTags{}
+		for _, tagID := range tagIDs {
+			tags = append(tags, n8n.Tag{Id: stringPtr(tagID.Id), Name: strings.TrimPrefix(tagID.Id, "tag-")})
+		}
+		_ = json.NewEncoder(w).Encode(tags)
+	default:
+		w.Header().Del("Content-Type")
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,23 @@
+package integration
+
+import (
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	home, err := os.MkdirTemp("", "n8n-cli-home-")
+	if err != nil {
+		panic(err)
+	}
+
+	// Commands changing workflows append to the audit journal in the home directory,
+	// keep the journal of the commands under test out of the real one
+	if err := os.Setenv("HOME", home); err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+	_ = os.RemoveAll(home)
+	os.Exit(code)
+}
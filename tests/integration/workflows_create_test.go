@@ -54,6 +54,28 @@ func TestCreateWorkflow(t *testing.T) {
 				assert.False(t, hasActive, "active field should be excluded when creating workflow")
 			},
 		},
+		{
+			name: "Create workflow without fields outside the API schema",
+			workflow: func() n8n.Workflow {
+				name := "Webhook"
+				return n8n.Workflow{
+					Name:                 "Test Workflow With Unknown Fields",
+					Nodes:                []n8n.Node{{Name: &name, AdditionalProperties: map[string]interface{}{"extendsCredential": "httpBasicAuth"}}},
+					AdditionalProperties: map[string]interface{}{"pinData": map[string]interface{}{}, "versionId": "v1"},
+				}
+			}(),
+			expectedError: false,
+			validateFields: func(t *testing.T, sentWorkflow map[string]interface{}) {
+				_, hasPinData := sentWorkflow["pinData"]
+				_, hasVersionID := sentWorkflow["versionId"]
+				assert.False(t, hasPinData, "Unknown fields should not be sent by default")
+				assert.False(t, hasVersionID, "Read-only fields should not be sent")
+
+				nodes := sentWorkflow["nodes"].([]interface{})
+				require.Len(t, nodes, 1)
+				assert.NotContains(t, nodes[0], "extendsCredential", "Unknown node fields should not be sent by default")
+			},
+		},
 	}
 
 	for _, tc := range testCases {
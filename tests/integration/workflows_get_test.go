@@ -101,6 +101,7 @@ func TestGetWorkflow(t *testing.T) {
 			if tc.expectedError {
 				require.Error(t, err)
 				assert.Contains(t, err.Error(), tc.errorContains)
+				assert.ErrorIs(t, err, n8n.ErrWorkflowNotFound)
 			} else {
 				require.NoError(t, err)
 				require.NotNil(t, workflow)
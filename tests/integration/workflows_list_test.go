@@ -180,3 +180,35 @@ func TestListWorkflows(t *testing.T) {
 		})
 	}
 }
+
+func TestGetAllWorkflows(t *testing.T) {
+	var cursors []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/workflows", r.URL.Path)
+		assert.Equal(t, "250", r.URL.Query().Get("limit"))
+
+		cursor := r.URL.Query().Get("cursor")
+		cursors = append(cursors, cursor)
+
+		w.Header().Set("Content-Type", "application/json")
+		switch cursor {
+		case "":
+			_, _ = fmt.Fprint(w, `{"data": [{"id": "1", "name": "Orders"}], "nextCursor": "page-2"}`)
+		case "page-2":
+			_, _ = fmt.Fprint(w, `{"data": [{"id": "2", "name": "Invoices"}], "nextCursor": null}`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	client := n8n.NewClient(server.URL, "test-api-key")
+	workflowList, err := client.GetAllWorkflows()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"", "page-2"}, cursors, "Every page should be fetched")
+	require.Len(t, *workflowList.Data, 2)
+	assert.Equal(t, "Orders", (*workflowList.Data)[0].Name)
+	assert.Equal(t, "Invoices", (*workflowList.Data)[1].Name)
+	assert.Nil(t, workflowList.NextCursor)
+}
@@ -29,7 +29,7 @@ func TestSyncWorkflows_Refresh(t *testing.T) {
 			name:           "Default behavior (refresh=true)",
 			refreshFlag:    true,
 			expectIDInFile: true,
-			args:           []string{"--directory", "", "--output", "json", "--all"},
+			args:           []string{"--directory", "", "--output", "json", "--all", "--include-unmanaged"},
 		},
 		{
 			name:           "Explicit refresh=false",
@@ -108,6 +108,10 @@ func TestSyncWorkflows_Refresh(t *testing.T) {
 					return
 				}
 
+				if handleTagRequests(w, r) {
+					return
+				}
+
 				switch {
 				case r.URL.Path == "/api/v1/workflows" && r.Method == http.MethodGet:
 					w.Header().Set("Content-Type", "application/json")
@@ -228,6 +232,9 @@ func TestSyncWorkflows_Refresh(t *testing.T) {
 			viper.Set("api_key", "test-api-key")
 			viper.Set("instance_url", server.URL)
 			defer viper.Reset()
+			t.Cleanup(func() {
+				_ = workflows.SyncCmd.Flags().Set("include-unmanaged", "false")
+			})
 
 			args := make([]string, len(tc.args))
 			copy(args, tc.args)
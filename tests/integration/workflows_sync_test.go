@@ -307,6 +307,10 @@ func TestSyncWorkflows(t *testing.T) {
 						return
 					}
 
+					if handleTagRequests(w, r) {
+						return
+					}
+
 					switch {
 					case r.URL.Path == "/api/v1/workflows" && r.Method == http.MethodGet:
 						w.Header().Set("Content-Type", "application/json")
@@ -314,7 +318,8 @@ func TestSyncWorkflows(t *testing.T) {
 							"data": [
 								{"id": "1", "name": "Workflow 1", "active": false},
 								{"id": "2", "name": "Workflow 2", "active": false},
-								{"id": "3", "name": "Workflow 3", "active": false}
+								{"id": "3", "name": "Workflow 3", "active": false, "tags": [{"id": "tag-n8n-cli", "name": "n8n-cli"}]},
+								{"id": "4", "name": "Built In The UI", "active": false}
 							]
 						}`)
 						return
@@ -378,6 +383,7 @@ func TestSyncWorkflows(t *testing.T) {
 				if !hasDeleted && !hasWouldDelete {
 					assert.Fail(t, "Expected either 'Deleted workflow' or 'Would delete workflow' in output")
 				}
+				assert.NotContains(t, stdout, "Built In The UI", "Workflows not managed by the CLI should not be pruned")
 			},
 			validateRequests: func(t *testing.T, requests []string) {
 				t.Logf("Requests: %v", requests)
@@ -407,7 +413,8 @@ func TestSyncWorkflows(t *testing.T) {
 							"data": [
 								{"id": "1", "name": "Workflow 1", "active": false},
 								{"id": "2", "name": "Workflow 2", "active": false},
-								{"id": "3", "name": "Workflow 3", "active": false}
+								{"id": "3", "name": "Workflow 3", "active": false, "tags": [{"id": "tag-n8n-cli", "name": "n8n-cli"}]},
+								{"id": "4", "name": "Built In The UI", "active": false}
 							]
 						}`)
 						return
@@ -421,6 +428,7 @@ func TestSyncWorkflows(t *testing.T) {
 			validateOutput: func(t *testing.T, stdout string) {
 				t.Logf("Command output: %s", stdout)
 				assert.Contains(t, stdout, "Would delete workflow 'Workflow 3' (ID: 3)")
+				assert.NotContains(t, stdout, "Built In The UI", "Workflows not managed by the CLI should not be pruned")
 			},
 			validateRequests: func(t *testing.T, requests []string) {
 				t.Logf("Requests: %v", requests)
@@ -464,6 +472,10 @@ func setupBasicMockServer(requests *[]string) *httptest.Server {
 
 		*requests = append(*requests, r.Method+" "+r.URL.Path)
 
+		if handleTagRequests(w, r) {
+			return
+		}
+
 		switch {
 		case r.URL.Path == "/api/v1/workflows" && r.Method == http.MethodGet:
 			// Create workflows using the same helper function for consistency
@@ -586,3 +598,94 @@ func createWorkflowFile(t *testing.T, dir, filename, id, name string, active boo
 	err = os.WriteFile(filepath.Join(dir, filename), data, 0644)
 	require.NoError(t, err, "Failed to write workflow file")
 }
+
+func TestSyncWorkflows_AtomicRevertsPartialDeployment(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	local := func(name string, id string, value string) n8n.Workflow {
+		wf := createTestWorkflow(name, id)
+		wf.Nodes = []n8n.Node{{
+			Name:       stringPtr("Set"),
+			Type:       stringPtr("n8n-nodes-base.set"),
+			Parameters: &map[string]interface{}{"value": value},
+		}}
+		return wf
+	}
+
+	writeWorkflowFile(t, tmpDir, "a_workflow.json", local("A Workflow", "1", "local"))
+	writeWorkflowFile(t, tmpDir, "b_workflow.json", local("B Workflow", "", "local"))
+	writeWorkflowFile(t, tmpDir, "c_workflow.json", local("C Workflow", "3", "local"))
+
+	remote := map[string]n8n.Workflow{
+		"1": local("A Workflow", "1", "remote"),
+		"3": local("C Workflow", "3", "remote"),
+	}
+
+	var requests []string
+	var restoredValue interface{}
+	failedUpdate := false
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if handleTagRequests(w, r) {
+			return
+		}
+		id := strings.TrimPrefix(r.URL.Path, "/api/v1/workflows/")
+
+		switch {
+		case r.URL.Path == "/api/v1/workflows" && r.Method == http.MethodPost:
+			var wf n8n.Workflow
+			_ = json.NewDecoder(r.Body).Decode(&wf)
+			wf.Id = stringPtr("new")
+			_ = json.NewEncoder(w).Encode(wf)
+		case r.Method == http.MethodGet:
+			wf, ok := remote[id]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = fmt.Fprint(w, `{"message": "not found"}`)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(wf)
+		case r.Method == http.MethodPut && id == "3" && !failedUpdate:
+			failedUpdate = true
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = fmt.Fprint(w, `{"message": "internal error"}`)
+		case r.Method == http.MethodPut:
+			var wf n8n.Workflow
+			_ = json.NewDecoder(r.Body).Decode(&wf)
+			if id == "1" {
+				restoredValue = (*wf.Nodes[0].Parameters)["value"]
+			}
+			wf.Id = &id
+			_ = json.NewEncoder(w).Encode(wf)
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	viper.Reset()
+	viper.Set("api_key", "test-api-key")
+	viper.Set("instance_url", mockServer.URL)
+
+	t.Cleanup(func() {
+		_ = workflows.SyncCmd.Flags().Set("atomic", "false")
+	})
+
+	stdout, _, err := executeCommand(t, workflows.SyncCmd, "--directory", tmpDir, "--atomic", "--dry-run=false", "--prune=false")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "all changes made during this run were reverted")
+
+	assert.Contains(t, stdout, "Updated workflow 'A Workflow' (ID: 1)")
+	assert.Contains(t, stdout, "Created workflow 'B Workflow' (ID: new)")
+	assert.Contains(t, stdout, "Restored workflow 'A Workflow' (ID: 1)")
+	assert.Contains(t, stdout, "Deleted workflow new that didn't exist before")
+
+	assert.Equal(t, "remote", restoredValue, "The previous content should be uploaded again")
+	assert.Contains(t, requests, "DELETE /api/v1/workflows/new")
+
+	_, err = os.Stat(filepath.Join(tmpDir, ".n8n", "state.json"))
+	assert.True(t, os.IsNotExist(err), "Versions recorded during the reverted run should be forgotten")
+}
@@ -0,0 +1,178 @@
+package unit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/edenreich/n8n-cli/audit"
+	rootcmd "github.com/edenreich/n8n-cli/cmd"
+	"github.com/edenreich/n8n-cli/cmd/workflows"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/edenreich/n8n-cli/n8n/clientfakes"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournal(t *testing.T) {
+	var received []audit.Record
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		var record audit.Record
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&record))
+		received = append(received, record)
+	}))
+	defer sink.Close()
+
+	journal := audit.NewJournal(filepath.Join(t.TempDir(), "audit", "history.jsonl"), audit.NewHTTPSink(sink.URL, "secret"))
+
+	now := time.Now().UTC()
+	require.NoError(t, journal.Append(audit.Record{Time: now.Add(-48 * time.Hour), Actor: "alice", WorkflowID: "1", WorkflowName: "Orders", Action: audit.ActionUpdate}))
+	require.NoError(t, journal.Append(audit.Record{Time: now, Actor: "ci-bot", WorkflowID: "2", WorkflowName: "Invoices", Action: audit.ActionDelete}))
+
+	records, err := journal.Read(audit.Filter{})
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "Orders", records[0].WorkflowName)
+	assert.Len(t, received, 2, "Every record should be sent to the sink")
+
+	records, err = journal.Read(audit.Filter{Workflow: "Invoices"})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "2", records[0].WorkflowID)
+
+	records, err = journal.Read(audit.Filter{Since: now.Add(-time.Hour)})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "ci-bot", records[0].Actor)
+
+	records, err = audit.NewJournal(filepath.Join(t.TempDir(), "missing.jsonl"), nil).Read(audit.Filter{})
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
+
+func TestAuditClient(t *testing.T) {
+	t.Setenv("GITHUB_ACTOR", "deploy-bot")
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_SERVER_URL", "https://github.com")
+	t.Setenv("GITHUB_REPOSITORY", "acme/workflows")
+	t.Setenv("GITHUB_RUN_ID", "42")
+
+	before := mergeTestWorkflow(mergeTestNode("Webhook", map[string]interface{}{"path": "orders"}))
+	after := mergeTestWorkflow(mergeTestNode("Webhook", map[string]interface{}{"path": "orders-v2"}))
+
+	fakeClient := &clientfakes.FakeClientInterface{}
+	fakeClient.GetWorkflowReturns(&before, nil)
+	fakeClient.UpdateWorkflowReturns(&after, nil)
+	fakeClient.DeleteWorkflowReturns(errors.New("forbidden"))
+	fakeClient.GetWorkflowTagsReturns(n8n.WorkflowTags{{Name: "billing"}}, nil)
+	fakeClient.UpdateWorkflowTagsReturns(n8n.WorkflowTags{{Name: "billing"}, {Name: "n8n-cli"}}, nil)
+	fakeClient.CreateTagReturns(&n8n.Tag{Id: stringPtr("tag-1"), Name: "billing"}, nil)
+	fakeClient.GetTagsReturns(&n8n.TagList{Data: &[]n8n.Tag{{Id: stringPtr("tag-2"), Name: "archived"}}}, nil)
+
+	journal := audit.NewJournal(filepath.Join(t.TempDir(), "history.jsonl"), nil)
+	client := workflows.NewAuditClient(fakeClient, journal, "n8n workflows sync", "https://n8n.example.com")
+
+	_, err := client.UpdateWorkflow("wf-1", &after)
+	require.NoError(t, err)
+	require.Error(t, client.DeleteWorkflow("wf-1"))
+	_, err = client.UpdateWorkflowTags("wf-1", n8n.TagIds{})
+	require.NoError(t, err)
+	_, err = client.GetWorkflows(nil)
+	require.NoError(t, err)
+	_, err = client.CreateTag("billing")
+	require.NoError(t, err)
+	require.NoError(t, client.DeleteTag("tag-2"))
+	require.NoError(t, client.CreateVariable(n8n.Variable{Key: "API_TOKEN", Value: "secret"}))
+	_, err = client.GetTags()
+	require.NoError(t, err)
+
+	records, err := journal.Read(audit.Filter{})
+	require.NoError(t, err)
+	require.Len(t, records, 6, "Only changes should be recorded")
+
+	beforeHash, err := n8n.WorkflowContentHash(before)
+	require.NoError(t, err)
+	afterHash, err := n8n.WorkflowContentHash(after)
+	require.NoError(t, err)
+
+	update := records[0]
+	assert.Equal(t, audit.ActionUpdate, update.Action)
+	assert.Equal(t, "deploy-bot", update.Actor)
+	assert.Equal(t, "https://github.com/acme/workflows/actions/runs/42", update.CIJob)
+	assert.Equal(t, "n8n workflows sync", update.Command)
+	assert.Equal(t, "https://n8n.example.com", update.Instance)
+	assert.Equal(t, "wf-1", update.WorkflowID)
+	assert.Equal(t, "Contact Form", update.WorkflowName)
+	assert.Equal(t, beforeHash, update.BeforeHash)
+	assert.Equal(t, afterHash, update.AfterHash)
+
+	assert.Equal(t, audit.ActionDelete, records[1].Action)
+	assert.Equal(t, "forbidden", records[1].Error, "Failed changes should be recorded too")
+
+	assert.Equal(t, audit.ActionUpdateTags, records[2].Action)
+	assert.Equal(t, "tags: [billing] -> [billing, n8n-cli]", records[2].Details)
+
+	assert.Equal(t, audit.ActionCreateTag, records[3].Action)
+	assert.Equal(t, "tag 'billing' (ID: tag-1)", records[3].Details)
+	assert.Equal(t, audit.ActionDeleteTag, records[4].Action)
+	assert.Equal(t, "tag 'archived' (ID: tag-2)", records[4].Details)
+	assert.Equal(t, audit.ActionCreateVariable, records[5].Action)
+	assert.Equal(t, "variable 'API_TOKEN'", records[5].Details)
+	assert.NotContains(t, string(mustReadFile(t, journal.Path)), "secret", "Variable values should not be recorded")
+}
+
+func TestShowHistory(t *testing.T) {
+	journalPath := filepath.Join(t.TempDir(), "history.jsonl")
+	journal := audit.NewJournal(journalPath, nil)
+	require.NoError(t, journal.Append(audit.Record{Time: time.Now().Add(-time.Hour), Actor: "alice", WorkflowID: "1", WorkflowName: "Orders", Action: audit.ActionCreate, AfterHash: "aaaaaaaaaaaaaaaaaaaa"}))
+	require.NoError(t, journal.Append(audit.Record{Time: time.Now(), Actor: "bob", WorkflowID: "1", WorkflowName: "Orders", Action: audit.ActionUpdate, BeforeHash: "aaaaaaaaaaaaaaaaaaaa", AfterHash: "bbbbbbbbbbbbbbbbbbbb"}))
+	require.NoError(t, journal.Append(audit.Record{Time: time.Now(), Actor: "bob", WorkflowID: "2", WorkflowName: "Invoices", Action: audit.ActionDelete}))
+
+	newHistoryTestCmd := func(t *testing.T, args ...string) (*cobra.Command, *bytes.Buffer) {
+		cmd := &cobra.Command{}
+		cmd.Flags().String("workflow", "", "")
+		cmd.Flags().String("action", "", "")
+		cmd.Flags().String("actor", "", "")
+		cmd.Flags().String("since", "", "")
+		cmd.Flags().Int("limit", 50, "")
+		cmd.Flags().String("output", "table", "")
+		cmd.Flags().String("journal", "", "")
+		require.NoError(t, cmd.ParseFlags(append([]string{"--journal", journalPath}, args...)))
+		outBuf := new(bytes.Buffer)
+		cmd.SetOut(outBuf)
+		return cmd, outBuf
+	}
+
+	t.Run("Lists the changes to a workflow newest first", func(t *testing.T) {
+		cmd, outBuf := newHistoryTestCmd(t, "--workflow", "Orders")
+		require.NoError(t, rootcmd.ShowHistory(cmd, nil))
+
+		output := outBuf.String()
+		assert.Contains(t, output, "aaaaaaaaaaaa -> bbbbbbbbbbbb")
+		assert.Contains(t, output, "- -> aaaaaaaaaaaa")
+		assert.Less(t, bytes.Index(outBuf.Bytes(), []byte("bob")), bytes.Index(outBuf.Bytes(), []byte("alice")))
+		assert.NotContains(t, output, "Invoices")
+	})
+
+	t.Run("Prints JSON", func(t *testing.T) {
+		cmd, outBuf := newHistoryTestCmd(t, "--action", "delete", "--output", "json")
+		require.NoError(t, rootcmd.ShowHistory(cmd, nil))
+
+		var records []audit.Record
+		require.NoError(t, json.Unmarshal(outBuf.Bytes(), &records))
+		require.Len(t, records, 1)
+		assert.Equal(t, "Invoices", records[0].WorkflowName)
+	})
+
+	t.Run("Rejects invalid since values", func(t *testing.T) {
+		cmd, _ := newHistoryTestCmd(t, "--since", "last week")
+		assert.Error(t, rootcmd.ShowHistory(cmd, nil))
+	})
+}
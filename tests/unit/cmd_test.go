@@ -2,11 +2,14 @@ package unit
 
 import (
 	"bytes"
+	"errors"
 	"testing"
 
 	"github.com/edenreich/n8n-cli/cmd"
 	"github.com/edenreich/n8n-cli/config"
+	"github.com/edenreich/n8n-cli/n8n"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSanitizeFilename(t *testing.T) {
@@ -32,6 +35,30 @@ func TestSanitizeFilename(t *testing.T) {
 	}
 }
 
+func TestFindWorkflow(t *testing.T) {
+	workflows := []n8n.Workflow{
+		{Id: stringPtr("1"), Name: "Contact Form"},
+		{Id: stringPtr("2"), Name: "Orders"},
+		{Id: stringPtr("3"), Name: "Orders"},
+	}
+
+	id, err := cmd.FindWorkflow("Contact Form", workflows)
+	require.NoError(t, err)
+	assert.Equal(t, "1", id)
+
+	id, err = cmd.FindWorkflowByFilename("workflows/Contact_Form.yaml", workflows)
+	require.NoError(t, err)
+	assert.Equal(t, "1", id)
+
+	_, err = cmd.FindWorkflow("Missing", workflows)
+	assert.EqualError(t, err, "workflow with name 'Missing' not found")
+
+	_, err = cmd.FindWorkflow("Orders", workflows)
+	var ambiguous *cmd.AmbiguousWorkflowError
+	require.True(t, errors.As(err, &ambiguous))
+	assert.Equal(t, []string{"2", "3"}, ambiguous.IDs)
+}
+
 func TestFormatAPIBaseURL(t *testing.T) {
 	testCases := []struct {
 		name            string
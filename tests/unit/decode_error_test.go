@@ -0,0 +1,139 @@
+package unit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/edenreich/n8n-cli/cmd/workflows"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeError_Locations(t *testing.T) {
+	tests := []struct {
+		name    string
+		decode  func([]byte) (n8n.Workflow, error)
+		content string
+		field   string
+		line    int
+		column  int
+		snippet string
+	}{
+		{
+			name:   "YAML type error",
+			decode: n8n.NewWorkflowDecoder().DecodeFromYAML,
+			content: `name: Contact Form
+nodes:
+  - name: Webhook
+    typeVersion: 1
+  - name: Reply
+    typeVersion: two
+connections: {}
+`,
+			field:   "nodes[1].typeVersion",
+			line:    6,
+			column:  18,
+			snippet: "    typeVersion: two",
+		},
+		{
+			name:    "YAML syntax error",
+			decode:  n8n.NewWorkflowDecoder().DecodeFromYAML,
+			content: "name: Contact Form\nnodes:\n  - name: Webhook\n\ttype: code\n",
+			line:    3,
+			snippet: "  - name: Webhook",
+		},
+		{
+			name:   "JSON type error",
+			decode: n8n.NewWorkflowDecoder().DecodeFromJSON,
+			content: `{
+  "name": "Contact Form",
+  "nodes": [
+    {"name": "Webhook", "position": [250, "top"]}
+  ],
+  "settings": {"executionTimeout": 60}
+}`,
+			field:   "nodes[0].position[1]",
+			line:    4,
+			column:  43,
+			snippet: `    {"name": "Webhook", "position": [250, "top"]}`,
+		},
+		{
+			name:    "JSON syntax error",
+			decode:  n8n.NewWorkflowDecoder().DecodeFromJSON,
+			content: "{\n  \"name\": \"Contact Form\",\n  \"nodes\": [}\n}",
+			line:    3,
+			column:  13,
+			snippet: `  "nodes": [}`,
+		},
+		{
+			name:    "Exported array",
+			decode:  n8n.NewWorkflowDecoder().DecodeFromJSON,
+			content: "[\n  {\"name\": \"Contact Form\", \"active\": \"yes\"}\n]",
+			field:   "active",
+			line:    2,
+			column:  38,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.decode([]byte(tt.content))
+			require.Error(t, err)
+
+			var decodeErr *n8n.DecodeError
+			require.True(t, errors.As(err, &decodeErr), "error should be located: %v", err)
+			assert.Equal(t, tt.field, decodeErr.Field)
+			assert.Equal(t, tt.line, decodeErr.Line)
+			assert.Equal(t, tt.column, decodeErr.Column)
+			if tt.snippet != "" {
+				assert.Equal(t, tt.snippet, decodeErr.Snippet)
+			}
+		})
+	}
+}
+
+func TestDecodeError_Message(t *testing.T) {
+	err := &n8n.DecodeError{
+		File:    "workflows/Contact_Form.yaml",
+		Field:   "nodes[1].typeVersion",
+		Line:    6,
+		Column:  18,
+		Snippet: "    typeVersion: two",
+		Err:     errors.New(`expected a number, got the string "two"`),
+	}
+
+	assert.Equal(t, `workflows/Contact_Form.yaml:6:18: nodes[1].typeVersion: expected a number, got the string "two"
+  6 |     typeVersion: two
+    |                  ^`, err.Error())
+}
+
+func TestDecodeError_WorkflowDirectory(t *testing.T) {
+	files := map[string][]byte{
+		n8n.DirectoryWorkflowFile: []byte("name: Contact Form\nnodes:\n  - $file: nodes/Webhook.yaml\nconnections: {}\n"),
+		"nodes/Webhook.yaml":      []byte("name: Webhook\ntype: n8n-nodes-base.webhook\nparameters:\n  - path\n"),
+	}
+
+	_, err := n8n.NewWorkflowDecoder().DecodeFromDirectory(files)
+	require.Error(t, err)
+
+	var decodeErr *n8n.DecodeError
+	require.True(t, errors.As(err, &decodeErr))
+	assert.Equal(t, "nodes/Webhook.yaml", decodeErr.File, "The error should be located in the node file")
+	assert.Equal(t, "nodes[0].parameters", decodeErr.Field)
+	assert.Equal(t, 4, decodeErr.Line)
+	assert.Equal(t, 3, decodeErr.Column)
+}
+
+func TestFormatWorkflowFiles_DecodeErrorLocation(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "Contact_Form.yaml")
+	require.NoError(t, os.WriteFile(filePath, []byte("name: Contact Form\nnodes:\n  - name: Webhook\n    disabled: maybe\n"), 0644))
+
+	_, err := workflows.FormatWorkflowFiles(newSelectTestCmd(t), dir, true, true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), filePath+`:4:15: nodes[0].disabled: expected a boolean, got the string "maybe"`)
+	assert.Contains(t, err.Error(), "  4 |     disabled: maybe")
+}
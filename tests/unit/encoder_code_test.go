@@ -0,0 +1,184 @@
+package unit
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/edenreich/n8n-cli/cmd/workflows"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func codeTestWorkflow() n8n.Workflow {
+	return n8n.Workflow{
+		Id:   stringPtr("1"),
+		Name: "Contact Form",
+		Nodes: []n8n.Node{
+			{Name: stringPtr("Format Reply"), Type: stringPtr("n8n-nodes-base.code"), Parameters: &map[string]interface{}{
+				"jsCode": "return items.map(item => ({ json: { reply: `Hi ${item.json.name}` } }));",
+			}},
+			{Name: stringPtr("Score Lead"), Type: stringPtr("n8n-nodes-base.code"), Parameters: &map[string]interface{}{
+				"language":   "python",
+				"pythonCode": "return [{'score': 1}]\n",
+			}},
+			{Name: stringPtr("Format/Reply"), Type: stringPtr("n8n-nodes-base.function"), Parameters: &map[string]interface{}{
+				"functionCode": "return items;",
+			}},
+			{Name: stringPtr("Expression"), Type: stringPtr("n8n-nodes-base.code"), Parameters: &map[string]interface{}{
+				"jsCode": "={{ $json.code }}",
+			}},
+		},
+		Connections: map[string]interface{}{},
+	}
+}
+
+func TestExtractCode(t *testing.T) {
+	workflow := codeTestWorkflow()
+
+	extracted, files := n8n.NewWorkflowEncoder(true).WithCodeFiles("Contact_Form").ExtractCode(workflow)
+
+	require.Len(t, files, 3)
+	assert.Equal(t, "Contact_Form/Format_Reply.js", files[0].Path)
+	assert.Equal(t, "Contact_Form/Score_Lead.py", files[1].Path)
+	assert.Equal(t, "Contact_Form/Format_Reply_2.js", files[2].Path, "Nodes with the same file name should get unique files")
+	assert.Equal(t, "return [{'score': 1}]\n\n", string(files[1].Content))
+
+	assert.Equal(t, map[string]interface{}{n8n.CodeFileKey: "Contact_Form/Format_Reply.js"}, (*extracted.Nodes[0].Parameters)["jsCode"])
+	assert.Equal(t, "={{ $json.code }}", (*extracted.Nodes[3].Parameters)["jsCode"], "Expressions should stay inline")
+	assert.IsType(t, "", (*workflow.Nodes[0].Parameters)["jsCode"], "The original workflow should not be modified")
+
+	t.Run("Round-trips through YAML and JSON", func(t *testing.T) {
+		contents := make(map[string][]byte)
+		for _, file := range files {
+			contents[file.Path] = file.Content
+		}
+		readFile := func(path string) ([]byte, error) {
+			content, ok := contents[path]
+			if !ok {
+				return nil, fmt.Errorf("%s not found", path)
+			}
+			return content, nil
+		}
+
+		encoder := n8n.NewWorkflowEncoder(true)
+		yamlData, err := encoder.EncodeToYAML(extracted)
+		require.NoError(t, err)
+		jsonData, err := encoder.EncodeToJSON(extracted)
+		require.NoError(t, err)
+
+		fromYAML, err := n8n.NewWorkflowDecoder().WithCodeFiles(readFile).DecodeFromYAML(yamlData)
+		require.NoError(t, err)
+		fromJSON, err := n8n.NewWorkflowDecoder().WithCodeFiles(readFile).DecodeFromJSON(jsonData)
+		require.NoError(t, err)
+
+		for i, node := range workflow.Nodes {
+			assert.Equal(t, *node.Parameters, *fromYAML.Nodes[i].Parameters)
+			assert.Equal(t, *node.Parameters, *fromJSON.Nodes[i].Parameters)
+		}
+	})
+
+	t.Run("Keeps references without a code file reader", func(t *testing.T) {
+		yamlData, err := n8n.NewWorkflowEncoder(true).EncodeToYAML(extracted)
+		require.NoError(t, err)
+
+		decoded, err := n8n.NewWorkflowDecoder().DecodeFromYAML(yamlData)
+		require.NoError(t, err)
+		assert.True(t, n8n.HasCodeFileReferences(decoded))
+	})
+}
+
+func TestInlineCode(t *testing.T) {
+	readFile := func(path string) ([]byte, error) {
+		return []byte("return items;\n"), nil
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{"Reads files relative to the workflow file", "Contact_Form/Format.js", false},
+		{"Rejects absolute paths", "/etc/passwd", true},
+		{"Rejects paths outside the workflow directory", "../secrets/Format.js", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := n8n.Workflow{Name: "Contact Form", Nodes: []n8n.Node{
+				{Name: stringPtr("Format"), Parameters: &map[string]interface{}{
+					"jsCode": map[string]interface{}{n8n.CodeFileKey: tt.path},
+				}},
+			}}
+
+			inlined, err := n8n.InlineCode(workflow, readFile)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "return items;", (*inlined.Nodes[0].Parameters)["jsCode"])
+		})
+	}
+}
+
+func TestRefreshWorkflows_ExtractCode(t *testing.T) {
+	dir := t.TempDir()
+	remote := codeTestWorkflow()
+	writeDriftTestWorkflow(t, dir, "Contact_Form.yaml", n8n.Workflow{Id: remote.Id, Name: remote.Name})
+
+	fakeClient := newWatchTestClient()
+	fakeClient.GetWorkflowReturns(&remote, nil)
+
+	newCmd := func(args ...string) *cobra.Command {
+		cmd := newSelectTestCmd(t)
+		cmd.Flags().Bool("extract-code", false, "")
+		require.NoError(t, cmd.ParseFlags(args))
+		return cmd
+	}
+
+	require.NoError(t, workflows.RefreshWorkflowsWithClient(newCmd("--extract-code"), fakeClient, dir, false, false, "", true, false))
+
+	code, err := os.ReadFile(filepath.Join(dir, "Contact_Form", "Format_Reply.js"))
+	require.NoError(t, err)
+	assert.Equal(t, "return items.map(item => ({ json: { reply: `Hi ${item.json.name}` } }));\n", string(code))
+
+	content, err := os.ReadFile(filepath.Join(dir, "Contact_Form.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "$file: Contact_Form/Format_Reply.js")
+	assert.NotContains(t, string(content), "items.map")
+
+	t.Run("Keeps extracting code without the flag", func(t *testing.T) {
+		changed := codeTestWorkflow()
+		(*changed.Nodes[0].Parameters)["jsCode"] = "return [];"
+		fakeClient.GetWorkflowReturns(&changed, nil)
+
+		outBuf := new(bytes.Buffer)
+		cmd := newCmd()
+		cmd.SetOut(outBuf)
+		require.NoError(t, workflows.RefreshWorkflowsWithClient(cmd, fakeClient, dir, false, false, "", true, false))
+
+		code, err := os.ReadFile(filepath.Join(dir, "Contact_Form", "Format_Reply.js"))
+		require.NoError(t, err)
+		assert.Equal(t, "return [];\n", string(code), "A change only in the code should be written")
+		assert.NotContains(t, outBuf.String(), "No changes")
+	})
+
+	t.Run("Sync inlines the code files", func(t *testing.T) {
+		fakeClient.GetWorkflowsReturns(&n8n.WorkflowList{Data: &[]n8n.Workflow{
+			{Id: remote.Id, Name: remote.Name, Tags: &[]n8n.Tag{{Name: "n8n-cli"}}},
+		}}, nil)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "Contact_Form", "Format_Reply.js"), []byte("return [1];\n"), 0644))
+
+		_, err := workflows.SyncWorkflowsWithClient(newSelectTestCmd(t), fakeClient, dir, false, false)
+		require.NoError(t, err)
+
+		require.Equal(t, 1, fakeClient.UpdateWorkflowCallCount())
+		_, updated := fakeClient.UpdateWorkflowArgsForCall(0)
+		assert.Equal(t, "return [1];", (*updated.Nodes[0].Parameters)["jsCode"])
+	})
+}
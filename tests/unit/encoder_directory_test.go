@@ -0,0 +1,129 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/edenreich/n8n-cli/cmd/workflows"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func directoryTestWorkflow() n8n.Workflow {
+	workflow := codeTestWorkflow()
+	workflow.Nodes = append(workflow.Nodes,
+		n8n.Node{Name: stringPtr("Load Leads"), Type: stringPtr("n8n-nodes-base.postgres"), Parameters: &map[string]interface{}{
+			"operation": "executeQuery",
+			"query":     "SELECT *\nFROM leads",
+		}},
+		n8n.Node{Name: stringPtr("Render Mail"), Type: stringPtr("n8n-nodes-base.html"), Parameters: &map[string]interface{}{
+			"html": "<p>{{ $json.reply }}</p>",
+		}},
+		n8n.Node{Name: stringPtr("Query Param"), Type: stringPtr("n8n-nodes-base.httpRequest"), Parameters: &map[string]interface{}{
+			"query": "not SQL",
+		}},
+	)
+	workflow.Connections = map[string]interface{}{
+		"Load Leads": map[string]interface{}{"main": []interface{}{[]interface{}{
+			map[string]interface{}{"node": "Format Reply", "type": "main", "index": float64(0)},
+		}}},
+	}
+	return workflow
+}
+
+func TestEncodeToDirectory(t *testing.T) {
+	workflow := directoryTestWorkflow()
+
+	files, err := n8n.NewWorkflowEncoder(true).EncodeToDirectory(workflow)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"nodes/Expression.yaml",
+		"nodes/Format_Reply.js",
+		"nodes/Format_Reply.yaml",
+		"nodes/Format_Reply_2.js",
+		"nodes/Format_Reply_2.yaml",
+		"nodes/Load_Leads.sql",
+		"nodes/Load_Leads.yaml",
+		"nodes/Query_Param.yaml",
+		"nodes/Render_Mail.html",
+		"nodes/Render_Mail.yaml",
+		"nodes/Score_Lead.py",
+		"nodes/Score_Lead.yaml",
+		"workflow.yaml",
+	}, n8n.DirectoryFilePaths(files))
+
+	assert.Contains(t, string(files["workflow.yaml"]), "$file: nodes/Format_Reply.yaml")
+	assert.Contains(t, string(files["workflow.yaml"]), "connections:")
+	assert.NotContains(t, string(files["workflow.yaml"]), "parameters:", "Nodes should be stored in their own files")
+	assert.Equal(t, "SELECT *\nFROM leads\n", string(files["nodes/Load_Leads.sql"]))
+	assert.Contains(t, string(files["nodes/Query_Param.yaml"]), "query: not SQL", "Parameters are only extracted from nodes of their types")
+
+	t.Run("Round-trips the workflow", func(t *testing.T) {
+		decoded, err := n8n.NewWorkflowDecoder().DecodeFromDirectory(files)
+		require.NoError(t, err)
+		assert.Equal(t, n8n.CleanWorkflow(workflow), n8n.CleanWorkflow(decoded))
+	})
+
+	t.Run("Fails for missing node files", func(t *testing.T) {
+		incomplete := make(map[string][]byte)
+		for path, content := range files {
+			incomplete[path] = content
+		}
+		delete(incomplete, "nodes/Score_Lead.yaml")
+
+		_, err := n8n.NewWorkflowDecoder().DecodeFromDirectory(incomplete)
+		assert.ErrorContains(t, err, "nodes/Score_Lead.yaml")
+	})
+
+	t.Run("Fails without workflow.yaml", func(t *testing.T) {
+		_, err := n8n.NewWorkflowDecoder().DecodeFromDirectory(map[string][]byte{})
+		assert.Error(t, err)
+	})
+}
+
+func TestRefreshWorkflows_OutputDirectory(t *testing.T) {
+	dir := t.TempDir()
+	remote := directoryTestWorkflow()
+	writeDriftTestWorkflow(t, dir, "Contact_Form.json", n8n.Workflow{Id: remote.Id, Name: remote.Name})
+
+	fakeClient := newWatchTestClient()
+	fakeClient.GetWorkflowReturns(&remote, nil)
+
+	cmd := newSelectTestCmd(t)
+	require.NoError(t, workflows.RefreshWorkflowsWithClient(cmd, fakeClient, dir, false, false, "dir", true, false))
+
+	workflowDir := filepath.Join(dir, "Contact_Form")
+	assert.FileExists(t, filepath.Join(workflowDir, "workflow.yaml"))
+	assert.FileExists(t, filepath.Join(workflowDir, "nodes", "Load_Leads.sql"))
+
+	t.Run("Removes the files of deleted nodes", func(t *testing.T) {
+		changed := directoryTestWorkflow()
+		changed.Nodes = changed.Nodes[:len(changed.Nodes)-1]
+		fakeClient.GetWorkflowReturns(&changed, nil)
+
+		require.NoError(t, workflows.RefreshWorkflowsWithClient(newSelectTestCmd(t), fakeClient, dir, false, false, "", true, false))
+
+		assert.NoFileExists(t, filepath.Join(workflowDir, "nodes", "Query_Param.yaml"))
+		assert.FileExists(t, filepath.Join(workflowDir, "nodes", "Load_Leads.yaml"))
+	})
+
+	t.Run("Sync reads the workflow directory", func(t *testing.T) {
+		require.NoError(t, os.Remove(filepath.Join(dir, "Contact_Form.json")))
+		require.NoError(t, os.WriteFile(filepath.Join(workflowDir, "nodes", "Load_Leads.sql"), []byte("SELECT 1\n"), 0644))
+		fakeClient.GetWorkflowsReturns(&n8n.WorkflowList{Data: &[]n8n.Workflow{
+			{Id: remote.Id, Name: remote.Name, Tags: &[]n8n.Tag{{Name: "n8n-cli"}}},
+		}}, nil)
+
+		results, err := workflows.SyncWorkflowsWithClient(newSelectTestCmd(t), fakeClient, dir, false, false)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, workflowDir, results[0].FilePath)
+
+		require.Equal(t, 1, fakeClient.UpdateWorkflowCallCount())
+		_, updated := fakeClient.UpdateWorkflowArgsForCall(0)
+		assert.Equal(t, "SELECT 1", (*updated.Nodes[4].Parameters)["query"])
+	})
+}
@@ -0,0 +1,139 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/edenreich/n8n-cli/cmd/workflows"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const unknownFieldsWorkflow = `{
+  "id": "1",
+  "name": "Contact Form",
+  "nodes": [
+    {
+      "name": "HTTP Request",
+      "type": "n8n-nodes-base.httpRequest",
+      "typeVersion": 4,
+      "parameters": {"url": "https://example.com"},
+      "extendsCredential": "httpBasicAuth"
+    }
+  ],
+  "connections": {},
+  "settings": {},
+  "pinData": {"HTTP Request": [{"json": {"ok": true}}]},
+  "meta": {"templateCredsSetupCompleted": true},
+  "isArchived": false,
+  "versionId": "0d2f7e3c"
+}`
+
+func TestUnknownFields_RoundTrip(t *testing.T) {
+	workflow, err := n8n.NewWorkflowDecoder().DecodeFromJSON([]byte(unknownFieldsWorkflow))
+	require.NoError(t, err)
+
+	pinData, found := workflow.Get("pinData")
+	require.True(t, found, "Unknown workflow fields should be decoded")
+	assert.Contains(t, pinData, "HTTP Request")
+	assert.Equal(t, "httpBasicAuth", workflow.Nodes[0].AdditionalProperties["extendsCredential"], "Unknown node fields should be decoded")
+
+	t.Run("JSON", func(t *testing.T) {
+		encoded, err := n8n.NewWorkflowEncoder(false).EncodeToJSON(workflow)
+		require.NoError(t, err)
+
+		decoded, err := n8n.NewWorkflowDecoder().DecodeFromJSON(encoded)
+		require.NoError(t, err)
+		assert.Equal(t, workflow, decoded)
+	})
+
+	t.Run("YAML", func(t *testing.T) {
+		encoded, err := n8n.NewWorkflowEncoder(false).EncodeToYAML(workflow)
+		require.NoError(t, err)
+		assert.Contains(t, string(encoded), "extendsCredential: httpBasicAuth")
+
+		decoded, err := n8n.NewWorkflowDecoder().DecodeFromYAML(encoded)
+		require.NoError(t, err)
+		assert.Equal(t, workflow, decoded)
+	})
+
+	t.Run("Clean output drops read-only fields", func(t *testing.T) {
+		encoded, err := n8n.NewWorkflowEncoder(true).EncodeToYAML(workflow)
+		require.NoError(t, err)
+
+		content := string(encoded)
+		assert.Contains(t, content, "pinData:")
+		assert.Contains(t, content, "isArchived: false")
+		assert.NotContains(t, content, "versionId")
+		_, found := workflow.Get("versionId")
+		assert.True(t, found, "The original workflow should not be modified")
+	})
+}
+
+func TestFieldPolicy(t *testing.T) {
+	workflow, err := n8n.NewWorkflowDecoder().DecodeFromJSON([]byte(unknownFieldsWorkflow))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		policy        n8n.FieldPolicy
+		expected      []string
+		expectedNodes []string
+	}{
+		{
+			name:   "Default sends nothing",
+			policy: n8n.DefaultFieldPolicy(),
+		},
+		{
+			name:     "Allowed workflow field",
+			policy:   n8n.FieldPolicy{Allow: []string{"pinData"}},
+			expected: []string{"pinData"},
+		},
+		{
+			name:          "Allowed node fields",
+			policy:        n8n.FieldPolicy{Allow: []string{"nodes.*"}},
+			expectedNodes: []string{"extendsCredential"},
+		},
+		{
+			name:          "Denied fields win",
+			policy:        n8n.FieldPolicy{Allow: []string{"*"}, Deny: append([]string{"meta"}, n8n.ReadOnlyFields...)},
+			expected:      []string{"isArchived", "pinData"},
+			expectedNodes: []string{"extendsCredential"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered := tt.policy.Apply(workflow)
+			assert.ElementsMatch(t, tt.expected, fieldNames(filtered.AdditionalProperties))
+			assert.ElementsMatch(t, tt.expectedNodes, fieldNames(filtered.Nodes[0].AdditionalProperties))
+		})
+	}
+
+	assert.Len(t, workflow.AdditionalProperties, 4, "The original workflow should not be modified")
+	assert.Len(t, workflow.Nodes[0].AdditionalProperties, 1)
+}
+
+func TestDetectWorkflowChanges_UnknownFields(t *testing.T) {
+	defer viper.Reset()
+
+	local, err := n8n.NewWorkflowDecoder().DecodeFromJSON([]byte(unknownFieldsWorkflow))
+	require.NoError(t, err)
+	remote := local
+	remote.AdditionalProperties = map[string]interface{}{"pinData": map[string]interface{}{}}
+
+	assert.False(t, workflows.DetectWorkflowChanges(&local, &remote).NeedsUpdate, "Fields that are not sent should not count as changes")
+
+	viper.Set("unknown_fields.allow", []string{"pinData"})
+	assert.True(t, workflows.DetectWorkflowChanges(&local, &remote).NeedsUpdate, "Allowed fields should count as changes")
+}
+
+// fieldNames returns the names of the fields of a property map
+func fieldNames(properties map[string]interface{}) []string {
+	var names []string
+	for name := range properties {
+		names = append(names, name)
+	}
+	return names
+}
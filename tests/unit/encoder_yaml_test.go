@@ -0,0 +1,86 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// yamlRoundTripValues are parameter values that have to decode to the same bytes after encoding to YAML
+var yamlRoundTripValues = []struct {
+	name     string
+	value    string
+	expected string
+}{
+	{"Expression", "={{ $json.name }}", "value: ={{ $json.name }}\n"},
+	{"Expression prefix only", "=", "value: =\n"},
+	{"Expression with quotes", `={{ $json["first name"] + ': ' + $json.email }}`, `value: '={{ $json["first name"] + '': '' + $json.email }}'` + "\n"},
+	{"Multi-line expression", "={{ $json.items\n  .map(item => item.name)\n  .join(', ') }}", "value: |-\n  ={{ $json.items\n    .map(item => item.name)\n    .join(', ') }}\n"},
+	{"Long expression", "={{ " + strings.Repeat("$json.total + ", 20) + "0 }}", "value: ={{ " + strings.Repeat("$json.total + ", 20) + "0 }}\n"},
+	{"Long text", strings.TrimSpace(strings.Repeat("Thanks for reaching out. ", 10)), "value: " + strings.TrimSpace(strings.Repeat("Thanks for reaching out. ", 10)) + "\n"},
+	{"SQL query", "SELECT *\nFROM leads\nWHERE created_at > NOW() - INTERVAL '1 day'", "value: |-\n  SELECT *\n  FROM leads\n  WHERE created_at > NOW() - INTERVAL '1 day'\n"},
+	{"HTML body", "<html>\n  <body>\n    <p>Hi {{ $json.name }}</p>\n  </body>\n</html>\n", "value: |\n  <html>\n    <body>\n      <p>Hi {{ $json.name }}</p>\n    </body>\n  </html>\n"},
+	{"Leading indentation", "  indented\nnot indented", "value: |2-\n    indented\n  not indented\n"},
+	{"Trailing blank lines", "return items;\n\n\n", "value: |+\n  return items;\n\n\n"},
+	{"Tabs", "a\tb\nc", "value: |-\n  a\tb\n  c\n"},
+	{"Trailing spaces", "line one  \nline two", ""},
+	{"Windows line breaks", "line one\r\nline two", ""},
+	{"Number-like string", "0123", ""},
+	{"Boolean-like string", "yes", ""},
+	{"Empty string", "", ""},
+	{"Unicode", "Grüße\nСпасибо", "value: |-\n  Grüße\n  Спасибо\n"},
+	{"Emoji", "Thanks 👋\nThe team", ""},
+}
+
+func TestEncodeToYAML_StringRoundTrip(t *testing.T) {
+	for _, tt := range yamlRoundTripValues {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := n8n.Workflow{
+				Name: "Contact Form",
+				Nodes: []n8n.Node{{Name: stringPtr("Node"), Parameters: &map[string]interface{}{
+					"value": tt.value,
+				}}},
+				Connections: map[string]interface{}{},
+			}
+
+			content, err := n8n.NewWorkflowEncoder(true).EncodeToYAML(workflow)
+			require.NoError(t, err)
+
+			if tt.expected != "" {
+				// The parameter is indented under nodes and parameters, blank lines are not indented
+				lines := strings.Split(strings.TrimSuffix(tt.expected, "\n"), "\n")
+				for i, line := range lines {
+					if line != "" {
+						lines[i] = "      " + line
+					}
+				}
+				assert.Contains(t, string(content), strings.Join(lines, "\n")+"\n")
+			}
+
+			decoded, err := n8n.NewWorkflowDecoder().DecodeFromYAML(content)
+			require.NoError(t, err)
+			assert.Equal(t, tt.value, (*decoded.Nodes[0].Parameters)["value"])
+
+			t.Run("Through a patched file", func(t *testing.T) {
+				existing, err := n8n.NewWorkflowEncoder(true).EncodeToYAML(n8n.Workflow{
+					Name: "Contact Form",
+					Nodes: []n8n.Node{{Name: stringPtr("Node"), Parameters: &map[string]interface{}{
+						"value": "'quoted'\n",
+					}}},
+					Connections: map[string]interface{}{},
+				})
+				require.NoError(t, err)
+
+				patched, err := n8n.PatchYAML(existing, content)
+				require.NoError(t, err)
+
+				decoded, err := n8n.NewWorkflowDecoder().DecodeFromYAML(patched)
+				require.NoError(t, err)
+				assert.Equal(t, tt.value, (*decoded.Nodes[0].Parameters)["value"])
+			})
+		})
+	}
+}
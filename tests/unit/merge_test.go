@@ -0,0 +1,110 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mergeTestNode(name string, parameters map[string]interface{}) n8n.Node {
+	return n8n.Node{
+		Name:       stringPtr(name),
+		Type:       stringPtr("n8n-nodes-base.set"),
+		Parameters: &parameters,
+	}
+}
+
+func mergeTestWorkflow(nodes ...n8n.Node) n8n.Workflow {
+	return n8n.Workflow{
+		Id:          stringPtr("wf-1"),
+		Name:        "Contact Form",
+		Nodes:       nodes,
+		Connections: map[string]interface{}{},
+	}
+}
+
+func TestMergeWorkflows(t *testing.T) {
+	base := mergeTestWorkflow(
+		mergeTestNode("Webhook", map[string]interface{}{"path": "contact"}),
+		mergeTestNode("Email", map[string]interface{}{"to": "dev@example.com"}),
+	)
+
+	t.Run("Merges changes to different nodes", func(t *testing.T) {
+		local := mergeTestWorkflow(
+			mergeTestNode("Webhook", map[string]interface{}{"path": "contact-form"}),
+			mergeTestNode("Email", map[string]interface{}{"to": "dev@example.com"}),
+		)
+		local.Active = boolPtr(true)
+
+		remote := mergeTestWorkflow(
+			mergeTestNode("Webhook", map[string]interface{}{"path": "contact"}),
+			mergeTestNode("Email", map[string]interface{}{"to": "ops@example.com"}),
+			mergeTestNode("Slack", map[string]interface{}{"channel": "#alerts"}),
+		)
+		remote.Connections = map[string]interface{}{
+			"Email": map[string]interface{}{"main": []interface{}{}},
+		}
+
+		merged, conflicts, err := n8n.MergeWorkflows(base, local, remote)
+		require.NoError(t, err)
+		assert.Empty(t, conflicts)
+
+		require.Len(t, merged.Nodes, 3)
+		assert.Equal(t, "contact-form", (*merged.Nodes[0].Parameters)["path"], "Local changes should be kept")
+		assert.Equal(t, "ops@example.com", (*merged.Nodes[1].Parameters)["to"], "Remote changes should be taken")
+		assert.Equal(t, "Slack", *merged.Nodes[2].Name, "Nodes added remotely should be appended")
+		assert.Contains(t, merged.Connections, "Email", "Connections added remotely should be taken")
+		assert.Equal(t, "wf-1", *merged.Id)
+		assert.True(t, *merged.Active, "Local activation should be kept")
+	})
+
+	t.Run("Reports nodes changed on both sides", func(t *testing.T) {
+		local := mergeTestWorkflow(
+			mergeTestNode("Webhook", map[string]interface{}{"path": "contact"}),
+			mergeTestNode("Email", map[string]interface{}{"to": "local@example.com"}),
+		)
+		remote := mergeTestWorkflow(
+			mergeTestNode("Webhook", map[string]interface{}{"path": "contact"}),
+			mergeTestNode("Email", map[string]interface{}{"to": "remote@example.com"}),
+		)
+
+		_, conflicts, err := n8n.MergeWorkflows(base, local, remote)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"node 'Email'"}, conflicts)
+	})
+
+	t.Run("Applies deletions from one side", func(t *testing.T) {
+		local := mergeTestWorkflow(mergeTestNode("Webhook", map[string]interface{}{"path": "contact"}))
+
+		merged, conflicts, err := n8n.MergeWorkflows(base, local, base)
+		require.NoError(t, err)
+		assert.Empty(t, conflicts)
+		require.Len(t, merged.Nodes, 1)
+		assert.Equal(t, "Webhook", *merged.Nodes[0].Name)
+	})
+}
+
+func TestWorkflowContentHash(t *testing.T) {
+	workflow := mergeTestWorkflow(mergeTestNode("Webhook", map[string]interface{}{"path": "contact"}))
+
+	hash, err := n8n.WorkflowContentHash(workflow)
+	require.NoError(t, err)
+
+	activated := workflow
+	activated.Active = boolPtr(true)
+	activated.Tags = &[]n8n.Tag{{Name: "production"}}
+	activatedHash, err := n8n.WorkflowContentHash(activated)
+	require.NoError(t, err)
+	assert.Equal(t, hash, activatedHash, "Activation and tags should not change the hash")
+
+	changed := mergeTestWorkflow(mergeTestNode("Webhook", map[string]interface{}{"path": "other"}))
+	changedHash, err := n8n.WorkflowContentHash(changed)
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, changedHash)
+
+	parts, err := n8n.ChangedParts(workflow, changed)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"node 'Webhook'"}, parts)
+}
@@ -0,0 +1,197 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyOverlay(t *testing.T) {
+	base := map[string]interface{}{
+		"name": "Contact Form",
+		"settings": map[string]interface{}{
+			"timezone":       "UTC",
+			"executionOrder": "v1",
+		},
+		"nodes": []interface{}{
+			map[string]interface{}{
+				"name": "Webhook",
+				"parameters": map[string]interface{}{
+					"path":       "contact-form",
+					"httpMethod": "POST",
+				},
+			},
+			map[string]interface{}{
+				"name": "Email",
+				"parameters": map[string]interface{}{
+					"toEmail": "dev@example.com",
+				},
+			},
+		},
+	}
+
+	overlay := map[string]interface{}{
+		"settings": map[string]interface{}{
+			"timezone":       "Europe/Berlin",
+			"executionOrder": nil,
+		},
+		"nodes": []interface{}{
+			map[string]interface{}{
+				"name": "Email",
+				"parameters": map[string]interface{}{
+					"toEmail": "ops@example.com",
+				},
+			},
+			map[string]interface{}{
+				"name": "Slack",
+			},
+		},
+	}
+
+	merged := n8n.ApplyOverlay(base, overlay)
+
+	assert.Equal(t, "Contact Form", merged["name"], "Keys missing from the overlay should be kept")
+
+	settings := merged["settings"].(map[string]interface{})
+	assert.Equal(t, "Europe/Berlin", settings["timezone"], "Overlay values should replace base values")
+	assert.NotContains(t, settings, "executionOrder", "Null overlay values should remove the key")
+
+	nodes := merged["nodes"].([]interface{})
+	require.Len(t, nodes, 3, "Nodes should be merged by name and new nodes appended")
+
+	webhook := nodes[0].(map[string]interface{})
+	assert.Equal(t, "contact-form", webhook["parameters"].(map[string]interface{})["path"], "Untouched nodes should be kept")
+
+	email := nodes[1].(map[string]interface{})
+	assert.Equal(t, "ops@example.com", email["parameters"].(map[string]interface{})["toEmail"], "Matching nodes should be merged")
+
+	assert.Equal(t, "Slack", nodes[2].(map[string]interface{})["name"], "New nodes should be appended")
+
+	baseEmail := base["nodes"].([]interface{})[1].(map[string]interface{})
+	assert.Equal(t, "dev@example.com", baseEmail["parameters"].(map[string]interface{})["toEmail"], "Base document should not be modified")
+}
+
+func TestExpandVariables(t *testing.T) {
+	lookup := func(name string) (string, bool) {
+		values := map[string]string{
+			"BASE_URL": "https://n8n.example.com",
+			"EMAIL":    "ops@example.com",
+			"EMPTY":    "",
+		}
+		value, ok := values[name]
+		return value, ok
+	}
+
+	t.Run("Resolves placeholders, defaults and escapes", func(t *testing.T) {
+		document := map[string]interface{}{
+			"url":     "${BASE_URL}/webhook",
+			"emails":  []interface{}{"${EMAIL}", "${CC:-cc@example.com}"},
+			"literal": "$${BASE_URL}",
+			"code":    "={{ $json.body }}",
+			"count":   float64(3),
+		}
+
+		expanded, err := n8n.ExpandVariables(document, lookup)
+		require.NoError(t, err)
+
+		result := expanded.(map[string]interface{})
+		assert.Equal(t, "https://n8n.example.com/webhook", result["url"])
+		assert.Equal(t, []interface{}{"ops@example.com", "cc@example.com"}, result["emails"])
+		assert.Equal(t, "${BASE_URL}", result["literal"], "Escaped placeholders should be kept literally")
+		assert.Equal(t, "={{ $json.body }}", result["code"], "n8n expressions should not be touched")
+		assert.Equal(t, float64(3), result["count"])
+	})
+
+	t.Run("Uses the default when the variable is empty", func(t *testing.T) {
+		document := map[string]interface{}{
+			"default": "${EMPTY:-fallback}",
+			"empty":   "${EMPTY}",
+		}
+
+		expanded, err := n8n.ExpandVariables(document, lookup)
+		require.NoError(t, err)
+
+		result := expanded.(map[string]interface{})
+		assert.Equal(t, "fallback", result["default"])
+		assert.Equal(t, "", result["empty"], "Empty variables without a default should stay empty")
+	})
+
+	t.Run("Leaves code parameters untouched", func(t *testing.T) {
+		document := map[string]interface{}{
+			"nodes": []interface{}{
+				map[string]interface{}{
+					"type": "n8n-nodes-base.code",
+					"parameters": map[string]interface{}{
+						"jsCode":     "return [{ json: { greeting: `Hello ${name}` } }];",
+						"pythonCode": "print(f\"${total}\")",
+						"notice":     "${EMAIL}",
+					},
+				},
+				map[string]interface{}{
+					"type":       "n8n-nodes-base.postgres",
+					"parameters": map[string]interface{}{"query": "SELECT '${literal}'"},
+				},
+				map[string]interface{}{
+					"type":       "n8n-nodes-base.httpRequest",
+					"parameters": map[string]interface{}{"query": "${BASE_URL}"},
+				},
+			},
+		}
+
+		expanded, err := n8n.ExpandVariables(document, lookup)
+		require.NoError(t, err)
+
+		nodes := expanded.(map[string]interface{})["nodes"].([]interface{})
+		code := nodes[0].(map[string]interface{})["parameters"].(map[string]interface{})
+		assert.Equal(t, "return [{ json: { greeting: `Hello ${name}` } }];", code["jsCode"])
+		assert.Equal(t, "print(f\"${total}\")", code["pythonCode"])
+		assert.Equal(t, "ops@example.com", code["notice"], "Other parameters of code nodes should be expanded")
+		assert.Equal(t, "SELECT '${literal}'", nodes[1].(map[string]interface{})["parameters"].(map[string]interface{})["query"])
+		assert.Equal(t, "https://n8n.example.com", nodes[2].(map[string]interface{})["parameters"].(map[string]interface{})["query"],
+			"Parameters are only code for the node types holding code in them")
+	})
+
+	t.Run("Reports all unresolved variables", func(t *testing.T) {
+		document := map[string]interface{}{
+			"a": "${MISSING_B}",
+			"b": "${MISSING_A} and ${EMAIL}",
+		}
+
+		_, err := n8n.ExpandVariables(document, lookup)
+		require.Error(t, err)
+		assert.Equal(t, "unresolved variables: MISSING_A, MISSING_B", err.Error())
+	})
+}
+
+func TestWorkflowDecoder_WithOverlayAndVariables(t *testing.T) {
+	base := []byte(`---
+name: Contact Form
+nodes:
+  - name: Email
+    parameters:
+      toEmail: ${NOTIFY_EMAIL}
+      subject: New contact request
+connections: {}
+`)
+
+	overlay := []byte(`{"nodes": [{"name": "Email", "parameters": {"subject": "[PROD] New contact request"}}]}`)
+
+	decoder := n8n.NewWorkflowDecoder().
+		WithOverlay(overlay).
+		WithVariables(func(name string) (string, bool) {
+			if name == "NOTIFY_EMAIL" {
+				return "ops@example.com", true
+			}
+			return "", false
+		})
+
+	workflow, err := decoder.DecodeFromYAML(base)
+	require.NoError(t, err)
+
+	require.Len(t, workflow.Nodes, 1)
+	parameters := *workflow.Nodes[0].Parameters
+	assert.Equal(t, "ops@example.com", parameters["toEmail"])
+	assert.Equal(t, "[PROD] New contact request", parameters["subject"])
+}
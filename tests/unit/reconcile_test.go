@@ -0,0 +1,231 @@
+package unit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	rootcmd "github.com/edenreich/n8n-cli/cmd"
+	"github.com/edenreich/n8n-cli/cmd/workflows"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/edenreich/n8n-cli/n8n/clientfakes"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconciler(t *testing.T) {
+	inSync := mergeTestWorkflow(mergeTestNode("Webhook", map[string]interface{}{"path": "orders"}))
+	inSync.Id = stringPtr("1")
+	inSync.Name = "Orders"
+
+	setup := func(t *testing.T) (string, *clientfakes.FakeClientInterface) {
+		dir := t.TempDir()
+		writeDriftTestWorkflow(t, dir, "Orders.json", inSync)
+		writeDriftTestWorkflow(t, dir, "New.json", n8n.Workflow{Name: "New"})
+
+		fakeClient := &clientfakes.FakeClientInterface{}
+		fakeClient.GetAllWorkflowsReturns(&n8n.WorkflowList{Data: &[]n8n.Workflow{inSync}}, nil)
+		fakeClient.GetWorkflowReturns(&inSync, nil)
+		fakeClient.CreateWorkflowStub = func(workflow *n8n.Workflow) (*n8n.Workflow, error) {
+			created := *workflow
+			created.Id = stringPtr("2")
+			return &created, nil
+		}
+		return dir, fakeClient
+	}
+
+	getJSON := func(t *testing.T, handler http.Handler, path string, body interface{}) int {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), body))
+		return recorder.Code
+	}
+
+	t.Run("Syncs drifted workflows and reports the result per workflow", func(t *testing.T) {
+		dir, fakeClient := setup(t)
+		reconciler := workflows.NewReconciler(&cobra.Command{}, fakeClient, dir)
+
+		require.NoError(t, reconciler.Reconcile(t.Context()))
+		assert.Equal(t, 1, fakeClient.CreateWorkflowCallCount())
+		assert.Equal(t, 0, fakeClient.UpdateWorkflowCallCount())
+
+		var status workflows.ReconcileStatus
+		code := getJSON(t, reconciler.Handler(), "/status", &status)
+		assert.Equal(t, http.StatusOK, code)
+		assert.True(t, status.Success)
+		require.Len(t, status.Workflows, 2)
+		assert.Equal(t, workflows.ReconcileWorkflowResult{ID: "1", Name: "Orders", File: status.Workflows[0].File, Drift: workflows.DriftInSync, Action: "none"}, status.Workflows[0])
+		assert.Equal(t, "2", status.Workflows[1].ID)
+		assert.Equal(t, workflows.DriftOnlyLocal, status.Workflows[1].Drift)
+		assert.Equal(t, "created", status.Workflows[1].Action)
+
+		var health map[string]interface{}
+		assert.Equal(t, http.StatusOK, getJSON(t, reconciler.Handler(), "/healthz", &health))
+		assert.Equal(t, "ok", health["status"])
+	})
+
+	t.Run("Doesn't sync when nothing drifted", func(t *testing.T) {
+		dir := t.TempDir()
+		writeDriftTestWorkflow(t, dir, "Orders.json", inSync)
+
+		fakeClient := &clientfakes.FakeClientInterface{}
+		fakeClient.GetAllWorkflowsReturns(&n8n.WorkflowList{Data: &[]n8n.Workflow{inSync}}, nil)
+
+		reconciler := workflows.NewReconciler(&cobra.Command{}, fakeClient, dir)
+
+		require.NoError(t, reconciler.Reconcile(t.Context()))
+		assert.Equal(t, 0, fakeClient.GetWorkflowCallCount())
+	})
+
+	// newRemoteClient returns a client that keeps the workflows created and updated through it
+	newRemoteClient := func() *clientfakes.FakeClientInterface {
+		remote := map[string]n8n.Workflow{}
+		fakeClient := &clientfakes.FakeClientInterface{}
+		fakeClient.GetAllWorkflowsStub = func() (*n8n.WorkflowList, error) {
+			var list []n8n.Workflow
+			for _, workflow := range remote {
+				list = append(list, workflow)
+			}
+			return &n8n.WorkflowList{Data: &list}, nil
+		}
+		fakeClient.GetWorkflowsStub = func(limit *int) (*n8n.WorkflowList, error) {
+			return fakeClient.GetAllWorkflowsStub()
+		}
+		fakeClient.GetWorkflowStub = func(id string) (*n8n.Workflow, error) {
+			workflow, exists := remote[id]
+			if !exists {
+				return nil, n8n.ErrWorkflowNotFound
+			}
+			return &workflow, nil
+		}
+		fakeClient.CreateWorkflowStub = func(workflow *n8n.Workflow) (*n8n.Workflow, error) {
+			created := *workflow
+			created.Id = stringPtr(fmt.Sprintf("wf-%d", len(remote)+1))
+			remote[*created.Id] = created
+			return &created, nil
+		}
+		fakeClient.UpdateWorkflowStub = func(id string, workflow *n8n.Workflow) (*n8n.Workflow, error) {
+			updated := *workflow
+			updated.Id = stringPtr(id)
+			remote[id] = updated
+			return &updated, nil
+		}
+		return fakeClient
+	}
+
+	t.Run("Creates workflows from files without an ID only once", func(t *testing.T) {
+		for _, refresh := range []bool{true, false} {
+			dir := t.TempDir()
+			writeDriftTestWorkflow(t, dir, "New.json", n8n.Workflow{Name: "New", Nodes: []n8n.Node{}, Connections: map[string]interface{}{}})
+			fakeClient := newRemoteClient()
+
+			cmd := &cobra.Command{}
+			cmd.Flags().Bool("prune", true, "")
+			cmd.Flags().Bool("refresh", refresh, "")
+			reconciler := workflows.NewReconciler(cmd, fakeClient, dir)
+
+			require.NoError(t, reconciler.Reconcile(t.Context()))
+			require.NoError(t, reconciler.Reconcile(t.Context()))
+
+			assert.Equal(t, 1, fakeClient.CreateWorkflowCallCount(), "refresh: %v", refresh)
+			assert.Equal(t, 0, fakeClient.DeleteWorkflowCallCount(), "refresh: %v", refresh)
+			if refresh {
+				assert.Contains(t, string(mustReadFile(t, filepath.Join(dir, "New.json"))), `"wf-1"`, "The ID should be written back")
+			}
+		}
+	})
+
+	t.Run("Doesn't write to a checkout updated with git pull", func(t *testing.T) {
+		origin := t.TempDir()
+		dir := filepath.Join(t.TempDir(), "checkout")
+		git := func(repo string, args ...string) {
+			command := exec.Command("git", append([]string{"-C", repo, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+			output, err := command.CombinedOutput()
+			require.NoError(t, err, string(output))
+		}
+
+		writeDriftTestWorkflow(t, origin, "New.json", n8n.Workflow{Name: "New", Nodes: []n8n.Node{}, Connections: map[string]interface{}{}})
+		git(origin, "init", "-q")
+		git(origin, "add", ".")
+		git(origin, "commit", "-q", "-m", "Add workflows")
+		git(origin, "clone", "-q", origin, dir)
+		content := mustReadFile(t, filepath.Join(dir, "New.json"))
+
+		fakeClient := newRemoteClient()
+		cmd := &cobra.Command{}
+		cmd.Flags().Bool("git-pull", true, "")
+		cmd.Flags().Bool("refresh", true, "")
+		reconciler := workflows.NewReconciler(cmd, fakeClient, dir)
+
+		require.NoError(t, reconciler.Reconcile(t.Context()))
+		require.NoError(t, reconciler.Reconcile(t.Context()))
+
+		assert.Equal(t, 1, fakeClient.CreateWorkflowCallCount(), "Created workflows should be matched by name")
+		assert.Equal(t, content, mustReadFile(t, filepath.Join(dir, "New.json")))
+		status, err := exec.Command("git", "-C", dir, "status", "--porcelain", "--untracked-files=no").Output()
+		require.NoError(t, err)
+		assert.Empty(t, string(status))
+		assert.True(t, reconciler.Status().Success)
+	})
+
+	t.Run("Reports failures on the health endpoint", func(t *testing.T) {
+		dir, fakeClient := setup(t)
+		fakeClient.GetAllWorkflowsReturns(nil, errors.New("connection refused"))
+
+		reconciler := workflows.NewReconciler(&cobra.Command{}, fakeClient, dir)
+
+		require.Error(t, reconciler.Reconcile(t.Context()))
+		require.Error(t, reconciler.Reconcile(t.Context()))
+
+		var health map[string]interface{}
+		assert.Equal(t, http.StatusServiceUnavailable, getJSON(t, reconciler.Handler(), "/healthz", &health))
+		assert.Equal(t, "failing", health["status"])
+		assert.Equal(t, float64(2), health["consecutiveFailures"])
+		assert.Contains(t, health["error"], "connection refused")
+
+		fakeClient.GetAllWorkflowsReturns(&n8n.WorkflowList{Data: &[]n8n.Workflow{inSync}}, nil)
+		require.NoError(t, reconciler.Reconcile(t.Context()))
+		assert.Equal(t, 0, reconciler.Status().ConsecutiveFailures)
+	})
+}
+
+func TestReconcileDelay(t *testing.T) {
+	tests := []struct {
+		failures int
+		expected time.Duration
+	}{
+		{0, time.Minute},
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{5, 10 * time.Minute},
+		{50, 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, workflows.ReconcileDelay(time.Minute, 10*time.Minute, tt.failures), "failures: %d", tt.failures)
+	}
+}
+
+func TestReconcileCommand(t *testing.T) {
+	cmd := rootcmd.GetReconcileCmd()
+	require.NoError(t, cmd.ParseFlags([]string{"--directory", t.TempDir(), "--git-pull", "--match-by", "id", "--once"}))
+	t.Cleanup(func() {
+		for _, name := range []string{"directory", "git-pull", "match-by", "once"} {
+			flag := cmd.Flags().Lookup(name)
+			require.NoError(t, flag.Value.Set(flag.DefValue))
+			flag.Changed = false
+		}
+	})
+
+	err := cmd.RunE(cmd, nil)
+	require.Error(t, err, "The reconciler registered by the workflows package should reject the flags")
+	assert.Contains(t, err.Error(), "--git-pull can't be used with --match-by id")
+}
@@ -0,0 +1,147 @@
+package unit
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/edenreich/n8n-cli/cmd"
+	"github.com/edenreich/n8n-cli/cmd/workflows"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflowSchema(t *testing.T) {
+	data, err := n8n.MarshalWorkflowSchema()
+	require.NoError(t, err)
+
+	var schema map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &schema))
+
+	t.Run("Describes the workflow fields", func(t *testing.T) {
+		assert.Equal(t, n8n.SchemaDraft, schema["$schema"])
+		assert.Equal(t, "object", schema["type"])
+		assert.Equal(t, true, schema["additionalProperties"], "Unknown workflow fields are kept by the CLI")
+		assert.ElementsMatch(t, []interface{}{"name", "nodes"}, schema["required"])
+
+		properties := schema["properties"].(map[string]interface{})
+		for _, field := range []string{"id", "name", "active", "nodes", "connections", "settings", "tags", "staticData"} {
+			assert.Contains(t, properties, field)
+		}
+		assert.Equal(t, map[string]interface{}{"$ref": "#/definitions/node"}, properties["nodes"].(map[string]interface{})["items"])
+		assert.Equal(t, "#/definitions/workflowSettings", properties["settings"].(map[string]interface{})["$ref"])
+	})
+
+	definitions := schema["definitions"].(map[string]interface{})
+
+	t.Run("Describes the node and settings fields", func(t *testing.T) {
+		node := definitions["node"].(map[string]interface{})
+		assert.Equal(t, true, node["additionalProperties"])
+		nodeProperties := node["properties"].(map[string]interface{})
+		assert.Equal(t, "number", nodeProperties["typeVersion"].(map[string]interface{})["type"])
+		assert.Equal(t, "array", nodeProperties["position"].(map[string]interface{})["type"])
+
+		settings := definitions["workflowSettings"].(map[string]interface{})
+		assert.Equal(t, false, settings["additionalProperties"])
+		saveData := settings["properties"].(map[string]interface{})["saveDataErrorExecution"].(map[string]interface{})
+		assert.Equal(t, []interface{}{"all", "none"}, saveData["enum"])
+	})
+
+	t.Run("Describes the parameters of known node types", func(t *testing.T) {
+		node := definitions["node"].(map[string]interface{})
+		nodeTypes := map[string]map[string]interface{}{}
+		for _, condition := range node["allOf"].([]interface{}) {
+			c := condition.(map[string]interface{})
+			nodeType := c["if"].(map[string]interface{})["properties"].(map[string]interface{})["type"].(map[string]interface{})["const"].(string)
+			parameters := c["then"].(map[string]interface{})["properties"].(map[string]interface{})["parameters"].(map[string]interface{})
+			nodeTypes[nodeType] = parameters["properties"].(map[string]interface{})
+		}
+
+		assert.Contains(t, nodeTypes["n8n-nodes-base.postgres"], "query")
+		assert.Contains(t, nodeTypes["n8n-nodes-base.html"], "html")
+		assert.Contains(t, nodeTypes["n8n-nodes-base.executeWorkflow"], "workflowId")
+		assert.Contains(t, nodeTypes["n8n-nodes-base.webhook"], "httpMethod")
+
+		parameters := node["properties"].(map[string]interface{})["parameters"].(map[string]interface{})["properties"].(map[string]interface{})
+		jsCode := parameters["jsCode"].(map[string]interface{})
+		assert.Len(t, jsCode["anyOf"], 2, "Code may be inline or reference a file")
+		assert.NotContains(t, parameters, "query", "Only database nodes hold SQL queries")
+	})
+}
+
+func TestSchemaWorkflowCommand(t *testing.T) {
+	workflowCmd, _, err := cmd.GetSchemaCmd().Find([]string{"workflow"})
+	require.NoError(t, err)
+
+	outBuf := new(bytes.Buffer)
+	workflowCmd.SetOut(outBuf)
+	require.NoError(t, workflowCmd.RunE(workflowCmd, nil))
+
+	expected, err := n8n.MarshalWorkflowSchema()
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), outBuf.String())
+}
+
+func TestRefreshWorkflows_SchemaHeader(t *testing.T) {
+	viper.Set("schema", true)
+	t.Cleanup(func() { viper.Set("schema", false) })
+
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "Contact_Form.yaml")
+	require.NoError(t, os.WriteFile(filePath, []byte(`# Reviewed by the growth team
+id: "1"
+name: Contact Form
+nodes: []
+connections: {}
+settings: {}
+`), 0644))
+
+	remote := n8n.Workflow{Id: stringPtr("1"), Name: "Contact Form", Nodes: []n8n.Node{}, Connections: map[string]interface{}{}}
+	fakeClient := newWatchTestClient()
+	fakeClient.GetWorkflowReturns(&remote, nil)
+
+	t.Run("Adds the header to unchanged files and writes the schema", func(t *testing.T) {
+		require.NoError(t, workflows.RefreshWorkflowsWithClient(newSelectTestCmd(t), fakeClient, dir, false, false, "", true, false))
+
+		content := string(mustReadFile(t, filePath))
+		assert.True(t, strings.HasPrefix(content, "# yaml-language-server: $schema=.n8n/workflow.schema.json\n"), content)
+		assert.Contains(t, content, "# Reviewed by the growth team", "Existing comments should be kept")
+
+		expected, err := n8n.MarshalWorkflowSchema()
+		require.NoError(t, err)
+		assert.Equal(t, expected, mustReadFile(t, filepath.Join(dir, ".n8n", "workflow.schema.json")))
+	})
+
+	t.Run("Doesn't rewrite files that have the header", func(t *testing.T) {
+		outBuf := new(bytes.Buffer)
+		cmd := newSelectTestCmd(t)
+		cmd.SetOut(outBuf)
+		require.NoError(t, workflows.RefreshWorkflowsWithClient(cmd, fakeClient, dir, false, false, "", true, false))
+		assert.Contains(t, outBuf.String(), "No changes for workflow 'Contact Form'")
+		assert.Equal(t, 1, strings.Count(string(mustReadFile(t, filePath)), "yaml-language-server"))
+	})
+
+	t.Run("Workflow directories reference the schema from their parent", func(t *testing.T) {
+		remote := n8n.Workflow{Id: stringPtr("2"), Name: "Orders", Nodes: []n8n.Node{}, Connections: map[string]interface{}{}}
+		dirClient := newWatchTestClient()
+		dirClient.GetWorkflowReturns(&remote, nil)
+
+		target := t.TempDir()
+		writeDriftTestWorkflow(t, target, "Orders.json", n8n.Workflow{Id: remote.Id, Name: remote.Name})
+		require.NoError(t, workflows.RefreshWorkflowsWithClient(newSelectTestCmd(t), dirClient, target, false, false, "dir", true, false))
+
+		content := string(mustReadFile(t, filepath.Join(target, "Orders", n8n.DirectoryWorkflowFile)))
+		assert.True(t, strings.HasPrefix(content, "# yaml-language-server: $schema=../.n8n/workflow.schema.json\n"), content)
+	})
+
+	t.Run("Formatting keeps the header", func(t *testing.T) {
+		_, err := workflows.FormatWorkflowFiles(newSelectTestCmd(t), dir, false, true)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(mustReadFile(t, filePath)), "# yaml-language-server: $schema="))
+	})
+}
@@ -0,0 +1,308 @@
+package unit
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/edenreich/n8n-cli/cmd/workflows"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/edenreich/n8n-cli/n8n/clientfakes"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// archiveTestWorkflows returns an instance with a billing workflow calling a helper workflow, and a workflow
+// that is not tagged
+func archiveTestWorkflows() []n8n.Workflow {
+	return []n8n.Workflow{
+		{
+			Id:   stringPtr("1"),
+			Name: "Orders",
+			Nodes: []n8n.Node{
+				{
+					Name:        stringPtr("Fetch Orders"),
+					Type:        stringPtr("n8n-nodes-base.httpRequest"),
+					Parameters:  &map[string]interface{}{"url": "={{ $vars.API_URL }}/orders", "headers": "={{ $vars['API_TENANT'] }}"},
+					Credentials: &map[string]interface{}{"httpBasicAuth": map[string]interface{}{"id": "cred-1", "name": "Shop API"}},
+				},
+				{
+					Name:       stringPtr("Run Helper"),
+					Type:       stringPtr("n8n-nodes-base.executeWorkflow"),
+					Parameters: &map[string]interface{}{"source": "database", "workflowId": "3"},
+				},
+			},
+			Connections: map[string]interface{}{},
+			Tags:        &[]n8n.Tag{{Id: stringPtr("7"), Name: "billing"}},
+		},
+		{Id: stringPtr("2"), Name: "Invoices", Nodes: []n8n.Node{}, Connections: map[string]interface{}{}},
+		{Id: stringPtr("3"), Name: "Helper", Nodes: []n8n.Node{}, Connections: map[string]interface{}{}},
+	}
+}
+
+// rewriteArchive changes the files of an archive without updating its manifest
+func rewriteArchive(t *testing.T, archivePath string, change func(files map[string][]byte)) {
+	content, err := os.ReadFile(archivePath)
+	require.NoError(t, err)
+
+	gzipReader, err := gzip.NewReader(bytes.NewReader(content))
+	require.NoError(t, err)
+	tarReader := tar.NewReader(gzipReader)
+
+	files := make(map[string][]byte)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		files[header.Name], err = io.ReadAll(tarReader)
+		require.NoError(t, err)
+	}
+
+	change(files)
+
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for name, file := range files {
+		require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(file)), Typeflag: tar.TypeReg}))
+		_, err := tarWriter.Write(file)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tarWriter.Close())
+	require.NoError(t, gzipWriter.Close())
+	require.NoError(t, os.WriteFile(archivePath, buf.Bytes(), 0644))
+}
+
+func TestArchive_RoundTrip(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "bundle.tar.gz")
+
+	archive := workflows.NewArchive(archiveTestWorkflows(), []n8n.Variable{{Key: "API_URL", Value: "https://shop.example.com"}})
+	require.NoError(t, archive.Write(archivePath))
+
+	read, err := workflows.ReadArchive(archivePath)
+	require.NoError(t, err)
+
+	assert.Equal(t, workflows.ArchiveFormatVersion, read.Manifest.Version)
+	require.Len(t, read.Manifest.Workflows, 3)
+	assert.Equal(t, workflows.ArchiveEntry{ID: "1", Name: "Orders", File: "workflows/Orders.json"}, read.Manifest.Workflows[0])
+	assert.Len(t, read.Manifest.Checksums, 6, "Every file except the manifest should have a checksum")
+	assert.Equal(t, archive.Workflows, read.Workflows)
+	assert.Equal(t, []n8n.Tag{{Id: stringPtr("7"), Name: "billing"}}, read.Tags)
+	assert.Equal(t, []n8n.Variable{{Key: "API_URL", Value: "https://shop.example.com"}}, read.Variables)
+	assert.Equal(t, []workflows.CredentialStub{{ID: "cred-1", Name: "Shop API", Type: "httpBasicAuth"}}, read.Credentials)
+
+	t.Run("Same content gives the same archive", func(t *testing.T) {
+		first, err := os.ReadFile(archivePath)
+		require.NoError(t, err)
+		require.NoError(t, archive.Write(archivePath))
+		second, err := os.ReadFile(archivePath)
+		require.NoError(t, err)
+		assert.Equal(t, first, second)
+	})
+}
+
+func TestReadArchive_Verification(t *testing.T) {
+	tests := []struct {
+		name          string
+		change        func(files map[string][]byte)
+		errorContains string
+	}{
+		{
+			name:          "Modified file",
+			change:        func(files map[string][]byte) { files["workflows/Orders.json"] = []byte(`{"name": "Orders"}`) },
+			errorContains: "workflows/Orders.json doesn't match its checksum",
+		},
+		{
+			name:          "Missing file",
+			change:        func(files map[string][]byte) { delete(files, "tags.json") },
+			errorContains: "tags.json is missing",
+		},
+		{
+			name:          "Unexpected file",
+			change:        func(files map[string][]byte) { files["workflows/Extra.json"] = []byte(`{}`) },
+			errorContains: "workflows/Extra.json is not listed in the manifest",
+		},
+		{
+			name:          "Entry outside the archive",
+			change:        func(files map[string][]byte) { files["../escape.json"] = []byte(`{}`) },
+			errorContains: "unexpected entry ../escape.json",
+		},
+		{
+			name:          "Newer format",
+			change:        func(files map[string][]byte) { files["manifest.json"] = []byte(`{"version": 99}`) },
+			errorContains: "archive format version 99 is not supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archivePath := filepath.Join(t.TempDir(), "bundle.tar.gz")
+			require.NoError(t, workflows.NewArchive(archiveTestWorkflows(), nil).Write(archivePath))
+			rewriteArchive(t, archivePath, tt.change)
+
+			_, err := workflows.ReadArchive(archivePath)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorContains)
+		})
+	}
+}
+
+func TestVariableReferences(t *testing.T) {
+	workflow := archiveTestWorkflows()[0]
+	assert.Equal(t, []string{"API_TENANT", "API_URL"}, workflows.VariableReferences(&workflow))
+
+	helper := archiveTestWorkflows()[2]
+	assert.Empty(t, workflows.VariableReferences(&helper))
+}
+
+func TestExportArchive(t *testing.T) {
+	remoteWorkflows := archiveTestWorkflows()
+	fakeClient := &clientfakes.FakeClientInterface{}
+	fakeClient.GetAllWorkflowsReturns(&n8n.WorkflowList{Data: &remoteWorkflows}, nil)
+	fakeClient.GetVariablesReturns(&n8n.VariableList{Data: &[]n8n.Variable{
+		{Id: stringPtr("v1"), Key: "API_URL", Value: "https://shop.example.com"},
+		{Id: stringPtr("v2"), Key: "UNUSED", Value: "x"},
+	}}, nil)
+
+	cmd := newSelectTestCmd(t, "--tag", "billing")
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+
+	archivePath := filepath.Join(t.TempDir(), "billing.tar.gz")
+	archive, err := workflows.ExportArchive(cmd, fakeClient, archivePath)
+	require.NoError(t, err)
+
+	require.Len(t, archive.Workflows, 2)
+	assert.Equal(t, "Orders", archive.Workflows[0].Name)
+	assert.Equal(t, "Helper", archive.Workflows[1].Name, "Called workflows should be included")
+	assert.Equal(t, []n8n.Variable{{Key: "API_URL", Value: "https://shop.example.com"}}, archive.Variables)
+	assert.Contains(t, out.String(), "Including workflow 'Helper' (ID: 3) referenced by 'Orders'")
+	assert.Contains(t, out.String(), "Warning: variable 'API_TENANT' used by workflow 'Orders' doesn't exist on the instance")
+	assert.FileExists(t, archivePath)
+
+	t.Run("More workflows than fit in one page", func(t *testing.T) {
+		var all []n8n.Workflow
+		for i := 0; i < n8n.MaxLimit; i++ {
+			all = append(all, n8n.Workflow{Id: stringPtr(fmt.Sprintf("other-%d", i)), Name: fmt.Sprintf("Other %d", i)})
+		}
+		all = append(all, remoteWorkflows[1])
+
+		pagedClient := &clientfakes.FakeClientInterface{}
+		pagedClient.GetAllWorkflowsReturns(&n8n.WorkflowList{Data: &all}, nil)
+		pagedClient.GetVariablesReturns(&n8n.VariableList{Data: &[]n8n.Variable{}}, nil)
+
+		cmd := newSelectTestCmd(t, "--name", remoteWorkflows[1].Name)
+		archive, err := workflows.ExportArchive(cmd, pagedClient, filepath.Join(t.TempDir(), "paged.tar.gz"))
+		require.NoError(t, err)
+		require.Len(t, archive.Workflows, 1)
+		assert.Equal(t, remoteWorkflows[1].Name, archive.Workflows[0].Name)
+		assert.Equal(t, 0, pagedClient.GetWorkflowCallCount())
+	})
+
+	t.Run("Nothing selected", func(t *testing.T) {
+		cmd := newSelectTestCmd(t, "--tag", "unknown")
+		_, err := workflows.ExportArchive(cmd, fakeClient, filepath.Join(t.TempDir(), "empty.tar.gz"))
+		assert.Error(t, err)
+	})
+}
+
+func TestImportArchive(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "billing.tar.gz")
+	source := archiveTestWorkflows()
+	archive := workflows.NewArchive([]n8n.Workflow{source[0], source[2]}, []n8n.Variable{{Key: "API_URL", Value: "https://shop.example.com"}})
+	require.NoError(t, archive.Write(archivePath))
+
+	// The target instance has the helper workflow and the credential under other IDs
+	newTargetClient := func() *clientfakes.FakeClientInterface {
+		targetWorkflows := []n8n.Workflow{
+			{Id: stringPtr("t-9"), Name: "Helper", Nodes: []n8n.Node{}, Connections: map[string]interface{}{}},
+			{Id: stringPtr("t-5"), Name: "Other", Nodes: []n8n.Node{{
+				Name:        stringPtr("Request"),
+				Credentials: &map[string]interface{}{"httpBasicAuth": map[string]interface{}{"id": "target-cred", "name": "Shop API"}},
+			}}, Connections: map[string]interface{}{}},
+		}
+
+		fakeClient := newWatchTestClient()
+		fakeClient.GetAllWorkflowsReturns(&n8n.WorkflowList{Data: &targetWorkflows}, nil)
+		fakeClient.GetTagsReturns(&n8n.TagList{Data: &[]n8n.Tag{}}, nil)
+		fakeClient.CreateTagReturns(&n8n.Tag{Id: stringPtr("t-tag"), Name: "billing"}, nil)
+		fakeClient.GetVariablesReturns(&n8n.VariableList{Data: &[]n8n.Variable{}}, nil)
+		return fakeClient
+	}
+
+	newImportTestCmd := func(t *testing.T) (*cobra.Command, *bytes.Buffer) {
+		cmd := &cobra.Command{}
+		cmd.Flags().String("match-by", workflows.MatchByName, "")
+		cmd.Flags().String("credentials-map", "", "")
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		return cmd, out
+	}
+
+	t.Run("Import with ID remapping", func(t *testing.T) {
+		fakeClient := newTargetClient()
+		cmd, out := newImportTestCmd(t)
+
+		results, err := workflows.ImportArchive(cmd, fakeClient, archivePath, false)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Contains(t, out.String(), "Verified "+archivePath+": 2 workflow(s), 1 tag(s), 1 variable(s) and 1 credential(s)")
+		assert.Contains(t, out.String(), "Matched Helper.json to workflow 'Helper' (ID: t-9) by name")
+
+		require.Equal(t, 1, fakeClient.CreateTagCallCount())
+		require.Equal(t, 1, fakeClient.CreateVariableCallCount())
+		assert.Equal(t, "API_URL", fakeClient.CreateVariableArgsForCall(0).Key)
+
+		require.Equal(t, 1, fakeClient.CreateWorkflowCallCount(), "Only the workflow missing on the instance should be created")
+		created := fakeClient.CreateWorkflowArgsForCall(0)
+		assert.Equal(t, "Orders", created.Name)
+		assert.Nil(t, created.Id)
+		assert.Equal(t, "t-9", (*created.Nodes[1].Parameters)["workflowId"], "References should point to the workflow on the instance")
+		assert.Equal(t, map[string]interface{}{"id": "target-cred", "name": "Shop API"}, (*created.Nodes[0].Credentials)["httpBasicAuth"])
+		assert.Equal(t, "t-tag", *(*created.Tags)[0].Id)
+	})
+
+	t.Run("Existing variables are kept", func(t *testing.T) {
+		fakeClient := newTargetClient()
+		fakeClient.GetVariablesReturns(&n8n.VariableList{Data: &[]n8n.Variable{{Key: "API_URL", Value: "https://other.example.com"}}}, nil)
+		cmd, out := newImportTestCmd(t)
+
+		_, err := workflows.ImportArchive(cmd, fakeClient, archivePath, false)
+		require.NoError(t, err)
+		assert.Equal(t, 0, fakeClient.CreateVariableCallCount())
+		assert.Contains(t, out.String(), "Keeping existing variable 'API_URL'")
+	})
+
+	t.Run("Unresolved credentials", func(t *testing.T) {
+		fakeClient := newTargetClient()
+		fakeClient.GetAllWorkflowsReturns(&n8n.WorkflowList{Data: &[]n8n.Workflow{}}, nil)
+		cmd, _ := newImportTestCmd(t)
+
+		_, err := workflows.ImportArchive(cmd, fakeClient, archivePath, false)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "httpBasicAuth 'Shop API'")
+		assert.Equal(t, 0, fakeClient.CreateWorkflowCallCount(), "Nothing should be imported")
+		assert.Equal(t, 0, fakeClient.CreateTagCallCount())
+	})
+
+	t.Run("Dry run", func(t *testing.T) {
+		fakeClient := newTargetClient()
+		cmd, out := newImportTestCmd(t)
+
+		_, err := workflows.ImportArchive(cmd, fakeClient, archivePath, true)
+		require.NoError(t, err)
+		assert.Equal(t, 0, fakeClient.CreateWorkflowCallCount())
+		assert.Equal(t, 0, fakeClient.UpdateWorkflowCallCount())
+		assert.Equal(t, 0, fakeClient.CreateVariableCallCount())
+		assert.Contains(t, out.String(), "Would create variable 'API_URL'")
+	})
+}
@@ -0,0 +1,170 @@
+package unit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/edenreich/n8n-cli/cmd/workflows"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/edenreich/n8n-cli/n8n/clientfakes"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupClient(t *testing.T) {
+	backupPath := filepath.Join(t.TempDir(), "backup")
+
+	remote := n8n.Workflow{
+		Id:     stringPtr("wf-1"),
+		Name:   "Contact Form",
+		Active: boolPtr(true),
+		Tags:   &[]n8n.Tag{{Id: stringPtr("tag-1"), Name: "production"}},
+	}
+
+	fakeClient := &clientfakes.FakeClientInterface{}
+	fakeClient.GetWorkflowReturns(&remote, nil)
+	fakeClient.CreateWorkflowReturns(&n8n.Workflow{Id: stringPtr("wf-new"), Name: "New"}, nil)
+
+	client := &workflows.BackupClient{ClientInterface: fakeClient, Backup: workflows.NewBackup(backupPath)}
+
+	_, err := client.UpdateWorkflow("wf-1", &n8n.Workflow{Name: "Contact Form"})
+	require.NoError(t, err)
+	_, err = client.DeactivateWorkflow("wf-1")
+	require.NoError(t, err)
+	_, err = client.CreateWorkflow(&n8n.Workflow{Name: "New"})
+	require.NoError(t, err)
+	_, err = client.ActivateWorkflow("wf-new")
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, fakeClient.GetWorkflowCallCount(), "Workflows should only be saved before their first change")
+
+	backup, err := workflows.LoadBackup(backupPath)
+	require.NoError(t, err)
+	require.Len(t, backup.Manifest.Workflows, 1)
+	assert.Equal(t, []string{"wf-new"}, backup.Manifest.Created)
+
+	saved, err := backup.Workflow(backup.Manifest.Workflows[0])
+	require.NoError(t, err)
+	assert.Equal(t, "Contact Form", saved.Name)
+	assert.True(t, *saved.Active)
+	assert.Equal(t, "production", (*saved.Tags)[0].Name)
+
+	t.Run("Fails the change when the backup fails", func(t *testing.T) {
+		failingClient := &clientfakes.FakeClientInterface{}
+		failingClient.GetWorkflowReturns(nil, errors.New("connection refused"))
+
+		client := &workflows.BackupClient{ClientInterface: failingClient, Backup: workflows.NewBackup(t.TempDir())}
+
+		err := client.DeleteWorkflow("wf-1")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "error backing up workflow wf-1")
+		assert.Equal(t, 0, failingClient.DeleteWorkflowCallCount())
+	})
+
+	t.Run("Records created tags", func(t *testing.T) {
+		tagClient := &clientfakes.FakeClientInterface{}
+		tagClient.CreateTagReturns(&n8n.Tag{Id: stringPtr("tag-new"), Name: "staging"}, nil)
+
+		tagBackupPath := t.TempDir()
+		client := &workflows.BackupClient{ClientInterface: tagClient, Backup: workflows.NewBackup(tagBackupPath)}
+
+		_, err := client.CreateTag("staging")
+		require.NoError(t, err)
+		assert.False(t, client.Backup.Empty())
+
+		backup, err := workflows.LoadBackup(tagBackupPath)
+		require.NoError(t, err)
+		assert.Equal(t, []workflows.BackupTag{{ID: "tag-new", Name: "staging"}}, backup.Manifest.CreatedTags)
+	})
+}
+
+func newRollbackTestCmd(t *testing.T, keepCreated bool) (*cobra.Command, *bytes.Buffer) {
+	cmd := &cobra.Command{}
+	cmd.Flags().Bool("dry-run", false, "")
+	cmd.Flags().Bool("keep-created", keepCreated, "")
+
+	outBuf := new(bytes.Buffer)
+	cmd.SetOut(outBuf)
+	return cmd, outBuf
+}
+
+func TestRollbackWorkflowsWithClient(t *testing.T) {
+	backupPath := t.TempDir()
+	backup := workflows.NewBackup(backupPath)
+	require.NoError(t, backup.Save(n8n.Workflow{
+		Id:     stringPtr("wf-1"),
+		Name:   "Contact Form",
+		Active: boolPtr(true),
+		Nodes:  []n8n.Node{{Name: stringPtr("Webhook")}},
+		Tags:   &[]n8n.Tag{{Id: stringPtr("deleted-tag"), Name: "production"}},
+	}))
+	require.NoError(t, backup.RecordCreated("wf-new"))
+	require.NoError(t, backup.RecordCreatedTag(n8n.Tag{Id: stringPtr("tag-new"), Name: "staging"}))
+
+	t.Run("Restores content, activation and tags", func(t *testing.T) {
+		fakeClient := &clientfakes.FakeClientInterface{}
+		fakeClient.GetWorkflowReturns(&n8n.Workflow{Id: stringPtr("wf-1"), Name: "Contact Form", Active: boolPtr(false)}, nil)
+		fakeClient.UpdateWorkflowReturns(&n8n.Workflow{Id: stringPtr("wf-1")}, nil)
+		fakeClient.ActivateWorkflowReturns(&n8n.Workflow{Id: stringPtr("wf-1")}, nil)
+		fakeClient.GetTagsReturns(&n8n.TagList{Data: &[]n8n.Tag{{Id: stringPtr("tag-1"), Name: "production"}}}, nil)
+
+		cmd, outBuf := newRollbackTestCmd(t, false)
+
+		err := workflows.RollbackWorkflowsWithClient(cmd, fakeClient, backupPath)
+		require.NoError(t, err)
+
+		require.Equal(t, 1, fakeClient.UpdateWorkflowCallCount())
+		id, restored := fakeClient.UpdateWorkflowArgsForCall(0)
+		assert.Equal(t, "wf-1", id)
+		assert.Equal(t, "Webhook", *restored.Nodes[0].Name)
+
+		assert.Equal(t, 1, fakeClient.ActivateWorkflowCallCount())
+
+		require.Equal(t, 1, fakeClient.UpdateWorkflowTagsCallCount())
+		_, tagIDs := fakeClient.UpdateWorkflowTagsArgsForCall(0)
+		assert.Equal(t, "tag-1", tagIDs[0].Id, "Tags should be restored by name")
+
+		require.Equal(t, 1, fakeClient.DeleteWorkflowCallCount())
+		assert.Equal(t, "wf-new", fakeClient.DeleteWorkflowArgsForCall(0))
+		require.Equal(t, 1, fakeClient.DeleteTagCallCount())
+		assert.Equal(t, "tag-new", fakeClient.DeleteTagArgsForCall(0))
+		assert.Contains(t, outBuf.String(), "Deleted tag 'staging' that didn't exist before")
+		assert.Contains(t, outBuf.String(), "Rollback completed successfully")
+	})
+
+	t.Run("Recreates deleted workflows and keeps created ones", func(t *testing.T) {
+		fakeClient := &clientfakes.FakeClientInterface{}
+		fakeClient.GetWorkflowReturns(nil, fmt.Errorf("%w: API returned error 404: not found", n8n.ErrWorkflowNotFound))
+		fakeClient.CreateWorkflowReturns(&n8n.Workflow{Id: stringPtr("wf-2"), Name: "Contact Form"}, nil)
+		fakeClient.ActivateWorkflowReturns(&n8n.Workflow{Id: stringPtr("wf-2")}, nil)
+		fakeClient.GetTagsReturns(&n8n.TagList{Data: &[]n8n.Tag{{Id: stringPtr("tag-1"), Name: "production"}}}, nil)
+
+		cmd, outBuf := newRollbackTestCmd(t, true)
+
+		err := workflows.RollbackWorkflowsWithClient(cmd, fakeClient, backupPath)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, fakeClient.CreateWorkflowCallCount())
+		assert.Equal(t, "wf-2", fakeClient.ActivateWorkflowArgsForCall(0))
+		assert.Equal(t, 0, fakeClient.DeleteWorkflowCallCount())
+		assert.Equal(t, 0, fakeClient.DeleteTagCallCount())
+		assert.Contains(t, outBuf.String(), "Recreated workflow 'Contact Form' with new ID wf-2")
+	})
+
+	t.Run("Doesn't recreate workflows on other errors", func(t *testing.T) {
+		fakeClient := &clientfakes.FakeClientInterface{}
+		fakeClient.GetWorkflowReturns(nil, errors.New("API returned error 503: unavailable"))
+
+		cmd, outBuf := newRollbackTestCmd(t, true)
+
+		err := workflows.RollbackWorkflowsWithClient(cmd, fakeClient, backupPath)
+		require.Error(t, err)
+		assert.Equal(t, 0, fakeClient.CreateWorkflowCallCount())
+		assert.Equal(t, 0, fakeClient.UpdateWorkflowCallCount())
+		assert.Contains(t, outBuf.String(), "error fetching workflow 'Contact Form' (ID: wf-1)")
+	})
+}
@@ -0,0 +1,150 @@
+package unit
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/edenreich/n8n-cli/cmd/workflows"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/edenreich/n8n-cli/n8n/clientfakes"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newConflictTestCmd(t *testing.T, flag string) *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().Bool("force", false, "")
+	cmd.Flags().Bool("theirs", false, "")
+	cmd.Flags().Bool("merge", false, "")
+	if flag != "" {
+		require.NoError(t, cmd.Flags().Set(flag, "true"))
+	}
+	cmd.SetOut(new(bytes.Buffer))
+	return cmd
+}
+
+func TestProcessWorkflow_DetectsRemoteChanges(t *testing.T) {
+	refreshedAt := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+	editedAt := time.Date(2025, 5, 2, 10, 0, 0, 0, time.UTC)
+
+	base := mergeTestWorkflow(
+		mergeTestNode("Webhook", map[string]interface{}{"path": "contact"}),
+		mergeTestNode("Email", map[string]interface{}{"to": "dev@example.com"}),
+	)
+	base.UpdatedAt = &refreshedAt
+
+	remote := mergeTestWorkflow(
+		mergeTestNode("Webhook", map[string]interface{}{"path": "contact"}),
+		mergeTestNode("Email", map[string]interface{}{"to": "hotfix@example.com"}),
+	)
+	remote.UpdatedAt = &editedAt
+
+	setup := func(t *testing.T) (*clientfakes.FakeClientInterface, *workflows.WorkflowState) {
+		state, err := workflows.LoadWorkflowState(t.TempDir())
+		require.NoError(t, err)
+		require.NoError(t, state.Record(base))
+
+		fakeClient := &clientfakes.FakeClientInterface{}
+		fakeClient.GetWorkflowReturns(&remote, nil)
+		fakeClient.UpdateWorkflowStub = func(id string, workflow *n8n.Workflow) (*n8n.Workflow, error) {
+			updated := *workflow
+			return &updated, nil
+		}
+		return fakeClient, state
+	}
+
+	localWebhookChange := mergeTestWorkflow(
+		mergeTestNode("Webhook", map[string]interface{}{"path": "contact-form"}),
+		mergeTestNode("Email", map[string]interface{}{"to": "dev@example.com"}),
+	)
+
+	t.Run("Stops with a conflict report", func(t *testing.T) {
+		local := localWebhookChange
+		fakeClient, state := setup(t)
+
+		_, err := workflows.ProcessWorkflow(fakeClient, newConflictTestCmd(t, ""), &local, "Contact_Form.json", false, state)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "conflict: workflow 'Contact Form' (ID: wf-1) was changed in n8n")
+		assert.Contains(t, err.Error(), "changed locally: node 'Webhook'")
+		assert.Contains(t, err.Error(), "changed in n8n: node 'Email'")
+		assert.Equal(t, 0, fakeClient.UpdateWorkflowCallCount())
+	})
+
+	t.Run("Keeps the remote version with --theirs", func(t *testing.T) {
+		local := localWebhookChange
+		fakeClient, state := setup(t)
+
+		result, err := workflows.ProcessWorkflow(fakeClient, newConflictTestCmd(t, "theirs"), &local, "Contact_Form.json", false, state)
+		require.NoError(t, err)
+		assert.Equal(t, "wf-1", result.WorkflowID)
+		assert.Equal(t, 0, fakeClient.UpdateWorkflowCallCount())
+	})
+
+	t.Run("Overwrites the remote version with --force", func(t *testing.T) {
+		local := localWebhookChange
+		fakeClient, state := setup(t)
+
+		_, err := workflows.ProcessWorkflow(fakeClient, newConflictTestCmd(t, "force"), &local, "Contact_Form.json", false, state)
+		require.NoError(t, err)
+		require.Equal(t, 1, fakeClient.UpdateWorkflowCallCount())
+
+		_, uploaded := fakeClient.UpdateWorkflowArgsForCall(0)
+		assert.Equal(t, "dev@example.com", (*uploaded.Nodes[1].Parameters)["to"])
+	})
+
+	t.Run("Merges non overlapping changes with --merge", func(t *testing.T) {
+		local := localWebhookChange
+		fakeClient, state := setup(t)
+
+		_, err := workflows.ProcessWorkflow(fakeClient, newConflictTestCmd(t, "merge"), &local, "Contact_Form.json", false, state)
+		require.NoError(t, err)
+		require.Equal(t, 1, fakeClient.UpdateWorkflowCallCount())
+
+		_, uploaded := fakeClient.UpdateWorkflowArgsForCall(0)
+		assert.Equal(t, "contact-form", (*uploaded.Nodes[0].Parameters)["path"])
+		assert.Equal(t, "hotfix@example.com", (*uploaded.Nodes[1].Parameters)["to"])
+
+		_, changed, err := state.RemoteChanged(*uploaded)
+		require.NoError(t, err)
+		assert.False(t, changed, "The uploaded version should be recorded")
+	})
+
+	t.Run("Fails to merge nodes changed on both sides", func(t *testing.T) {
+		local := mergeTestWorkflow(
+			mergeTestNode("Webhook", map[string]interface{}{"path": "contact"}),
+			mergeTestNode("Email", map[string]interface{}{"to": "local@example.com"}),
+		)
+		fakeClient, state := setup(t)
+
+		_, err := workflows.ProcessWorkflow(fakeClient, newConflictTestCmd(t, "merge"), &local, "Contact_Form.json", false, state)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "changed on both sides: node 'Email'")
+		assert.Equal(t, 0, fakeClient.UpdateWorkflowCallCount())
+	})
+
+	t.Run("Updates when the remote version is unchanged", func(t *testing.T) {
+		local := localWebhookChange
+		fakeClient, state := setup(t)
+		fakeClient.GetWorkflowReturns(&base, nil)
+
+		_, err := workflows.ProcessWorkflow(fakeClient, newConflictTestCmd(t, ""), &local, "Contact_Form.json", false, state)
+		require.NoError(t, err)
+		assert.Equal(t, 1, fakeClient.UpdateWorkflowCallCount())
+	})
+}
+
+func TestWorkflowState_SaveAndLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	state, err := workflows.LoadWorkflowState(dir)
+	require.NoError(t, err)
+	require.NoError(t, state.Record(mergeTestWorkflow(mergeTestNode("Webhook", map[string]interface{}{"path": "contact"}))))
+	require.NoError(t, state.Save())
+
+	loaded, err := workflows.LoadWorkflowState(dir)
+	require.NoError(t, err)
+	require.Contains(t, loaded.Workflows, "wf-1")
+	assert.Equal(t, state.Workflows["wf-1"].Hash, loaded.Workflows["wf-1"].Hash)
+}
@@ -0,0 +1,110 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/edenreich/n8n-cli/cmd/workflows"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/edenreich/n8n-cli/n8n/clientfakes"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func credentialNode(name string, credType string, credName string, credID string) n8n.Node {
+	return n8n.Node{
+		Name: stringPtr(name),
+		Credentials: &map[string]interface{}{
+			credType: map[string]interface{}{"id": credID, "name": credName},
+		},
+	}
+}
+
+func TestCredentialResolver_Resolve(t *testing.T) {
+	remote := n8n.Workflow{
+		Name: "Remote",
+		Nodes: []n8n.Node{
+			credentialNode("Email", "smtp", "Mailgun", "prod-smtp-id"),
+			credentialNode("Slack", "slackApi", "Team Slack", "prod-slack-1"),
+			credentialNode("Slack 2", "slackApi", "Team Slack", "prod-slack-2"),
+		},
+	}
+
+	fakeClient := &clientfakes.FakeClientInterface{}
+	fakeClient.GetAllWorkflowsReturns(&n8n.WorkflowList{Data: &[]n8n.Workflow{remote}}, nil)
+
+	resolver := workflows.NewCredentialResolver()
+	require.NoError(t, resolver.LoadFromInstance(fakeClient))
+
+	t.Run("Rewrites IDs by type and name", func(t *testing.T) {
+		workflow := n8n.Workflow{Name: "Local", Nodes: []n8n.Node{credentialNode("Email", "smtp", "Mailgun", "dev-smtp-id")}}
+
+		unresolved := resolver.Resolve(&workflow)
+		assert.Empty(t, unresolved)
+
+		credential := (*workflow.Nodes[0].Credentials)["smtp"].(map[string]interface{})
+		assert.Equal(t, "prod-smtp-id", credential["id"])
+		assert.Equal(t, "Mailgun", credential["name"])
+	})
+
+	t.Run("Reports missing and ambiguous credentials", func(t *testing.T) {
+		workflow := n8n.Workflow{Name: "Local", Nodes: []n8n.Node{
+			credentialNode("Postgres", "postgres", "Analytics DB", "dev-pg-id"),
+			credentialNode("Slack", "slackApi", "Team Slack", "dev-slack-id"),
+		}}
+
+		unresolved := resolver.Resolve(&workflow)
+		require.Len(t, unresolved, 2)
+
+		err := workflows.FormatUnresolvedCredentials(unresolved)
+		assert.Contains(t, err.Error(), "node 'Postgres': postgres 'Analytics DB' (ID: dev-pg-id) not found on the target instance")
+		assert.Contains(t, err.Error(), "node 'Slack': slackApi 'Team Slack' (ID: dev-slack-id) ambiguous")
+	})
+
+	t.Run("Mapping file takes precedence", func(t *testing.T) {
+		mapFile := filepath.Join(t.TempDir(), "credentials.yaml")
+		content := `credentials:
+  - type: slackApi
+    name: Team Slack
+    id: prod-slack-2
+`
+		require.NoError(t, os.WriteFile(mapFile, []byte(content), 0644))
+		require.NoError(t, resolver.LoadMappingFile(mapFile))
+
+		workflow := n8n.Workflow{Name: "Local", Nodes: []n8n.Node{credentialNode("Slack", "slackApi", "Team Slack", "dev-slack-id")}}
+
+		unresolved := resolver.Resolve(&workflow)
+		assert.Empty(t, unresolved)
+
+		credential := (*workflow.Nodes[0].Credentials)["slackApi"].(map[string]interface{})
+		assert.Equal(t, "prod-slack-2", credential["id"])
+	})
+}
+
+func TestCredentialResolver_ResolvesLoadedWorkflowFiles(t *testing.T) {
+	dir := t.TempDir()
+	workflow := `{"name": "Contact Form", "nodes": [{"name": "Email", "credentials": {"smtp": {"id": "dev-id", "name": "Mailgun"}}}], "connections": {}}`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Contact_Form.json"), []byte(workflow), 0644))
+
+	cmd := &cobra.Command{}
+	cmd.Flags().Bool("resolve-credentials", false, "")
+	cmd.Flags().String("credentials-map", "", "")
+	require.NoError(t, cmd.Flags().Set("resolve-credentials", "true"))
+
+	localWorkflows, err := workflows.LoadWorkflowFiles(cmd, dir)
+	require.NoError(t, err)
+
+	fakeClient := &clientfakes.FakeClientInterface{}
+	fakeClient.GetAllWorkflowsReturns(&n8n.WorkflowList{Data: &[]n8n.Workflow{}}, nil)
+
+	resolver := workflows.NewCredentialResolver()
+	require.NoError(t, resolver.LoadFromInstance(fakeClient))
+
+	unresolved := resolver.Resolve(&localWorkflows[0].Workflow)
+	require.Len(t, unresolved, 1)
+	assert.Equal(t, "Contact Form", unresolved[0].Workflow)
+	assert.Equal(t, "Mailgun", unresolved[0].Name)
+	assert.Equal(t, 0, fakeClient.CreateWorkflowCallCount())
+}
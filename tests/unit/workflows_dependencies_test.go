@@ -0,0 +1,142 @@
+package unit
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/edenreich/n8n-cli/cmd/workflows"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/edenreich/n8n-cli/n8n/clientfakes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func executeWorkflowNode(name string, workflowID interface{}) n8n.Node {
+	return n8n.Node{
+		Name:       stringPtr(name),
+		Type:       stringPtr("n8n-nodes-base.executeWorkflow"),
+		Parameters: &map[string]interface{}{"workflowId": workflowID},
+	}
+}
+
+func localWorkflow(id string, name string, nodes ...n8n.Node) workflows.LocalWorkflow {
+	return workflows.LocalWorkflow{
+		FilePath: name + ".json",
+		Workflow: n8n.Workflow{Id: stringPtr(id), Name: name, Nodes: nodes},
+	}
+}
+
+func workflowNames(localWorkflows []workflows.LocalWorkflow) []string {
+	names := make([]string, len(localWorkflows))
+	for i, local := range localWorkflows {
+		names[i] = local.Workflow.Name
+	}
+	return names
+}
+
+func TestWorkflowReferences(t *testing.T) {
+	workflow := n8n.Workflow{
+		Settings: n8n.WorkflowSettings{ErrorWorkflow: stringPtr("error-handler")},
+		Nodes: []n8n.Node{
+			executeWorkflowNode("Call Plain", "plain-id"),
+			executeWorkflowNode("Call Locator", map[string]interface{}{"__rl": true, "value": "locator-id", "mode": "list"}),
+			executeWorkflowNode("Call Expression", "={{ $json.workflowId }}"),
+			{Name: stringPtr("Webhook"), Type: stringPtr("n8n-nodes-base.webhook")},
+		},
+	}
+
+	references := workflows.WorkflowReferences(&workflow)
+
+	assert.Equal(t, []workflows.WorkflowReference{
+		{WorkflowID: "error-handler"},
+		{Node: "Call Plain", WorkflowID: "plain-id"},
+		{Node: "Call Locator", WorkflowID: "locator-id"},
+	}, references)
+}
+
+func TestRewriteWorkflowReferences(t *testing.T) {
+	workflow := n8n.Workflow{
+		Settings: n8n.WorkflowSettings{ErrorWorkflow: stringPtr("old-error")},
+		Nodes: []n8n.Node{
+			executeWorkflowNode("Call Plain", "old-plain"),
+			executeWorkflowNode("Call Locator", map[string]interface{}{"__rl": true, "value": "old-locator", "mode": "list"}),
+			executeWorkflowNode("Call Other", "untouched"),
+		},
+	}
+
+	workflows.RewriteWorkflowReferences(&workflow, map[string]string{
+		"old-error":   "new-error",
+		"old-plain":   "new-plain",
+		"old-locator": "new-locator",
+	})
+
+	assert.Equal(t, "new-error", *workflow.Settings.ErrorWorkflow)
+	assert.Equal(t, "new-plain", (*workflow.Nodes[0].Parameters)["workflowId"])
+	locator := (*workflow.Nodes[1].Parameters)["workflowId"].(map[string]interface{})
+	assert.Equal(t, "new-locator", locator["value"])
+	assert.Equal(t, "list", locator["mode"])
+	assert.Equal(t, "untouched", (*workflow.Nodes[2].Parameters)["workflowId"])
+}
+
+func TestSortWorkflowsByDependencies(t *testing.T) {
+	t.Run("Orders referenced workflows first", func(t *testing.T) {
+		parent := localWorkflow("parent", "Parent", executeWorkflowNode("Call Child", "child"))
+		child := localWorkflow("child", "Child")
+		child.Workflow.Settings.ErrorWorkflow = stringPtr("errors")
+		errorHandler := localWorkflow("errors", "Error Handler")
+
+		fakeClient := &clientfakes.FakeClientInterface{}
+
+		sorted, err := workflows.SortWorkflowsByDependencies(fakeClient, []workflows.LocalWorkflow{parent, child, errorHandler})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"Error Handler", "Child", "Parent"}, workflowNames(sorted))
+		assert.Equal(t, 0, fakeClient.GetWorkflowCallCount(), "Local references should not be looked up remotely")
+	})
+
+	t.Run("Accepts references to workflows on the instance", func(t *testing.T) {
+		parent := localWorkflow("parent", "Parent", executeWorkflowNode("Call Remote", "remote"))
+
+		fakeClient := &clientfakes.FakeClientInterface{}
+		fakeClient.GetWorkflowReturns(&n8n.Workflow{Id: stringPtr("remote")}, nil)
+
+		sorted, err := workflows.SortWorkflowsByDependencies(fakeClient, []workflows.LocalWorkflow{parent})
+		require.NoError(t, err)
+		assert.Len(t, sorted, 1)
+		assert.Equal(t, "remote", fakeClient.GetWorkflowArgsForCall(0))
+	})
+
+	t.Run("Reports missing workflows", func(t *testing.T) {
+		parent := localWorkflow("parent", "Parent", executeWorkflowNode("Call Missing", "missing"))
+
+		fakeClient := &clientfakes.FakeClientInterface{}
+		fakeClient.GetWorkflowReturns(nil, fmt.Errorf("%w: API returned error 404: not found", n8n.ErrWorkflowNotFound))
+
+		_, err := workflows.SortWorkflowsByDependencies(fakeClient, []workflows.LocalWorkflow{parent})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "workflow 'Parent' references missing workflow missing in its node 'Call Missing'")
+	})
+
+	t.Run("Returns errors other than missing workflows", func(t *testing.T) {
+		parent := localWorkflow("parent", "Parent", executeWorkflowNode("Call Remote", "remote"))
+
+		fakeClient := &clientfakes.FakeClientInterface{}
+		fakeClient.GetWorkflowReturns(nil, errors.New("API returned error 503: unavailable"))
+
+		_, err := workflows.SortWorkflowsByDependencies(fakeClient, []workflows.LocalWorkflow{parent})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "API returned error 503")
+		assert.NotContains(t, err.Error(), "unresolved workflow references")
+	})
+
+	t.Run("Reports cycles", func(t *testing.T) {
+		first := localWorkflow("first", "First", executeWorkflowNode("Call Second", "second"))
+		second := localWorkflow("second", "Second", executeWorkflowNode("Call First", "first"))
+		independent := localWorkflow("independent", "Independent")
+
+		_, err := workflows.SortWorkflowsByDependencies(&clientfakes.FakeClientInterface{}, []workflows.LocalWorkflow{first, second, independent})
+		require.Error(t, err)
+		assert.Equal(t, "circular workflow references involving 'First', 'Second'", err.Error())
+	})
+}
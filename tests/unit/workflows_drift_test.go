@@ -0,0 +1,135 @@
+package unit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	rootcmd "github.com/edenreich/n8n-cli/cmd"
+	"github.com/edenreich/n8n-cli/cmd/workflows"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/edenreich/n8n-cli/n8n/clientfakes"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDriftTestCmd(t *testing.T, dir string) (*cobra.Command, *bytes.Buffer) {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("directory", "", "")
+	cmd.Flags().Bool("include-unmanaged", false, "")
+	require.NoError(t, cmd.Flags().Set("directory", dir))
+
+	outBuf := new(bytes.Buffer)
+	cmd.SetOut(outBuf)
+	return cmd, outBuf
+}
+
+func writeDriftTestWorkflow(t *testing.T, dir string, filename string, workflow n8n.Workflow) {
+	content, err := n8n.NewWorkflowEncoder(true).EncodeToJSON(workflow)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, filename), content, 0644))
+}
+
+func TestDriftWorkflows(t *testing.T) {
+	managed := &[]n8n.Tag{{Id: stringPtr("tag-managed"), Name: "n8n-cli"}}
+
+	inSync := mergeTestWorkflow(mergeTestNode("Webhook", map[string]interface{}{"path": "orders"}))
+	inSync.Id = stringPtr("1")
+	inSync.Name = "Orders"
+
+	t.Run("Reports changed, remote only and local only workflows", func(t *testing.T) {
+		dir := t.TempDir()
+
+		changedLocal := mergeTestWorkflow(mergeTestNode("Webhook", map[string]interface{}{"path": "invoices"}))
+		changedLocal.Id = stringPtr("2")
+		changedLocal.Name = "Invoices"
+
+		changedRemote := mergeTestWorkflow(mergeTestNode("Webhook", map[string]interface{}{"path": "invoices-edited"}))
+		changedRemote.Id = stringPtr("2")
+		changedRemote.Name = "Invoices"
+
+		writeDriftTestWorkflow(t, dir, "Orders.json", inSync)
+		writeDriftTestWorkflow(t, dir, "Invoices.json", changedLocal)
+		writeDriftTestWorkflow(t, dir, "New.json", n8n.Workflow{Name: "New"})
+
+		remoteOnly := n8n.Workflow{Id: stringPtr("3"), Name: "Deployed Elsewhere", Tags: managed}
+		unmanaged := n8n.Workflow{Id: stringPtr("4"), Name: "Built In The UI"}
+
+		fakeClient := &clientfakes.FakeClientInterface{}
+		fakeClient.GetAllWorkflowsReturns(&n8n.WorkflowList{Data: &[]n8n.Workflow{inSync, changedRemote, remoteOnly, unmanaged}}, nil)
+
+		cmd, outBuf := newDriftTestCmd(t, dir)
+
+		err := workflows.DriftWorkflowsWithClient(cmd, fakeClient)
+		require.Error(t, err)
+
+		var exitErr *rootcmd.ExitError
+		require.True(t, errors.As(err, &exitErr))
+		assert.Equal(t, workflows.DriftExitCode, exitErr.Code)
+
+		output := outBuf.String()
+		assert.Contains(t, output, "Changed: 'Invoices' (ID: 2) from Invoices.json: content differs")
+		assert.Contains(t, output, "Only in n8n: 'Deployed Elsewhere' (ID: 3)")
+		assert.Contains(t, output, "Only local: 'New' from New.json")
+		assert.NotContains(t, output, "Built In The UI", "Unmanaged workflows should not be reported")
+		assert.Contains(t, output, "Drift found in 3 workflow(s), 1 in sync")
+
+		assert.Equal(t, 0, fakeClient.UpdateWorkflowCallCount())
+		assert.Equal(t, 0, fakeClient.CreateWorkflowCallCount())
+		assert.Equal(t, 0, fakeClient.DeleteWorkflowCallCount())
+	})
+
+	t.Run("Succeeds when the instance is in sync", func(t *testing.T) {
+		dir := t.TempDir()
+		writeDriftTestWorkflow(t, dir, "Orders.json", inSync)
+
+		fakeClient := &clientfakes.FakeClientInterface{}
+		fakeClient.GetAllWorkflowsReturns(&n8n.WorkflowList{Data: &[]n8n.Workflow{inSync}}, nil)
+
+		cmd, outBuf := newDriftTestCmd(t, dir)
+
+		require.NoError(t, workflows.DriftWorkflowsWithClient(cmd, fakeClient))
+		assert.Contains(t, outBuf.String(), "No drift found, 1 workflow(s) in sync")
+	})
+
+	t.Run("Reports workflows beyond the first page of the list", func(t *testing.T) {
+		dir := t.TempDir()
+		writeDriftTestWorkflow(t, dir, "Orders.json", inSync)
+
+		remote := []n8n.Workflow{inSync}
+		for i := 0; i < n8n.MaxLimit; i++ {
+			remote = append(remote, n8n.Workflow{Id: stringPtr(fmt.Sprintf("other-%d", i)), Name: fmt.Sprintf("Other %d", i)})
+		}
+		remote = append(remote, n8n.Workflow{Id: stringPtr("last"), Name: "Deployed Elsewhere", Tags: managed})
+
+		fakeClient := &clientfakes.FakeClientInterface{}
+		fakeClient.GetAllWorkflowsReturns(&n8n.WorkflowList{Data: &remote}, nil)
+
+		cmd, outBuf := newDriftTestCmd(t, dir)
+
+		require.Error(t, workflows.DriftWorkflowsWithClient(cmd, fakeClient))
+		assert.Contains(t, outBuf.String(), "Only in n8n: 'Deployed Elsewhere' (ID: last)")
+		assert.Contains(t, outBuf.String(), "Drift found in 1 workflow(s), 1 in sync")
+		assert.Equal(t, 0, fakeClient.GetWorkflowsCallCount())
+	})
+
+	t.Run("Fails with a regular error when the instance can't be reached", func(t *testing.T) {
+		dir := t.TempDir()
+		writeDriftTestWorkflow(t, dir, "Orders.json", inSync)
+
+		fakeClient := &clientfakes.FakeClientInterface{}
+		fakeClient.GetAllWorkflowsReturns(nil, errors.New("connection refused"))
+
+		cmd, _ := newDriftTestCmd(t, dir)
+
+		err := workflows.DriftWorkflowsWithClient(cmd, fakeClient)
+		require.Error(t, err)
+
+		var exitErr *rootcmd.ExitError
+		assert.False(t, errors.As(err, &exitErr))
+	})
+}
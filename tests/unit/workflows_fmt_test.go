@@ -0,0 +1,132 @@
+package unit
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	rootcmd "github.com/edenreich/n8n-cli/cmd"
+	"github.com/edenreich/n8n-cli/cmd/workflows"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalWorkflow(t *testing.T) {
+	workflow := n8n.Workflow{
+		Name: "Contact Form",
+		Nodes: []n8n.Node{
+			{Name: stringPtr("Webhook"), Parameters: &map[string]interface{}{
+				"path":    "contact",
+				"timeout": 1.100000023841858,
+				"ratio":   0.1,
+				"options": map[string]interface{}{"weights": []interface{}{2.299999952316284, float64(3)}},
+			}},
+			{Name: stringPtr("Format Reply"), CreatedAt: timePtr("2024-01-01T00:00:00Z")},
+		},
+		UpdatedAt: timePtr("2024-01-02T00:00:00Z"),
+	}
+
+	canonical := n8n.CanonicalWorkflow(workflow)
+
+	require.Len(t, canonical.Nodes, 2)
+	assert.Equal(t, "Format Reply", *canonical.Nodes[0].Name, "Nodes should be sorted by name")
+	assert.Nil(t, canonical.Nodes[0].CreatedAt)
+	assert.Nil(t, canonical.UpdatedAt)
+
+	parameters := *canonical.Nodes[1].Parameters
+	assert.Equal(t, 1.1, parameters["timeout"], "Float32 artifacts should be normalized")
+	assert.Equal(t, 0.1, parameters["ratio"], "Other numbers should be kept")
+	assert.Equal(t, []interface{}{2.3, float64(3)}, parameters["options"].(map[string]interface{})["weights"])
+
+	assert.Equal(t, "Webhook", *workflow.Nodes[0].Name, "The original workflow should not be modified")
+	assert.Equal(t, 1.100000023841858, (*workflow.Nodes[0].Parameters)["timeout"])
+
+	t.Run("Numbers", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			value    float64
+			expected float64
+		}{
+			{name: "Float32 artifact", value: 1.100000023841858, expected: 1.1},
+			{name: "Negative float32 artifact", value: -2.299999952316284, expected: -2.3},
+			{name: "Float64 value", value: 0.1, expected: 0.1},
+			{name: "Small integer", value: 3, expected: 3},
+			{name: "2^30", value: 1073741824, expected: 1073741824},
+			{name: "2^31", value: 2147483648, expected: 2147483648},
+			{name: "2^32", value: 4294967296, expected: 4294967296},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				canonical := n8n.CanonicalWorkflow(n8n.Workflow{Nodes: []n8n.Node{
+					{Name: stringPtr("Wait"), Parameters: &map[string]interface{}{"amount": tt.value}},
+				}})
+				assert.Equal(t, tt.expected, (*canonical.Nodes[0].Parameters)["amount"])
+			})
+		}
+	})
+
+	t.Run("Node order is not drift", func(t *testing.T) {
+		assert.False(t, rootcmd.DetectWorkflowDrift(workflow, canonical, true))
+	})
+}
+
+func TestFormatWorkflowFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	unformatted := []byte(`---
+name: Contact Form
+nodes:
+  - name: Webhook
+    type: n8n-nodes-base.webhook
+    typeVersion: 1
+  - name: Format Reply
+    type: n8n-nodes-base.code
+    parameters:
+      jsCode:
+        $file: Contact_Form/Format_Reply.js
+connections: {}
+`)
+	filePath := filepath.Join(dir, "Contact_Form.yaml")
+	require.NoError(t, os.WriteFile(filePath, unformatted, 0644))
+	writeDriftTestWorkflow(t, dir, "Orders.json", n8n.Workflow{Id: stringPtr("1"), Name: "Orders", Nodes: []n8n.Node{}, Connections: map[string]interface{}{}})
+
+	t.Run("Check reports files that are not formatted", func(t *testing.T) {
+		cmd := newSelectTestCmd(t)
+		outBuf := new(bytes.Buffer)
+		cmd.SetOut(outBuf)
+
+		files, err := workflows.FormatWorkflowFiles(cmd, dir, true, true)
+		require.NoError(t, err)
+		assert.Equal(t, []string{filePath}, files)
+		assert.Contains(t, outBuf.String(), "Not formatted: "+filePath)
+
+		content, err := os.ReadFile(filePath)
+		require.NoError(t, err)
+		assert.Equal(t, unformatted, content, "Check should not write anything")
+	})
+
+	t.Run("Formats the files", func(t *testing.T) {
+		files, err := workflows.FormatWorkflowFiles(newSelectTestCmd(t), dir, false, true)
+		require.NoError(t, err)
+		assert.Equal(t, []string{filePath}, files)
+
+		workflow, err := n8n.NewWorkflowDecoder().DecodeFromYAML(mustReadFile(t, filePath))
+		require.NoError(t, err)
+		assert.Equal(t, "Format Reply", *workflow.Nodes[0].Name)
+		assert.Equal(t, map[string]interface{}{n8n.CodeFileKey: "Contact_Form/Format_Reply.js"}, (*workflow.Nodes[0].Parameters)["jsCode"], "Code file references should be kept")
+
+		files, err = workflows.FormatWorkflowFiles(newSelectTestCmd(t), dir, true, true)
+		require.NoError(t, err)
+		assert.Empty(t, files, "Formatted files should pass the check")
+	})
+}
+
+func mustReadFile(t *testing.T, filePath string) []byte {
+	t.Helper()
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	return content
+}
@@ -0,0 +1,172 @@
+package unit
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/edenreich/n8n-cli/cmd/workflows"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const uiExportWorkflow = `{
+  "name": "Contact Form",
+  "nodes": [
+    {
+      "parameters": {"path": "contact"},
+      "id": "5f1c2a3b",
+      "name": "Webhook",
+      "type": "n8n-nodes-base.webhook",
+      "typeVersion": 2,
+      "position": [0, 0]
+    }
+  ],
+  "pinData": {"Webhook": [{"json": {"email": "jane@example.com"}}]},
+  "connections": {},
+  "active": false,
+  "settings": {"executionOrder": "v1"},
+  "versionId": "0d2f7e3c-4b1a-4e8f-9c3d-2a1b0c9d8e7f",
+  "meta": {"templateCredsSetupCompleted": true, "instanceId": "abc123"},
+  "id": "AbCdEf123456",
+  "tags": [{"createdAt": "2024-01-01T00:00:00.000Z", "updatedAt": "2024-01-01T00:00:00.000Z", "id": "7", "name": "forms"}]
+}`
+
+const cliExportWorkflows = `[
+  {"id": 1, "name": "Orders Sync", "nodes": [], "connections": {}, "active": true, "settings": {}, "tags": [{"id": 2, "name": "billing"}]},
+  {"id": 2, "name": "Invoices", "nodes": [], "connections": {}, "active": false, "settings": {}, "versionId": "v1"}
+]`
+
+func TestDecodeExport(t *testing.T) {
+	decoder := n8n.NewWorkflowDecoder()
+
+	t.Run("Editor download", func(t *testing.T) {
+		exported, err := decoder.DecodeExport([]byte(uiExportWorkflow))
+		require.NoError(t, err)
+		require.Len(t, exported, 1)
+		assert.Equal(t, "AbCdEf123456", *exported[0].Id)
+		assert.Equal(t, "Contact Form", exported[0].Name)
+		require.NotNil(t, exported[0].Tags)
+		assert.Equal(t, "forms", (*exported[0].Tags)[0].Name)
+	})
+
+	t.Run("CLI export with numeric IDs", func(t *testing.T) {
+		exported, err := decoder.DecodeExport([]byte(cliExportWorkflows))
+		require.NoError(t, err)
+		require.Len(t, exported, 2)
+		assert.Equal(t, "1", *exported[0].Id)
+		assert.Equal(t, "2", *(*exported[0].Tags)[0].Id)
+		assert.Equal(t, "Invoices", exported[1].Name)
+	})
+
+	t.Run("Invalid JSON", func(t *testing.T) {
+		_, err := decoder.DecodeExport([]byte(`[{"name": `))
+		assert.Error(t, err)
+	})
+}
+
+func TestDecodeFromJSON_Array(t *testing.T) {
+	decoder := n8n.NewWorkflowDecoder()
+
+	workflow, err := decoder.DecodeFromJSON([]byte(`[{"id": 3, "name": "Single", "nodes": [], "connections": {}}]`))
+	require.NoError(t, err)
+	assert.Equal(t, "3", *workflow.Id)
+	assert.Equal(t, "Single", workflow.Name)
+
+	_, err = decoder.DecodeFromJSON([]byte(cliExportWorkflows))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "n8n workflows import")
+}
+
+func TestImportWorkflows(t *testing.T) {
+	t.Run("Editor download", func(t *testing.T) {
+		source := filepath.Join(t.TempDir(), "Contact Form.json")
+		require.NoError(t, os.WriteFile(source, []byte(uiExportWorkflow), 0644))
+		dir := t.TempDir()
+
+		cmd := newSelectTestCmd(t)
+		imported, err := workflows.ImportWorkflows(cmd, []string{source}, dir, "yaml", false, true)
+		require.NoError(t, err)
+		assert.Equal(t, 1, imported)
+
+		content := string(mustReadFile(t, filepath.Join(dir, "Contact_Form.yaml")))
+		assert.Contains(t, content, "id: AbCdEf123456")
+		assert.Contains(t, content, "pinData:", "Fields outside the API schema should be kept")
+		assert.Contains(t, content, "instanceId: abc123")
+		assert.NotContains(t, content, "versionId", "Read-only fields should be dropped")
+	})
+
+	t.Run("CLI export array", func(t *testing.T) {
+		source := filepath.Join(t.TempDir(), "workflows.json")
+		require.NoError(t, os.WriteFile(source, []byte(cliExportWorkflows), 0644))
+		dir := t.TempDir()
+
+		cmd := newSelectTestCmd(t)
+		imported, err := workflows.ImportWorkflows(cmd, []string{source}, dir, "", false, true)
+		require.NoError(t, err)
+		assert.Equal(t, 2, imported)
+
+		id, err := workflows.ExtractWorkflowIDFromFile(filepath.Join(dir, "Orders_Sync.json"))
+		require.NoError(t, err)
+		assert.Equal(t, "1", id)
+		assert.FileExists(t, filepath.Join(dir, "Invoices.json"))
+	})
+
+	t.Run("Backup directory", func(t *testing.T) {
+		backup := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(backup, "1.json"), []byte(`{"id": 1, "name": "Orders Sync", "nodes": [], "connections": {}}`), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(backup, "2.json"), []byte(`{"id": 2, "name": "Invoices", "nodes": [], "connections": {}}`), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(backup, "README.md"), []byte("not a workflow"), 0644))
+		dir := t.TempDir()
+
+		cmd := newSelectTestCmd(t)
+		imported, err := workflows.ImportWorkflows(cmd, []string{backup}, dir, "", false, true)
+		require.NoError(t, err)
+		assert.Equal(t, 2, imported)
+		assert.FileExists(t, filepath.Join(dir, "Orders_Sync.json"))
+		assert.FileExists(t, filepath.Join(dir, "Invoices.json"))
+	})
+
+	t.Run("Existing file is updated", func(t *testing.T) {
+		dir := t.TempDir()
+		writeDriftTestWorkflow(t, dir, "orders.yaml", n8n.Workflow{Id: stringPtr("1"), Name: "Orders Sync"})
+
+		source := filepath.Join(t.TempDir(), "workflows.json")
+		require.NoError(t, os.WriteFile(source, []byte(cliExportWorkflows), 0644))
+
+		cmd := newSelectTestCmd(t)
+		_, err := workflows.ImportWorkflows(cmd, []string{source}, dir, "", false, true)
+		require.NoError(t, err)
+		assert.NoFileExists(t, filepath.Join(dir, "Orders_Sync.json"))
+		assert.Contains(t, string(mustReadFile(t, filepath.Join(dir, "orders.yaml"))), "active: true")
+	})
+
+	t.Run("Name clash with another workflow", func(t *testing.T) {
+		dir := t.TempDir()
+		writeDriftTestWorkflow(t, dir, "Invoices.json", n8n.Workflow{Id: stringPtr("99"), Name: "Invoices"})
+
+		source := filepath.Join(t.TempDir(), "workflows.json")
+		require.NoError(t, os.WriteFile(source, []byte(cliExportWorkflows), 0644))
+
+		cmd := newSelectTestCmd(t)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		imported, err := workflows.ImportWorkflows(cmd, []string{source}, dir, "", false, true)
+		require.NoError(t, err)
+		assert.Equal(t, 1, imported)
+		assert.Contains(t, out.String(), "already holds workflow 99")
+	})
+
+	t.Run("Dry run", func(t *testing.T) {
+		source := filepath.Join(t.TempDir(), "workflows.json")
+		require.NoError(t, os.WriteFile(source, []byte(cliExportWorkflows), 0644))
+		dir := filepath.Join(t.TempDir(), "workflows")
+
+		cmd := newSelectTestCmd(t)
+		_, err := workflows.ImportWorkflows(cmd, []string{source}, dir, "", true, true)
+		require.NoError(t, err)
+		assert.NoDirExists(t, dir)
+	})
+}
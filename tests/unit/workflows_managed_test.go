@@ -0,0 +1,127 @@
+package unit
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/edenreich/n8n-cli/cmd/workflows"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/edenreich/n8n-cli/n8n/clientfakes"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func updatedTagIDs(fakeClient *clientfakes.FakeClientInterface) map[string][]string {
+	updates := make(map[string][]string)
+	for i := 0; i < fakeClient.UpdateWorkflowTagsCallCount(); i++ {
+		id, tagIDs := fakeClient.UpdateWorkflowTagsArgsForCall(i)
+		var ids []string
+		for _, tag := range tagIDs {
+			ids = append(ids, tag.Id)
+		}
+		updates[id] = ids
+	}
+	return updates
+}
+
+func TestSyncWorkflows_MarksWorkflowsAsManaged(t *testing.T) {
+	dir := t.TempDir()
+
+	encoder := n8n.NewWorkflowEncoder(true)
+	tagged, err := encoder.EncodeToJSON(n8n.Workflow{Name: "Tagged", Tags: &[]n8n.Tag{{Name: "ops"}}})
+	require.NoError(t, err)
+	untagged, err := encoder.EncodeToJSON(n8n.Workflow{Name: "Untagged"})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Tagged.json"), tagged, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Untagged.json"), untagged, 0644))
+
+	tagIDs := map[string]string{"ops": "tag-ops", "n8n-cli": "tag-managed"}
+
+	fakeClient := &clientfakes.FakeClientInterface{}
+	fakeClient.GetWorkflowReturns(nil, errors.New("not found"))
+	fakeClient.CreateWorkflowStub = func(workflow *n8n.Workflow) (*n8n.Workflow, error) {
+		created := *workflow
+		created.Id = stringPtr("id-" + workflow.Name)
+		return &created, nil
+	}
+	fakeClient.GetTagsReturns(&n8n.TagList{Data: &[]n8n.Tag{}}, nil)
+	fakeClient.CreateTagStub = func(name string) (*n8n.Tag, error) {
+		return &n8n.Tag{Id: stringPtr(tagIDs[name]), Name: name}, nil
+	}
+	fakeClient.GetWorkflowTagsReturns(n8n.WorkflowTags{{Id: stringPtr("tag-ui"), Name: "added-in-ui"}}, nil)
+
+	cmd := &cobra.Command{}
+	cmd.Flags().Bool("mark-managed", true, "")
+	cmd.SetOut(new(bytes.Buffer))
+
+	_, err = workflows.SyncWorkflowsWithClient(cmd, fakeClient, dir, false, false)
+	require.NoError(t, err)
+
+	updates := updatedTagIDs(fakeClient)
+	assert.ElementsMatch(t, []string{"tag-ops", "tag-managed"}, updates["id-Tagged"], "The managed tag should be added to declared tags")
+	assert.Equal(t, []string{"tag-managed", "tag-ui"}, updates["id-Untagged"], "Tags added in n8n should be kept")
+}
+
+func TestAdoptWorkflow(t *testing.T) {
+	newAdoptTestCmd := func(t *testing.T, dir string) (*cobra.Command, *bytes.Buffer) {
+		cmd := &cobra.Command{}
+		cmd.Flags().String("directory", "", "")
+		cmd.Flags().String("output", "", "")
+		cmd.Flags().Bool("dry-run", false, "")
+		require.NoError(t, cmd.Flags().Set("directory", dir))
+		require.NoError(t, cmd.Flags().Set("output", "yaml"))
+
+		outBuf := new(bytes.Buffer)
+		cmd.SetOut(outBuf)
+		return cmd, outBuf
+	}
+
+	t.Run("Tags the workflow and writes it to the directory", func(t *testing.T) {
+		dir := t.TempDir()
+
+		unmanaged := n8n.Workflow{Id: stringPtr("42"), Name: "Built In The UI"}
+		managed := unmanaged
+		managed.Tags = &[]n8n.Tag{{Id: stringPtr("tag-managed"), Name: "n8n-cli"}}
+
+		fakeClient := &clientfakes.FakeClientInterface{}
+		fakeClient.GetWorkflowReturnsOnCall(0, &unmanaged, nil)
+		fakeClient.GetWorkflowReturnsOnCall(1, &managed, nil)
+		fakeClient.GetWorkflowTagsReturns(n8n.WorkflowTags{}, nil)
+		fakeClient.GetTagsReturns(&n8n.TagList{Data: &[]n8n.Tag{{Id: stringPtr("tag-managed"), Name: "n8n-cli"}}}, nil)
+
+		cmd, outBuf := newAdoptTestCmd(t, dir)
+
+		require.NoError(t, workflows.AdoptWorkflowWithClient(cmd, fakeClient, "42"))
+		assert.Equal(t, map[string][]string{"42": {"tag-managed"}}, updatedTagIDs(fakeClient))
+		assert.Equal(t, 0, fakeClient.CreateTagCallCount(), "The existing managed tag should be reused")
+		assert.Contains(t, outBuf.String(), "Marked workflow 'Built In The UI' (ID: 42) as managed")
+
+		content, err := os.ReadFile(filepath.Join(dir, "Built_In_The_UI.yaml"))
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "n8n-cli")
+
+		_, err = os.Stat(filepath.Join(dir, ".n8n", "state.json"))
+		assert.NoError(t, err, "The adopted version should be recorded")
+	})
+
+	t.Run("Uses the configured managed tag", func(t *testing.T) {
+		viper.Set("managed_tag", "gitops")
+		t.Cleanup(func() { viper.Set("managed_tag", "") })
+
+		workflow := n8n.Workflow{Id: stringPtr("42"), Name: "Already Managed", Tags: &[]n8n.Tag{{Id: stringPtr("tag-gitops"), Name: "gitops"}}}
+
+		fakeClient := &clientfakes.FakeClientInterface{}
+		fakeClient.GetWorkflowReturns(&workflow, nil)
+
+		cmd, outBuf := newAdoptTestCmd(t, t.TempDir())
+
+		require.NoError(t, workflows.AdoptWorkflowWithClient(cmd, fakeClient, "42"))
+		assert.Equal(t, 0, fakeClient.UpdateWorkflowTagsCallCount())
+		assert.Contains(t, outBuf.String(), "is already managed by the CLI")
+	})
+}
@@ -0,0 +1,230 @@
+package unit
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/edenreich/n8n-cli/cmd/workflows"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/edenreich/n8n-cli/n8n/clientfakes"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncWorkflows_MatchBy(t *testing.T) {
+	remoteWorkflows := []n8n.Workflow{
+		{Id: stringPtr("10"), Name: "Orders", Nodes: []n8n.Node{}, Connections: map[string]interface{}{}},
+		{Id: stringPtr("20"), Name: "Invoices", Nodes: []n8n.Node{}, Connections: map[string]interface{}{}},
+		{Id: stringPtr("21"), Name: "Invoices", Nodes: []n8n.Node{}, Connections: map[string]interface{}{}},
+	}
+
+	newMatchTestClient := func() *clientfakes.FakeClientInterface {
+		fakeClient := newWatchTestClient()
+		fakeClient.GetAllWorkflowsReturns(&n8n.WorkflowList{Data: &remoteWorkflows}, nil)
+		fakeClient.GetWorkflowStub = func(id string) (*n8n.Workflow, error) {
+			for _, remote := range remoteWorkflows {
+				if *remote.Id == id {
+					return &remote, nil
+				}
+			}
+			return nil, assert.AnError
+		}
+		return fakeClient
+	}
+
+	newMatchTestCmd := func(t *testing.T, matchBy string) (*cobra.Command, *bytes.Buffer) {
+		cmd := &cobra.Command{}
+		cmd.Flags().String("match-by", "", "")
+		require.NoError(t, cmd.Flags().Set("match-by", matchBy))
+		outBuf := new(bytes.Buffer)
+		cmd.SetOut(outBuf)
+		return cmd, outBuf
+	}
+
+	t.Run("Updates the workflow with the same name instead of creating a duplicate", func(t *testing.T) {
+		dir := t.TempDir()
+		writeDriftTestWorkflow(t, dir, "Orders.json", n8n.Workflow{Name: "Orders", Active: boolPtr(false)})
+		writeDriftTestWorkflow(t, dir, "Refunds.json", n8n.Workflow{Name: "Refunds"})
+
+		fakeClient := newMatchTestClient()
+		cmd, outBuf := newMatchTestCmd(t, workflows.MatchByName)
+
+		_, err := workflows.SyncWorkflowsWithClient(cmd, fakeClient, dir, false, false)
+		require.NoError(t, err)
+
+		assert.Contains(t, outBuf.String(), "Matched Orders.json to workflow 'Orders' (ID: 10) by name")
+		require.Equal(t, 1, fakeClient.CreateWorkflowCallCount(), "Only the workflow without a match should be created")
+		assert.Equal(t, "Refunds", fakeClient.CreateWorkflowArgsForCall(0).Name)
+	})
+
+	t.Run("Matches by file name", func(t *testing.T) {
+		dir := t.TempDir()
+		writeDriftTestWorkflow(t, dir, "Orders.json", n8n.Workflow{Id: stringPtr("from-staging"), Name: "Orders (renamed)"})
+
+		fakeClient := newMatchTestClient()
+		cmd, outBuf := newMatchTestCmd(t, workflows.MatchByKey)
+
+		_, err := workflows.SyncWorkflowsWithClient(cmd, fakeClient, dir, false, false)
+		require.NoError(t, err)
+
+		assert.Contains(t, outBuf.String(), "Matched Orders.json to workflow 'Orders' (ID: 10) by key")
+		assert.Equal(t, 0, fakeClient.CreateWorkflowCallCount())
+		require.Equal(t, 1, fakeClient.UpdateWorkflowCallCount())
+		id, _ := fakeClient.UpdateWorkflowArgsForCall(0)
+		assert.Equal(t, "10", id)
+	})
+
+	t.Run("Fails before uploading anything when a match is ambiguous", func(t *testing.T) {
+		dir := t.TempDir()
+		writeDriftTestWorkflow(t, dir, "Invoices.json", n8n.Workflow{Name: "Invoices"})
+		writeDriftTestWorkflow(t, dir, "Orders.json", n8n.Workflow{Id: stringPtr("10"), Name: "Orders"})
+		writeDriftTestWorkflow(t, dir, "Orders_Copy.json", n8n.Workflow{Name: "Orders"})
+
+		fakeClient := newMatchTestClient()
+		cmd, _ := newMatchTestCmd(t, workflows.MatchByName)
+
+		_, err := workflows.SyncWorkflowsWithClient(cmd, fakeClient, dir, false, false)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Invoices.json: 2 workflows in n8n match by name 'Invoices' (IDs: 20, 21)")
+		assert.Contains(t, err.Error(), "Orders.json and Orders_Copy.json both match workflow 'Orders' (ID: 10)")
+		assert.Equal(t, 0, fakeClient.CreateWorkflowCallCount())
+		assert.Equal(t, 0, fakeClient.UpdateWorkflowCallCount())
+	})
+
+	t.Run("Rejects unknown strategies", func(t *testing.T) {
+		cmd, _ := newMatchTestCmd(t, "title")
+		_, err := workflows.MatchRemoteWorkflows(newMatchTestClient(), cmd, nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestDedupeWorkflows(t *testing.T) {
+	managed := &[]n8n.Tag{{Name: "n8n-cli"}}
+	remoteWorkflows := []n8n.Workflow{
+		{Id: stringPtr("1"), Name: "Orders", UpdatedAt: timePtr("2024-01-01T00:00:00Z")},
+		{Id: stringPtr("2"), Name: "Orders", UpdatedAt: timePtr("2024-03-01T00:00:00Z")},
+		{Id: stringPtr("3"), Name: "Invoices", Tags: managed},
+		{Id: stringPtr("4"), Name: "Invoices", Active: boolPtr(true)},
+		{Id: stringPtr("5"), Name: "Refunds"},
+	}
+
+	newDedupeTestCmd := func(t *testing.T, args ...string) (*cobra.Command, *bytes.Buffer) {
+		cmd := &cobra.Command{}
+		cmd.Flags().String("directory", "", "")
+		cmd.Flags().Bool("delete", false, "")
+		cmd.Flags().Bool("include-unmanaged", false, "")
+		cmd.Flags().Bool("yes", false, "")
+		cmd.Flags().Bool("dry-run", false, "")
+		require.NoError(t, cmd.ParseFlags(args))
+		outBuf := new(bytes.Buffer)
+		cmd.SetOut(outBuf)
+		cmd.SetIn(strings.NewReader(""))
+		return cmd, outBuf
+	}
+
+	t.Run("Lists duplicates without deleting them", func(t *testing.T) {
+		fakeClient := &clientfakes.FakeClientInterface{}
+		fakeClient.GetAllWorkflowsReturns(&n8n.WorkflowList{Data: &remoteWorkflows}, nil)
+
+		cmd, outBuf := newDedupeTestCmd(t)
+		require.NoError(t, workflows.DedupeWorkflowsWithClient(cmd, fakeClient))
+
+		output := outBuf.String()
+		assert.Contains(t, output, "Duplicate name 'Invoices': keeping ID 3 (managed by the CLI), duplicates: 4")
+		assert.Contains(t, output, "Duplicate name 'Orders': keeping ID 2 (most recently updated), duplicates: 1")
+		assert.Contains(t, output, "Found 2 duplicate workflow(s), remove them with --delete")
+		assert.NotContains(t, output, "Refunds")
+		assert.Equal(t, 0, fakeClient.DeleteWorkflowCallCount())
+	})
+
+	t.Run("Keeps the workflows in the directory and deletes their duplicates", func(t *testing.T) {
+		dir := t.TempDir()
+		writeDriftTestWorkflow(t, dir, "Orders.json", n8n.Workflow{Id: stringPtr("1"), Name: "Orders"})
+
+		fakeClient := &clientfakes.FakeClientInterface{}
+		fakeClient.GetAllWorkflowsReturns(&n8n.WorkflowList{Data: &remoteWorkflows}, nil)
+		fakeClient.GetWorkflowStub = func(id string) (*n8n.Workflow, error) {
+			return &n8n.Workflow{Id: &id, Name: "Duplicate"}, nil
+		}
+
+		cmd, outBuf := newDedupeTestCmd(t, "--directory", dir, "--delete", "--include-unmanaged", "--yes")
+		require.NoError(t, workflows.DedupeWorkflowsWithClient(cmd, fakeClient))
+
+		assert.Contains(t, outBuf.String(), "Duplicate name 'Orders': keeping ID 1 (in the local files), duplicates: 2")
+		require.Equal(t, 2, fakeClient.DeleteWorkflowCallCount())
+		assert.Equal(t, "4", fakeClient.DeleteWorkflowArgsForCall(0))
+		assert.Equal(t, "2", fakeClient.DeleteWorkflowArgsForCall(1))
+		assert.Contains(t, outBuf.String(), "Saved the deleted workflows to")
+	})
+
+	t.Run("Only deletes managed duplicates by default", func(t *testing.T) {
+		dir := t.TempDir()
+		writeDriftTestWorkflow(t, dir, "Orders.json", n8n.Workflow{Id: stringPtr("1"), Name: "Orders"})
+
+		fakeClient := &clientfakes.FakeClientInterface{}
+		fakeClient.GetAllWorkflowsReturns(&n8n.WorkflowList{Data: &[]n8n.Workflow{
+			{Id: stringPtr("1"), Name: "Orders"},
+			{Id: stringPtr("2"), Name: "Orders", Tags: managed},
+			{Id: stringPtr("6"), Name: "Orders"},
+		}}, nil)
+		fakeClient.GetWorkflowStub = func(id string) (*n8n.Workflow, error) {
+			return &n8n.Workflow{Id: &id, Name: "Orders"}, nil
+		}
+
+		cmd, outBuf := newDedupeTestCmd(t, "--directory", dir, "--delete", "--yes")
+		require.NoError(t, workflows.DedupeWorkflowsWithClient(cmd, fakeClient))
+
+		require.Equal(t, 1, fakeClient.DeleteWorkflowCallCount())
+		assert.Equal(t, "2", fakeClient.DeleteWorkflowArgsForCall(0))
+		assert.Contains(t, outBuf.String(), "Skipped 1 duplicate workflow(s) not managed by the CLI, use --include-unmanaged to delete them")
+	})
+
+	t.Run("Backs up deleted workflows in the current directory without a directory", func(t *testing.T) {
+		dir := t.TempDir()
+		t.Chdir(dir)
+
+		fakeClient := &clientfakes.FakeClientInterface{}
+		fakeClient.GetAllWorkflowsReturns(&n8n.WorkflowList{Data: &remoteWorkflows}, nil)
+		fakeClient.GetWorkflowStub = func(id string) (*n8n.Workflow, error) {
+			return &n8n.Workflow{Id: &id, Name: "Duplicate"}, nil
+		}
+
+		cmd, outBuf := newDedupeTestCmd(t, "--delete", "--include-unmanaged", "--yes")
+		require.NoError(t, workflows.DedupeWorkflowsWithClient(cmd, fakeClient))
+
+		require.Equal(t, 2, fakeClient.DeleteWorkflowCallCount())
+		backups, err := filepath.Glob(filepath.Join(dir, ".n8n", "backups", "*", "manifest.json"))
+		require.NoError(t, err)
+		require.Len(t, backups, 1)
+		backup, err := workflows.LoadBackup(filepath.Dir(backups[0]))
+		require.NoError(t, err)
+		assert.Equal(t, 2, backup.Len())
+		assert.Contains(t, outBuf.String(), "Saved the deleted workflows to .n8n/backups/")
+	})
+
+	t.Run("Doesn't back up on a dry run", func(t *testing.T) {
+		dir := t.TempDir()
+		t.Chdir(dir)
+
+		fakeClient := &clientfakes.FakeClientInterface{}
+		fakeClient.GetAllWorkflowsReturns(&n8n.WorkflowList{Data: &remoteWorkflows}, nil)
+
+		cmd, _ := newDedupeTestCmd(t, "--delete", "--include-unmanaged", "--dry-run")
+		require.NoError(t, workflows.DedupeWorkflowsWithClient(cmd, fakeClient))
+
+		assert.Equal(t, 0, fakeClient.DeleteWorkflowCallCount())
+		assert.NoDirExists(t, filepath.Join(dir, ".n8n"))
+	})
+
+	t.Run("Doesn't choose when several duplicates are in the directory", func(t *testing.T) {
+		groups := workflows.FindDuplicateWorkflows(remoteWorkflows, map[string]bool{"1": true, "2": true})
+
+		require.Len(t, groups, 2)
+		assert.Equal(t, "Orders", groups[1].Name)
+		assert.Nil(t, groups[1].Keep)
+		assert.Equal(t, "2 of them are in the local files", groups[1].Reason)
+	})
+}
@@ -0,0 +1,166 @@
+package unit
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/edenreich/n8n-cli/cmd/workflows"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/edenreich/n8n-cli/n8n/clientfakes"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPruneTestCmd(t *testing.T, input string, flags map[string]string) (*cobra.Command, *bytes.Buffer) {
+	cmd := &cobra.Command{}
+	cmd.Flags().Bool("dry-run", false, "")
+	cmd.Flags().StringSlice("prune-tag", nil, "")
+	cmd.Flags().String("prune-project", "", "")
+	cmd.Flags().String("prune-pattern", "", "")
+	cmd.Flags().Int("max-deletions", 0, "")
+	cmd.Flags().Bool("archive", false, "")
+	cmd.Flags().String("archive-tag", "archived", "")
+	cmd.Flags().Bool("yes", false, "")
+	cmd.Flags().Bool("include-unmanaged", false, "")
+
+	for name, value := range flags {
+		require.NoError(t, cmd.Flags().Set(name, value))
+	}
+
+	outBuf := new(bytes.Buffer)
+	cmd.SetOut(outBuf)
+	cmd.SetIn(strings.NewReader(input))
+	return cmd, outBuf
+}
+
+func pruneTestWorkflows() *n8n.WorkflowList {
+	projectID := "project-1"
+	managed := n8n.Tag{Id: stringPtr("tag-managed"), Name: "n8n-cli"}
+	return &n8n.WorkflowList{Data: &[]n8n.Workflow{
+		{Id: stringPtr("1"), Name: "Local Workflow", Tags: &[]n8n.Tag{managed}},
+		{Id: stringPtr("2"), Name: "[ci] Orders", Tags: &[]n8n.Tag{{Id: stringPtr("tag-ci"), Name: "ci"}, managed}, Active: boolPtr(true)},
+		{Id: stringPtr("3"), Name: "[ci] Invoices", Tags: &[]n8n.Tag{managed}, Shared: &[]n8n.SharedWorkflow{{ProjectId: &projectID}}},
+		{Id: stringPtr("4"), Name: "Hand Made", Tags: &[]n8n.Tag{managed}},
+		{Id: stringPtr("5"), Name: "Built In The UI"},
+	}}
+}
+
+func deletedIDs(fakeClient *clientfakes.FakeClientInterface) []string {
+	var ids []string
+	for i := 0; i < fakeClient.DeleteWorkflowCallCount(); i++ {
+		ids = append(ids, fakeClient.DeleteWorkflowArgsForCall(i))
+	}
+	return ids
+}
+
+func TestPruneWorkflows(t *testing.T) {
+	local := map[string]bool{"1": true}
+
+	t.Run("Asks for confirmation and deletes when confirmed", func(t *testing.T) {
+		fakeClient := &clientfakes.FakeClientInterface{}
+		fakeClient.GetWorkflowsReturns(pruneTestWorkflows(), nil)
+
+		cmd, outBuf := newPruneTestCmd(t, "y\n", nil)
+
+		require.NoError(t, workflows.PruneWorkflows(fakeClient, cmd, local))
+		assert.Contains(t, outBuf.String(), "The following 3 workflow(s) will be deleted:")
+		assert.Contains(t, outBuf.String(), "  - Hand Made (ID: 4)")
+		assert.Equal(t, []string{"2", "3", "4"}, deletedIDs(fakeClient))
+	})
+
+	t.Run("Deletes nothing when not confirmed", func(t *testing.T) {
+		fakeClient := &clientfakes.FakeClientInterface{}
+		fakeClient.GetWorkflowsReturns(pruneTestWorkflows(), nil)
+
+		cmd, outBuf := newPruneTestCmd(t, "\n", nil)
+
+		require.NoError(t, workflows.PruneWorkflows(fakeClient, cmd, local))
+		assert.Contains(t, outBuf.String(), "Prune cancelled")
+		assert.Equal(t, 0, fakeClient.DeleteWorkflowCallCount())
+	})
+
+	t.Run("Scopes by tag, project and name pattern", func(t *testing.T) {
+		tests := []struct {
+			flags    map[string]string
+			expected []string
+		}{
+			{map[string]string{"prune-tag": "ci"}, []string{"2"}},
+			{map[string]string{"prune-project": "project-1"}, []string{"3"}},
+			{map[string]string{"prune-pattern": "\\[ci\\] *"}, []string{"2", "3"}},
+		}
+
+		for _, tt := range tests {
+			fakeClient := &clientfakes.FakeClientInterface{}
+			fakeClient.GetWorkflowsReturns(pruneTestWorkflows(), nil)
+
+			tt.flags["yes"] = "true"
+			cmd, _ := newPruneTestCmd(t, "", tt.flags)
+
+			require.NoError(t, workflows.PruneWorkflows(fakeClient, cmd, local))
+			assert.Equal(t, tt.expected, deletedIDs(fakeClient), "flags: %v", tt.flags)
+		}
+	})
+
+	t.Run("Skips protected workflows", func(t *testing.T) {
+		viper.Set("prune.protected", []string{"4", "*Orders"})
+		t.Cleanup(func() { viper.Set("prune.protected", nil) })
+
+		fakeClient := &clientfakes.FakeClientInterface{}
+		fakeClient.GetWorkflowsReturns(pruneTestWorkflows(), nil)
+
+		cmd, outBuf := newPruneTestCmd(t, "", map[string]string{"yes": "true"})
+
+		require.NoError(t, workflows.PruneWorkflows(fakeClient, cmd, local))
+		assert.Equal(t, []string{"3"}, deletedIDs(fakeClient))
+		assert.Contains(t, outBuf.String(), "Skipping protected workflow 'Hand Made' (ID: 4)")
+	})
+
+	t.Run("Refuses to delete more than --max-deletions", func(t *testing.T) {
+		fakeClient := &clientfakes.FakeClientInterface{}
+		fakeClient.GetWorkflowsReturns(pruneTestWorkflows(), nil)
+
+		cmd, _ := newPruneTestCmd(t, "", map[string]string{"yes": "true", "max-deletions": "2"})
+
+		err := workflows.PruneWorkflows(fakeClient, cmd, local)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "refusing to prune 3 workflows")
+		assert.Equal(t, 0, fakeClient.DeleteWorkflowCallCount())
+	})
+
+	t.Run("Archives instead of deleting", func(t *testing.T) {
+		fakeClient := &clientfakes.FakeClientInterface{}
+		fakeClient.GetWorkflowsReturns(pruneTestWorkflows(), nil)
+		fakeClient.GetTagsReturns(&n8n.TagList{Data: &[]n8n.Tag{}}, nil)
+		fakeClient.CreateTagReturns(&n8n.Tag{Id: stringPtr("tag-archived"), Name: "archived"}, nil)
+
+		cmd, outBuf := newPruneTestCmd(t, "", map[string]string{"yes": "true", "archive": "true", "prune-tag": "ci"})
+
+		require.NoError(t, workflows.PruneWorkflows(fakeClient, cmd, local))
+		assert.Equal(t, 0, fakeClient.DeleteWorkflowCallCount())
+		assert.Equal(t, "2", fakeClient.DeactivateWorkflowArgsForCall(0))
+
+		id, tagIDs := fakeClient.UpdateWorkflowTagsArgsForCall(0)
+		assert.Equal(t, "2", id)
+		require.Len(t, tagIDs, 3, "Existing tags should be kept")
+		assert.Equal(t, "tag-archived", tagIDs[0].Id)
+		assert.Equal(t, "tag-ci", tagIDs[1].Id)
+		assert.Equal(t, "tag-managed", tagIDs[2].Id)
+		assert.Contains(t, outBuf.String(), "Archived workflow '[ci] Orders' (ID: 2)")
+	})
+
+	t.Run("Leaves unmanaged workflows alone unless included", func(t *testing.T) {
+		fakeClient := &clientfakes.FakeClientInterface{}
+		fakeClient.GetWorkflowsReturns(pruneTestWorkflows(), nil)
+
+		cmd, _ := newPruneTestCmd(t, "", map[string]string{"yes": "true", "prune-pattern": "Built*"})
+		require.NoError(t, workflows.PruneWorkflows(fakeClient, cmd, local))
+		assert.Equal(t, 0, fakeClient.DeleteWorkflowCallCount())
+
+		cmd, _ = newPruneTestCmd(t, "", map[string]string{"yes": "true", "prune-pattern": "Built*", "include-unmanaged": "true"})
+		require.NoError(t, workflows.PruneWorkflows(fakeClient, cmd, local))
+		assert.Equal(t, []string{"5"}, deletedIDs(fakeClient))
+	})
+}
@@ -441,6 +441,7 @@ active: true
 			cmd.Flags().StringP("output", "o", "json", "Output format")
 			cmd.Flags().Bool("no-truncate", false, "Include all fields in output")
 			cmd.Flags().Bool("all", false, "Refresh all workflows")
+			cmd.Flags().Bool("include-unmanaged", false, "Include unmanaged workflows")
 			cmd.SetOut(outBuf)
 			cmd.SetErr(errBuf)
 
@@ -448,6 +449,11 @@ active: true
 				t.Fatal(err)
 			}
 
+			// The mocked workflows are not tagged as managed, these cases cover the written files
+			if err := cmd.Flags().Set("include-unmanaged", "true"); err != nil {
+				t.Fatal(err)
+			}
+
 			for i := 0; i < len(tc.args); i++ {
 				if tc.args[i] == "--output" || tc.args[i] == "-o" {
 					if i+1 < len(tc.args) {
@@ -525,3 +531,37 @@ active: true
 		})
 	}
 }
+
+func TestRefreshWorkflows_SkipsUnmanagedWorkflows(t *testing.T) {
+	dir := t.TempDir()
+
+	existing := n8n.Workflow{Id: stringPtr("local"), Name: "Local Workflow"}
+	content, err := n8n.NewWorkflowEncoder(true).EncodeToJSON(existing)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Local_Workflow.json"), content, 0644))
+
+	fakeClient := &clientfakes.FakeClientInterface{}
+	fakeClient.GetWorkflowsReturns(&n8n.WorkflowList{Data: &[]n8n.Workflow{
+		{Id: stringPtr("local"), Name: "Local Workflow", Active: boolPtr(true)},
+		{Id: stringPtr("managed"), Name: "Managed Workflow", Tags: &[]n8n.Tag{{Id: stringPtr("tag-1"), Name: "n8n-cli"}}},
+		{Id: stringPtr("ui"), Name: "Built In The UI"},
+	}}, nil)
+
+	outBuf := new(bytes.Buffer)
+	cmd := &cobra.Command{}
+	cmd.Flags().Bool("include-unmanaged", false, "Include unmanaged workflows")
+	cmd.SetOut(outBuf)
+
+	err = workflows.RefreshWorkflowsWithClient(cmd, fakeClient, dir, false, false, "json", true, true)
+	require.NoError(t, err)
+
+	assert.FileExists(t, filepath.Join(dir, "Local_Workflow.json"), "Workflows with a local file should be refreshed")
+	assert.FileExists(t, filepath.Join(dir, "Managed_Workflow.json"), "Managed workflows should be refreshed")
+	assert.NoFileExists(t, filepath.Join(dir, "Built_In_The_UI.json"), "Unmanaged workflows should be skipped")
+	assert.Contains(t, outBuf.String(), "Skipped 1 workflow(s) not managed by the CLI")
+
+	require.NoError(t, cmd.Flags().Set("include-unmanaged", "true"))
+	err = workflows.RefreshWorkflowsWithClient(cmd, fakeClient, dir, false, false, "json", true, true)
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(dir, "Built_In_The_UI.json"))
+}
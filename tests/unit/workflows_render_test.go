@@ -0,0 +1,127 @@
+package unit
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/edenreich/n8n-cli/cmd/workflows"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/edenreich/n8n-cli/n8n/clientfakes"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRenderTestCmd(t *testing.T, overlay string, substitute bool) (*cobra.Command, *bytes.Buffer) {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("overlay", "", "Overlay")
+	cmd.Flags().Bool("substitute", false, "Substitute")
+	cmd.Flags().StringP("output", "o", "", "Output format")
+
+	require.NoError(t, cmd.Flags().Set("overlay", overlay))
+	if substitute {
+		require.NoError(t, cmd.Flags().Set("substitute", "true"))
+	}
+
+	outBuf := new(bytes.Buffer)
+	cmd.SetOut(outBuf)
+	return cmd, outBuf
+}
+
+func writeRenderFixtures(t *testing.T) string {
+	dir := t.TempDir()
+
+	base := `---
+name: Contact Form
+nodes:
+  - name: Email
+    parameters:
+      toEmail: ${RENDER_TEST_EMAIL}
+      subject: New contact request
+connections: {}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Contact_Form.yaml"), []byte(base), 0644))
+
+	overlayDir := filepath.Join(dir, "overlays", "production")
+	require.NoError(t, os.MkdirAll(overlayDir, 0755))
+
+	overlay := `---
+nodes:
+  - name: Email
+    parameters:
+      subject: "[PROD] New contact request"
+`
+	require.NoError(t, os.WriteFile(filepath.Join(overlayDir, "Contact_Form.yaml"), []byte(overlay), 0644))
+
+	return dir
+}
+
+func TestRenderCommand(t *testing.T) {
+	dir := writeRenderFixtures(t)
+	filePath := filepath.Join(dir, "Contact_Form.yaml")
+
+	t.Setenv("RENDER_TEST_EMAIL", "ops@example.com")
+
+	t.Run("Applies overlay and substitution", func(t *testing.T) {
+		cmd, outBuf := newRenderTestCmd(t, "production", true)
+
+		err := workflows.RenderCmd.RunE(cmd, []string{filePath})
+		require.NoError(t, err)
+
+		assert.Contains(t, outBuf.String(), "toEmail: ops@example.com")
+		assert.Contains(t, outBuf.String(), "[PROD] New contact request")
+	})
+
+	t.Run("Keeps placeholders without substitution", func(t *testing.T) {
+		cmd, outBuf := newRenderTestCmd(t, "", false)
+
+		err := workflows.RenderCmd.RunE(cmd, []string{filePath})
+		require.NoError(t, err)
+
+		assert.Contains(t, outBuf.String(), "${RENDER_TEST_EMAIL}")
+		assert.NotContains(t, outBuf.String(), "[PROD]")
+	})
+
+	t.Run("Fails on unknown overlay", func(t *testing.T) {
+		cmd, _ := newRenderTestCmd(t, "staging", false)
+
+		err := workflows.RenderCmd.RunE(cmd, []string{filePath})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "overlay 'staging' not found")
+	})
+
+	t.Run("Fails on unresolved variables", func(t *testing.T) {
+		t.Setenv("RENDER_TEST_EMAIL", "")
+		require.NoError(t, os.Unsetenv("RENDER_TEST_EMAIL"))
+
+		cmd, _ := newRenderTestCmd(t, "", true)
+
+		err := workflows.RenderCmd.RunE(cmd, []string{filePath})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unresolved variables: RENDER_TEST_EMAIL")
+	})
+}
+
+func TestProcessWorkflowFile_AppliesOverlay(t *testing.T) {
+	dir := writeRenderFixtures(t)
+	filePath := filepath.Join(dir, "Contact_Form.yaml")
+
+	t.Setenv("RENDER_TEST_EMAIL", "ops@example.com")
+
+	fakeClient := &clientfakes.FakeClientInterface{}
+	newID := "new-id"
+	fakeClient.CreateWorkflowReturns(&n8n.Workflow{Id: &newID, Name: "Contact Form"}, nil)
+
+	cmd, _ := newRenderTestCmd(t, "production", true)
+
+	_, err := workflows.ProcessWorkflowFile(fakeClient, cmd, filePath, false, false)
+	require.NoError(t, err)
+
+	require.Equal(t, 1, fakeClient.CreateWorkflowCallCount())
+	uploaded := fakeClient.CreateWorkflowArgsForCall(0)
+	parameters := *uploaded.Nodes[0].Parameters
+	assert.Equal(t, "ops@example.com", parameters["toEmail"])
+	assert.Equal(t, "[PROD] New contact request", parameters["subject"])
+}
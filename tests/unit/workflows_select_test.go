@@ -0,0 +1,163 @@
+package unit
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/edenreich/n8n-cli/cmd/workflows"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSelectTestCmd(t *testing.T, args ...string) *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().StringSlice("name", nil, "")
+	cmd.Flags().StringSlice("tag", nil, "")
+	cmd.Flags().StringSlice("id", nil, "")
+	cmd.Flags().String("changed-since", "", "")
+	require.NoError(t, cmd.ParseFlags(args))
+	cmd.SetOut(new(bytes.Buffer))
+	return cmd
+}
+
+func TestWorkflowSelector(t *testing.T) {
+	dir := t.TempDir()
+
+	orders := n8n.Workflow{Id: stringPtr("1"), Name: "Orders Sync", Tags: &[]n8n.Tag{{Name: "billing"}}}
+	invoices := n8n.Workflow{Id: stringPtr("2"), Name: "Invoices", Tags: &[]n8n.Tag{{Name: "billing"}}}
+	writeDriftTestWorkflow(t, dir, "Orders.json", orders)
+	writeDriftTestWorkflow(t, dir, "Invoices.json", invoices)
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{"Selects every workflow without criteria", nil, []string{"Orders Sync", "Invoices"}},
+		{"Selects by name pattern", []string{"--name", "Orders*"}, []string{"Orders Sync"}},
+		{"Selects by tag", []string{"--tag", "billing"}, []string{"Orders Sync", "Invoices"}},
+		{"Selects by ID", []string{"--id", "2"}, []string{"Invoices"}},
+		{"Selects by file relative to the directory", []string{"Invoices.json"}, []string{"Invoices"}},
+		{"Requires every criterion to match", []string{"--tag", "billing", "--id", "1,3"}, []string{"Orders Sync"}},
+		{"Selects nothing when no workflow matches", []string{"--name", "Orders", "--tag", "billing"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector, err := workflows.NewWorkflowSelector(newSelectTestCmd(t, tt.args...), dir)
+			require.NoError(t, err)
+
+			var selected []string
+			for _, local := range selector.SelectWorkflows([]workflows.LocalWorkflow{
+				{FilePath: filepath.Join(dir, "Orders.json"), Workflow: orders},
+				{FilePath: filepath.Join(dir, "Invoices.json"), Workflow: invoices},
+			}) {
+				selected = append(selected, local.Workflow.Name)
+			}
+			assert.Equal(t, tt.expected, selected)
+		})
+	}
+
+	t.Run("Fails for files that don't exist", func(t *testing.T) {
+		_, err := workflows.NewWorkflowSelector(newSelectTestCmd(t, "Missing.json"), dir)
+		assert.Error(t, err)
+	})
+
+	t.Run("Fails for invalid name patterns", func(t *testing.T) {
+		_, err := workflows.NewWorkflowSelector(newSelectTestCmd(t, "--name", "[orders"), dir)
+		assert.Error(t, err)
+	})
+}
+
+func TestWorkflowSelector_ChangedSince(t *testing.T) {
+	repo := t.TempDir()
+	dir := filepath.Join(repo, "workflows")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+
+	git := func(args ...string) {
+		command := exec.Command("git", append([]string{"-C", repo, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		output, err := command.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+
+	writeDriftTestWorkflow(t, dir, "Orders.json", n8n.Workflow{Id: stringPtr("1"), Name: "Orders"})
+	writeDriftTestWorkflow(t, dir, "Invoices.json", n8n.Workflow{Id: stringPtr("2"), Name: "Invoices"})
+	writeDriftTestWorkflow(t, dir, "Refunds.json", n8n.Workflow{Id: stringPtr("3"), Name: "Refunds"})
+	writeDriftTestWorkflow(t, dir, "Shipping.json", n8n.Workflow{Id: stringPtr("4"), Name: "Shipping"})
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "Shipping"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Shipping", "Format.js"), []byte("return items;\n"), 0644))
+	writeDriftTestWorkflow(t, dir, "Payments.json", n8n.Workflow{Id: stringPtr("5"), Name: "Payments"})
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "overlays", "production"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "overlays", "production", "Payments.yaml"), []byte("active: false\n"), 0644))
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-q", "-m", "Add workflows")
+
+	writeDriftTestWorkflow(t, dir, "Orders.json", n8n.Workflow{Id: stringPtr("1"), Name: "Orders", Active: boolPtr(true)})
+	writeDriftTestWorkflow(t, dir, "New.json", n8n.Workflow{Name: "New"})
+	git("add", ".")
+	writeDriftTestWorkflow(t, dir, "Untracked.json", n8n.Workflow{Name: "Untracked"})
+	require.NoError(t, os.WriteFile(filepath.Join(repo, ".gitignore"), []byte("workflows/Ignored.json\n"), 0644))
+	writeDriftTestWorkflow(t, dir, "Ignored.json", n8n.Workflow{Name: "Ignored"})
+	require.NoError(t, os.Remove(filepath.Join(dir, "Refunds.json")))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Shipping", "Format.js"), []byte("return [];\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "overlays", "production", "Payments.yaml"), []byte("active: true\n"), 0644))
+
+	selector, err := workflows.NewWorkflowSelector(newSelectTestCmd(t, "--changed-since", "HEAD"), dir)
+	require.NoError(t, err)
+
+	assert.True(t, selector.MatchesLocation("1", filepath.Join(dir, "Orders.json")))
+	assert.True(t, selector.MatchesLocation("", filepath.Join(dir, "New.json")))
+	assert.True(t, selector.MatchesLocation("", filepath.Join(dir, "Untracked.json")), "Untracked files should be selected")
+	assert.False(t, selector.MatchesLocation("", filepath.Join(dir, "Ignored.json")), "Ignored files should not be selected")
+	assert.True(t, selector.MatchesLocation("4", filepath.Join(dir, "Shipping.json")), "Changed code files should select their workflow file")
+	assert.True(t, selector.MatchesLocation("5", filepath.Join(dir, "Payments.json")), "Changed overlay files should select the workflow they patch")
+	assert.False(t, selector.MatchesLocation("2", filepath.Join(dir, "Invoices.json")))
+	assert.Len(t, selector.Files, 5, "Deleted files should not be selected")
+
+	_, err = workflows.NewWorkflowSelector(newSelectTestCmd(t, "--changed-since", "does-not-exist"), dir)
+	assert.Error(t, err)
+}
+
+func TestSyncWorkflows_Selection(t *testing.T) {
+	dir := t.TempDir()
+	writeDriftTestWorkflow(t, dir, "Orders.json", n8n.Workflow{Id: stringPtr("1"), Name: "Orders"})
+	writeDriftTestWorkflow(t, dir, "Invoices.json", n8n.Workflow{Id: stringPtr("2"), Name: "Invoices"})
+
+	fakeClient := newWatchTestClient()
+	fakeClient.GetWorkflowsReturns(&n8n.WorkflowList{Data: &[]n8n.Workflow{
+		{Id: stringPtr("1"), Name: "Orders", Tags: &[]n8n.Tag{{Name: "n8n-cli"}}},
+		{Id: stringPtr("2"), Name: "Invoices", Tags: &[]n8n.Tag{{Name: "n8n-cli"}}},
+	}}, nil)
+
+	cmd := newSelectTestCmd(t, "--id", "1")
+	outBuf := new(bytes.Buffer)
+	cmd.SetOut(outBuf)
+
+	results, err := workflows.SyncWorkflowsWithClient(cmd, fakeClient, dir, false, true)
+	require.NoError(t, err)
+
+	require.Len(t, results, 1)
+	assert.Equal(t, "1", results[0].WorkflowID)
+	assert.Contains(t, outBuf.String(), "Selected 1 of 2 workflow file(s)")
+	assert.Equal(t, 0, fakeClient.DeleteWorkflowCallCount(), "Workflows in the directory that weren't selected should not be pruned")
+}
+
+func TestRefreshWorkflows_Selection(t *testing.T) {
+	dir := t.TempDir()
+	writeDriftTestWorkflow(t, dir, "Orders.json", n8n.Workflow{Id: stringPtr("1"), Name: "Orders"})
+	writeDriftTestWorkflow(t, dir, "Invoices.json", n8n.Workflow{Id: stringPtr("2"), Name: "Invoices"})
+
+	fakeClient := newWatchTestClient()
+
+	cmd := newSelectTestCmd(t, "Invoices.json")
+	require.NoError(t, workflows.RefreshWorkflowsWithClient(cmd, fakeClient, dir, false, false, "", true, false))
+
+	require.Equal(t, 1, fakeClient.GetWorkflowCallCount(), "Only the selected workflow should be fetched")
+	assert.Equal(t, "2", fakeClient.GetWorkflowArgsForCall(0))
+}
@@ -0,0 +1,254 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edenreich/n8n-cli/cmd/workflows"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/edenreich/n8n-cli/n8n/clientfakes"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is a bytes.Buffer that can be written by the watcher while the test reads it
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func newWatchTestClient() *clientfakes.FakeClientInterface {
+	fakeClient := &clientfakes.FakeClientInterface{}
+	fakeClient.GetWorkflowStub = func(id string) (*n8n.Workflow, error) {
+		return &n8n.Workflow{Id: &id, Name: "Remote", Nodes: []n8n.Node{}, Connections: map[string]interface{}{}}, nil
+	}
+	fakeClient.CreateWorkflowStub = func(workflow *n8n.Workflow) (*n8n.Workflow, error) {
+		created := *workflow
+		created.Id = stringPtr("created-" + workflow.Name)
+		return &created, nil
+	}
+	fakeClient.UpdateWorkflowStub = func(id string, workflow *n8n.Workflow) (*n8n.Workflow, error) {
+		updated := *workflow
+		return &updated, nil
+	}
+	return fakeClient
+}
+
+func TestSyncWorkflowFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "Valid.yaml")
+	invalid := filepath.Join(dir, "Invalid.json")
+	require.NoError(t, os.WriteFile(valid, []byte("name: Valid\nnodes: []\nconnections: {}\n"), 0644))
+	require.NoError(t, os.WriteFile(invalid, []byte(`{"name": "Invalid",`), 0644))
+
+	fakeClient := newWatchTestClient()
+
+	cmd := &cobra.Command{}
+	cmd.Flags().Bool("refresh", true, "")
+	outBuf := new(bytes.Buffer)
+	cmd.SetOut(outBuf)
+
+	failed := workflows.SyncWorkflowFiles(cmd, fakeClient, dir, []string{valid, invalid}, false)
+	assert.Equal(t, 1, failed)
+	assert.Contains(t, outBuf.String(), "Error in Invalid.json")
+	assert.Equal(t, 1, fakeClient.CreateWorkflowCallCount(), "Errors in one file should not stop the others")
+
+	content, err := os.ReadFile(valid)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "id: created-Valid", "The ID of the created workflow should be written back")
+}
+
+func TestWatchWorkflows(t *testing.T) {
+	dir := t.TempDir()
+
+	existing := filepath.Join(dir, "Existing.json")
+	require.NoError(t, os.WriteFile(existing, []byte(`{"id": "1", "name": "Existing", "nodes": [], "connections": {}}`), 0644))
+	untouched := filepath.Join(dir, "Untouched.json")
+	require.NoError(t, os.WriteFile(untouched, []byte(`{"id": "2", "name": "Untouched", "nodes": [], "connections": {}}`), 0644))
+
+	fakeClient := newWatchTestClient()
+
+	cmd := &cobra.Command{}
+	cmd.Flags().Duration("debounce", 100*time.Millisecond, "")
+	outBuf := &syncBuffer{}
+	cmd.SetOut(outBuf)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- workflows.WatchWorkflows(ctx, cmd, fakeClient, dir, false)
+	}()
+
+	require.Eventually(t, func() bool {
+		return bytes.Contains([]byte(outBuf.String()), []byte("Watching"))
+	}, 2*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, os.WriteFile(existing, []byte(`{"id": "1", "name": "Existing", "nodes": [], "connections": {"changed": {}}}`), 0644))
+	require.NoError(t, os.WriteFile(existing, []byte(`{"id": "1", "name": "Existing", "nodes": [], "connections": {"changed": {"main": []}}}`), 0644))
+	require.NoError(t, os.WriteFile(untouched, []byte(`{"id": "2", "name": "Untouched", "nodes": [], "connections": {}}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Broken.yaml"), []byte("name: [unclosed"), 0644))
+
+	require.Eventually(t, func() bool {
+		return bytes.Contains([]byte(outBuf.String()), []byte("Error in Broken.yaml"))
+	}, 2*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+
+	assert.Equal(t, 1, fakeClient.UpdateWorkflowCallCount(), "Changes should be debounced and unchanged files skipped")
+	id, _ := fakeClient.UpdateWorkflowArgsForCall(0)
+	assert.Equal(t, "1", id)
+}
+
+func TestWatchWorkflows_NewWorkflowDirectory(t *testing.T) {
+	dir := t.TempDir()
+
+	// The instance returns the last uploaded version, so the second change isn't reported as a conflict
+	var mu sync.Mutex
+	var uploaded *n8n.Workflow
+	fakeClient := newWatchTestClient()
+	fakeClient.GetWorkflowStub = func(id string) (*n8n.Workflow, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if uploaded != nil {
+			return uploaded, nil
+		}
+		return &n8n.Workflow{Id: &id, Name: "Remote", Nodes: []n8n.Node{}, Connections: map[string]interface{}{}}, nil
+	}
+	fakeClient.UpdateWorkflowStub = func(id string, workflow *n8n.Workflow) (*n8n.Workflow, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		updated := *workflow
+		uploaded = &updated
+		return &updated, nil
+	}
+
+	cmd := &cobra.Command{}
+	cmd.Flags().Duration("debounce", 100*time.Millisecond, "")
+	outBuf := &syncBuffer{}
+	cmd.SetOut(outBuf)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- workflows.WatchWorkflows(ctx, cmd, fakeClient, dir, false)
+	}()
+
+	require.Eventually(t, func() bool {
+		return strings.Contains(outBuf.String(), "Watching")
+	}, 2*time.Second, 10*time.Millisecond)
+
+	workflowDir := filepath.Join(dir, "Orders")
+	nodeFile := filepath.Join(workflowDir, n8n.DirectoryNodesDir, "Webhook.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(nodeFile), 0755))
+	require.NoError(t, os.WriteFile(nodeFile, []byte("name: Webhook\ntype: n8n-nodes-base.webhook\nparameters:\n  path: orders\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(workflowDir, n8n.DirectoryWorkflowFile), []byte("id: \"1\"\nname: Orders\nnodes:\n  - $file: nodes/Webhook.yaml\nconnections: {}\n"), 0644))
+
+	require.Eventually(t, func() bool {
+		return fakeClient.UpdateWorkflowCallCount() == 1
+	}, 2*time.Second, 10*time.Millisecond, "A workflow directory created while watching should be synced")
+
+	require.NoError(t, os.WriteFile(nodeFile, []byte("name: Webhook\ntype: n8n-nodes-base.webhook\nparameters:\n  path: orders-v2\n"), 0644))
+
+	require.Eventually(t, func() bool {
+		return fakeClient.UpdateWorkflowCallCount() == 2
+	}, 2*time.Second, 10*time.Millisecond, "Changes to the nodes of the new workflow directory should be synced")
+
+	cancel()
+	require.NoError(t, <-done)
+
+	_, updated := fakeClient.UpdateWorkflowArgsForCall(1)
+	assert.Equal(t, "orders-v2", (*updated.Nodes[0].Parameters)["path"])
+}
+
+func TestWatchRemoteWorkflows(t *testing.T) {
+	dir := t.TempDir()
+
+	refreshed := mergeTestWorkflow(mergeTestNode("Webhook", map[string]interface{}{"path": "orders"}))
+	refreshed.Id = stringPtr("1")
+	refreshed.Name = "Orders"
+	refreshed.UpdatedAt = timePtr("2024-01-01T00:00:00Z")
+
+	editing := mergeTestWorkflow(mergeTestNode("Webhook", map[string]interface{}{"path": "invoices"}))
+	editing.Id = stringPtr("2")
+	editing.Name = "Invoices"
+	editing.UpdatedAt = refreshed.UpdatedAt
+
+	writeDriftTestWorkflow(t, dir, "Orders.json", refreshed)
+	locallyEdited := editing
+	locallyEdited.Nodes = []n8n.Node{mergeTestNode("Webhook", map[string]interface{}{"path": "invoices-local"})}
+	writeDriftTestWorkflow(t, dir, "Invoices.json", locallyEdited)
+
+	state, err := workflows.LoadWorkflowState(dir)
+	require.NoError(t, err)
+	require.NoError(t, state.Record(refreshed))
+	require.NoError(t, state.Record(editing))
+	require.NoError(t, state.Save())
+
+	editedInUI := func(workflow n8n.Workflow, path string) n8n.Workflow {
+		workflow.Nodes = []n8n.Node{mergeTestNode("Webhook", map[string]interface{}{"path": path})}
+		workflow.UpdatedAt = timePtr("2024-01-02T00:00:00Z")
+		return workflow
+	}
+
+	fakeClient := &clientfakes.FakeClientInterface{}
+	fakeClient.GetAllWorkflowsReturns(&n8n.WorkflowList{Data: &[]n8n.Workflow{
+		editedInUI(refreshed, "orders-ui"),
+		editedInUI(editing, "invoices-ui"),
+	}}, nil)
+
+	cmd := &cobra.Command{}
+	cmd.Flags().Duration("poll-interval", 20*time.Millisecond, "")
+	outBuf := &syncBuffer{}
+	cmd.SetOut(outBuf)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+	go func() {
+		done <- workflows.WatchRemoteWorkflows(ctx, cmd, fakeClient, dir, false, "json", true, false)
+	}()
+
+	assert.Eventually(t, func() bool {
+		content, err := os.ReadFile(filepath.Join(dir, "Orders.json"))
+		return err == nil && bytes.Contains(content, []byte("orders-ui"))
+	}, 2*time.Second, 10*time.Millisecond, "The workflow edited in n8n should be written to its file")
+
+	assert.Eventually(t, func() bool {
+		return fakeClient.GetAllWorkflowsCallCount() >= 3
+	}, 2*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+
+	content, err := os.ReadFile(filepath.Join(dir, "Invoices.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "invoices-local", "Files with local changes should not be overwritten")
+
+	output := outBuf.String()
+	assert.Equal(t, 1, strings.Count(output, "Paused refreshing workflow 'Invoices' (ID: 2)"), "The pause should be reported once")
+	assert.Equal(t, 1, strings.Count(output, "Updating workflow 'Orders'"), "Unchanged workflows should not be refreshed again")
+
+	state, err = workflows.LoadWorkflowState(dir)
+	require.NoError(t, err)
+	assert.True(t, state.Workflows["1"].UpdatedAt.Equal(*timePtr("2024-01-02T00:00:00Z")))
+}
@@ -0,0 +1,140 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/edenreich/n8n-cli/cmd/workflows"
+	"github.com/edenreich/n8n-cli/n8n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatchYAML(t *testing.T) {
+	existing := `# Owned by the growth team
+---
+# Contact form workflow
+name: Contact Form
+active: false # enable after review
+nodes:
+  # Entry point
+  - name: Webhook
+    type: n8n-nodes-base.webhook
+    parameters:
+      path: contact
+  - name: Format Reply
+    type: n8n-nodes-base.code
+    parameters:
+      jsCode: |
+        // greet the sender
+        return items;
+  - name: Removed
+    type: n8n-nodes-base.noOp
+settings: {}
+connections: {}
+`
+	updated := `---
+active: true
+connections:
+  Webhook:
+    main:
+      - - index: 0
+          node: Format Reply
+          type: main
+name: Contact Form
+nodes:
+  - name: Format Reply
+    parameters:
+      jsCode: "// greet the sender\nreturn [];\n"
+    type: n8n-nodes-base.code
+  - name: Set Fields
+    type: n8n-nodes-base.set
+  - name: Webhook
+    parameters:
+      path: "123"
+    type: n8n-nodes-base.webhook
+settings:
+  executionOrder: v1
+`
+
+	expected := `# Owned by the growth team
+---
+# Contact form workflow
+name: Contact Form
+active: true # enable after review
+nodes:
+  # Entry point
+  - name: Webhook
+    type: n8n-nodes-base.webhook
+    parameters:
+      path: "123"
+  - name: Format Reply
+    type: n8n-nodes-base.code
+    parameters:
+      jsCode: |
+        // greet the sender
+        return [];
+  - name: Set Fields
+    type: n8n-nodes-base.set
+settings:
+  executionOrder: v1
+connections:
+  Webhook:
+    main:
+      - - index: 0
+          node: Format Reply
+          type: main
+`
+
+	patched, err := n8n.PatchYAML([]byte(existing), []byte(updated))
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(patched))
+
+	t.Run("Keeps the document unchanged when nothing changed", func(t *testing.T) {
+		again, err := n8n.PatchYAML(patched, []byte(updated))
+		require.NoError(t, err)
+		assert.Equal(t, string(patched), string(again))
+	})
+
+	t.Run("Replaces documents in flow style", func(t *testing.T) {
+		replaced, err := n8n.PatchYAML([]byte(`{"name": "Contact Form"}`), []byte(updated))
+		require.NoError(t, err)
+		assert.Equal(t, updated, string(replaced))
+	})
+}
+
+func TestRefreshWorkflows_PreservesYAMLComments(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "Contact_Form.yaml")
+	require.NoError(t, os.WriteFile(filePath, []byte(`---
+# Reviewed by the growth team
+id: "1"
+name: Contact Form
+nodes:
+  - name: Webhook # public entry point
+    type: n8n-nodes-base.webhook
+    parameters:
+      path: contact
+connections: {}
+settings: {}
+`), 0644))
+
+	remote := n8n.Workflow{
+		Id:   stringPtr("1"),
+		Name: "Contact Form",
+		Nodes: []n8n.Node{{Name: stringPtr("Webhook"), Type: stringPtr("n8n-nodes-base.webhook"), Parameters: &map[string]interface{}{
+			"path": "contact-form",
+		}}},
+		Connections: map[string]interface{}{},
+	}
+	fakeClient := newWatchTestClient()
+	fakeClient.GetWorkflowReturns(&remote, nil)
+
+	require.NoError(t, workflows.RefreshWorkflowsWithClient(newSelectTestCmd(t), fakeClient, dir, false, false, "", true, false))
+
+	content := string(mustReadFile(t, filePath))
+	assert.Contains(t, content, "# Reviewed by the growth team\nid: \"1\"")
+	assert.Contains(t, content, "- name: Webhook # public entry point")
+	assert.Contains(t, content, "path: contact-form")
+}